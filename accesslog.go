@@ -0,0 +1,96 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AccessLogEntry is a request logged by AccessLog, with the sensitive values
+// of its query and JSON bodies masked.
+type AccessLogEntry struct {
+	Sample
+
+	// OperationID and Route are the ID and path template of the operation the
+	// request matched, or empty if it matched none.
+	OperationID string
+	Route       string
+
+	// Duration is the time next took to handle the request.
+	Duration time.Duration
+}
+
+// AccessLog calls log for every request once next has handled it, so the spec
+// doubles as the source of truth for log scrubbing rules. Values are masked
+// by the redactor, which defaults to DefaultRedactor if nil, i.e. properties
+// with the `x-sensitive` extension and names matching its patterns, and so
+// are the `writeOnly` properties of the matched operation's schemas, such as
+// passwords. JSON bodies larger than 1 MiB are not logged.
+//
+//	openapi.AccessLog(openAPI, nil, func(e *openapi.AccessLogEntry) {
+//		slog.Info("request", "operation", e.OperationID, "status", e.Status, "body", e.RequestBody)
+//	})
+func AccessLog(o *OpenAPI, r *Redactor, log func(e *AccessLogEntry)) func(next http.Handler) http.Handler {
+	if log == nil {
+		panic("log must be specified")
+	}
+	if r == nil {
+		r = DefaultRedactor
+	}
+
+	matcher := NewMatcher(o)
+	routes := map[*Operation]string{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		routes[op] = path
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			op, params := matcher.Match(req.Method, req.URL.Path)
+
+			var reqBody []byte
+			if req.Body != nil {
+				reqBody, _ = io.ReadAll(io.LimitReader(req.Body, maxCaptureBody+1))
+				req.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(reqBody), req.Body), req.Body}
+			}
+
+			cw := &captureWriter{statusWriter: statusWriter{ResponseWriter: w, status: http.StatusOK}}
+			next.ServeHTTP(cw, req)
+
+			e := &AccessLogEntry{
+				Sample: Sample{
+					Method:              req.Method,
+					Path:                req.URL.Path,
+					Query:               req.URL.Query(),
+					PathParams:          params,
+					Status:              cw.status,
+					RequestContentType:  mediaType(req.Header.Get("Content-Type")),
+					ResponseContentType: mediaType(cw.Header().Get("Content-Type")),
+				},
+				Duration: time.Since(start),
+			}
+			e.RequestBody = decodeJSONBody(e.RequestContentType, reqBody)
+			if !cw.truncated {
+				e.ResponseBody = decodeJSONBody(e.ResponseContentType, cw.body.Bytes())
+			}
+			if op != nil {
+				e.OperationID = op.OperationID
+				e.Route = routes[op]
+			}
+
+			redactSample(o, r, op, &e.Sample, true)
+			log(e)
+		})
+	}
+}
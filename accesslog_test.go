@@ -0,0 +1,105 @@
+package openapi_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type AccessLogCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"pin" writeOnly:"true"`
+	Note     string `json:"note" sensitive:"true"`
+}
+
+type AccessLogSession struct {
+	Username string `json:"username"`
+	Token    string `json:"token"`
+}
+
+func TestAccessLog(t *testing.T) {
+	builder := openapi.New("title", "version")
+	login := builder.Register(&openapi.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/sessions",
+	})
+	login.Request().Body(AccessLogCredentials{})
+	login.Response(http.StatusCreated).Body(AccessLogSession{})
+
+	var entries []*openapi.AccessLogEntry
+	handler := openapi.AccessLog(builder.OpenAPI(), nil, func(e *openapi.AccessLogEntry) {
+		entries = append(entries, e)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"username": "alice", "token": "abc"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/sessions?api_key=123&lang=en", bytes.NewReader([]byte(`{"username": "alice", "pin": "1234", "note": "private"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.OperationID != "login" || e.Route != "/sessions" || e.Status != http.StatusCreated {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	body := e.RequestBody.(map[string]any)
+	if body["username"] != "alice" || body["pin"] != "********" || body["note"] != "********" {
+		t.Errorf("expected the write only and sensitive properties to be masked, got %v", body)
+	}
+	if e.Query.Get("api_key") != "********" || e.Query.Get("lang") != "en" {
+		t.Errorf("unexpected query %v", e.Query)
+	}
+	if body := e.ResponseBody.(map[string]any); body["username"] != "alice" || body["token"] != "********" {
+		t.Errorf("unexpected response body %v", body)
+	}
+}
+
+type AccessLogSignup struct {
+	Username    string                `json:"username"`
+	Credentials *AccessLogCredentials `json:"credentials"`
+}
+
+func TestAccessLogNullable(t *testing.T) {
+	builder := openapi.New("title", "version").RegistryOptions(openapi.NullablePointers())
+	signup := builder.Register(&openapi.Operation{
+		OperationID: "signup",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	signup.Request().Body(AccessLogSignup{})
+	signup.Response(http.StatusCreated).Body(AccessLogSignup{})
+	op := builder.OpenAPI().Paths["/users"].Post
+	op.Responses["2XX"] = op.Responses["201"]
+	delete(op.Responses, "201")
+
+	var entries []*openapi.AccessLogEntry
+	handler := openapi.AccessLog(builder.OpenAPI(), nil, func(e *openapi.AccessLogEntry) {
+		entries = append(entries, e)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"username": "alice", "credentials": {"username": "alice", "note": "private"}}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(`{"username": "alice", "credentials": {"username": "alice", "pin": "1234", "note": "private"}}`)))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	credentials := entries[0].RequestBody.(map[string]any)["credentials"].(map[string]any)
+	if credentials["username"] != "alice" || credentials["pin"] != "********" || credentials["note"] != "********" {
+		t.Errorf("expected the nested write only and sensitive properties to be masked, got %v", credentials)
+	}
+	credentials = entries[0].ResponseBody.(map[string]any)["credentials"].(map[string]any)
+	if credentials["username"] != "alice" || credentials["note"] != "********" {
+		t.Errorf("expected the response to be redacted through the 2XX response, got %v", credentials)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package chi registers routes on a chi router and documents them as
+// operations of an openapi.Builder in one step, so routes are not defined
+// twice. The path parameters of the operation are derived from the chi
+// route pattern.
+//
+//	import chiadapter "github.com/restk/openapi/adapter/chi"
+//
+//	r := chi.NewRouter()
+//	api := chiadapter.New(r, builder)
+//	api.Get("/users/{id:[0-9]+}", getUser).
+//		Summary("Get a user").
+//		Response(http.StatusOK).Description("OK").Body(&User{})
+//
+// The package doesn't import chi; any router with chi's Method method, e.g.
+// *chi.Mux or the router passed to chi.Router.Route, can be used.
+package chi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/restk/openapi"
+)
+
+// Router is the part of chi.Router used by the adapter.
+type Router interface {
+	Method(method, pattern string, h http.Handler)
+}
+
+// Adapter registers routes on a router and the builder.
+type Adapter struct {
+	router  Router
+	builder *openapi.Builder
+	prefix  string
+}
+
+// New returns an adapter registering routes on r and operations on b.
+func New(r Router, b *openapi.Builder) *Adapter {
+	return &Adapter{
+		router:  r,
+		builder: b,
+	}
+}
+
+// Route returns an adapter for a sub-router mounted at prefix, e.g. the
+// router passed to chi.Router.Route. The operations it registers are
+// documented with the prefix prepended to their path.
+//
+//	r.Route("/v1", func(r chi.Router) {
+//		v1 := api.Route("/v1", r)
+//		v1.Get("/users", listUsers)
+//	})
+func (a *Adapter) Route(prefix string, r Router) *Adapter {
+	return &Adapter{
+		router:  r,
+		builder: a.builder,
+		prefix:  a.prefix + strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+// Handle registers h for the method and chi route pattern, and returns the
+// builder of its operation to document it further. The path parameters of
+// the pattern are documented as strings, with the regular expression of
+// parameters such as `{id:[0-9]+}` as their pattern.
+func (a *Adapter) Handle(method, pattern string, h http.Handler) *openapi.OperationBuilder {
+	a.router.Method(method, pattern, h)
+
+	path, params := convertPattern(a.prefix + pattern)
+	op := &openapi.Operation{
+		Method: method,
+		Path:   path,
+	}
+	ob := a.builder.Register(op)
+
+	for _, p := range params {
+		pb := ob.Request().PathParam(p.name, openapi.StringType)
+		if p.pattern != "" {
+			pb.Pattern("^" + p.pattern + "$")
+		}
+	}
+
+	return ob
+}
+
+// Get registers a GET route, see Handle.
+func (a *Adapter) Get(pattern string, h http.HandlerFunc) *openapi.OperationBuilder {
+	return a.Handle(http.MethodGet, pattern, h)
+}
+
+// Post registers a POST route, see Handle.
+func (a *Adapter) Post(pattern string, h http.HandlerFunc) *openapi.OperationBuilder {
+	return a.Handle(http.MethodPost, pattern, h)
+}
+
+// Put registers a PUT route, see Handle.
+func (a *Adapter) Put(pattern string, h http.HandlerFunc) *openapi.OperationBuilder {
+	return a.Handle(http.MethodPut, pattern, h)
+}
+
+// Patch registers a PATCH route, see Handle.
+func (a *Adapter) Patch(pattern string, h http.HandlerFunc) *openapi.OperationBuilder {
+	return a.Handle(http.MethodPatch, pattern, h)
+}
+
+// Delete registers a DELETE route, see Handle.
+func (a *Adapter) Delete(pattern string, h http.HandlerFunc) *openapi.OperationBuilder {
+	return a.Handle(http.MethodDelete, pattern, h)
+}
+
+type pathParam struct {
+	name    string
+	pattern string
+}
+
+// convertPattern converts a chi route pattern to an OpenAPI path, e.g.
+// `/users/{id:[0-9]+}` to `/users/{id}`. A trailing `*` wildcard becomes
+// the `{wildcard}` parameter.
+func convertPattern(pattern string) (string, []pathParam) {
+	var sb strings.Builder
+	var params []pathParam
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '{':
+			// Regular expressions may contain braces, e.g. `{id:[0-9]{4}}`.
+			depth, end := 0, i
+			for ; end < len(pattern); end++ {
+				if pattern[end] == '{' {
+					depth++
+				} else if pattern[end] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+
+			param := pathParam{name: pattern[i+1 : end]}
+			if colon := strings.IndexByte(param.name, ':'); colon >= 0 {
+				param.name, param.pattern = param.name[:colon], param.name[colon+1:]
+			}
+			params = append(params, param)
+			sb.WriteString("{" + param.name + "}")
+			i = end
+		case c == '*' && i == len(pattern)-1:
+			params = append(params, pathParam{name: "wildcard"})
+			sb.WriteString("{wildcard}")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), params
+}
@@ -0,0 +1,60 @@
+package chi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+	chiadapter "github.com/restk/openapi/adapter/chi"
+)
+
+type router struct {
+	routes []string
+}
+
+func (r *router) Method(method, pattern string, h http.Handler) {
+	r.routes = append(r.routes, method+" "+pattern)
+}
+
+func TestAdapter(t *testing.T) {
+	builder := openapi.New("title", "version")
+	r := &router{}
+	api := chiadapter.New(r, builder)
+
+	api.Get("/users/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {}).
+		Summary("Get a user")
+	api.Get("/files/*", func(w http.ResponseWriter, r *http.Request) {})
+
+	sub := &router{}
+	api.Route("/v1/", sub).Post("/orgs/{org}/members", func(w http.ResponseWriter, r *http.Request) {})
+
+	if len(r.routes) != 2 || r.routes[0] != "GET /users/{id:[0-9]+}" || len(sub.routes) != 1 || sub.routes[0] != "POST /orgs/{org}/members" {
+		t.Errorf("unexpected routes %v %v", r.routes, sub.routes)
+	}
+
+	o := builder.OpenAPI()
+	getUser := o.Paths["/users/{id}"].Get
+	if getUser == nil || getUser.Summary != "Get a user" {
+		t.Fatalf("expected GET /users/{id}, got %v", o.Paths)
+	}
+	if p := getUser.Parameters[0]; p.Name != "id" || p.In != "path" || p.Schema.Pattern != "^[0-9]+$" {
+		t.Errorf("unexpected param %v", p)
+	}
+	for value, valid := range map[string]bool{"42": true, "abc": false} {
+		res := &openapi.ValidateResult{}
+		openapi.Validate(builder.Registry(), getUser.Parameters[0].Schema, &openapi.PathBuffer{}, openapi.ModeWriteToServer, value, res)
+		if (len(res.Errors) == 0) != valid {
+			t.Errorf("unexpected errors for %s: %v", value, res.Errors)
+		}
+	}
+	if files := o.Paths["/files/{wildcard}"]; files == nil || files.Get.Parameters[0].Name != "wildcard" {
+		t.Errorf("expected GET /files/{wildcard}, got %v", o.Paths)
+	}
+	if members := o.Paths["/v1/orgs/{org}/members"]; members == nil || members.Post.Parameters[0].Name != "org" {
+		t.Errorf("expected POST /v1/orgs/{org}/members, got %v", o.Paths)
+	}
+
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("expected a valid document, got %v", errs)
+	}
+}
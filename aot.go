@@ -0,0 +1,345 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// PrebuiltSchema is a component schema generated ahead of time by
+// GenerateSchemas, see PrebuiltSchemas.
+type PrebuiltSchema struct {
+	// Type is the Go type the schema was generated for.
+	Type reflect.Type
+
+	// Name is the name of the component schema.
+	Name string
+
+	// Schema is the generated schema.
+	Schema *Schema
+
+	// PropertyOrder is the order of the properties in the Go struct, which is
+	// the order validation visits them in.
+	PropertyOrder []string
+}
+
+// PrebuiltSchemas makes the registry use schemas generated ahead of time by
+// GenerateSchemas instead of generating them through reflection, which cuts
+// cold start times, e.g. for serverless deployments. Types which are not
+// prebuilt are still generated at runtime.
+//
+//	builder := openapi.New("My API", "1.0.0").
+//		RegistryOptions(openapi.PrebuiltSchemas(api.Schemas...))
+func PrebuiltSchemas(schemas ...PrebuiltSchema) RegistryOption {
+	return registryOptionFunc(func(r *mapRegistry) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.names == nil {
+			r.names = map[reflect.Type]string{}
+		}
+		for _, prebuilt := range schemas {
+			s := prebuilt.Schema
+			if prebuilt.PropertyOrder != nil {
+				s.propertyNames = prebuilt.PropertyOrder
+			}
+			s.PrecomputeMessages()
+
+			r.order = append(r.order, prebuilt.Name)
+			r.schemas[prebuilt.Name] = s
+			r.types[prebuilt.Name] = prebuilt.Type
+			r.seen[prebuilt.Type] = true
+			r.names[prebuilt.Type] = prebuilt.Name
+		}
+	})
+}
+
+// Ptr returns a pointer to v. It is used by generated schema literals, e.g.
+// for `Minimum: openapi.Ptr(1.0)`.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// GenerateSchemas writes Go source declaring a `Schemas` variable with the
+// component schemas of types, and of every type they reference, as
+// PrebuiltSchema literals. The source belongs to package pkg, which must be
+// the package declaring the types. Typically it is called from a small program
+// run by `go generate`:
+//
+//	//go:generate go run ./cmd/schemas -o schemas_gen.go
+//	openapi.GenerateSchemas(w, "api", api.User{}, api.Order{})
+func GenerateSchemas(w io.Writer, pkg string, types ...any) error {
+	registry := NewMapRegistry("#/components/schemas/", DefaultSchemaNamer).(*mapRegistry)
+	pkgPath := ""
+	for _, v := range types {
+		t := deref(reflect.TypeOf(v))
+		if pkgPath == "" {
+			pkgPath = t.PkgPath()
+		}
+		registry.Schema(t, true, "")
+	}
+
+	names := make([]string, 0, len(registry.schemas))
+	for name := range registry.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by openapi.GenerateSchemas. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	buf.WriteString("import (\n\t\"reflect\"\n\n\t\"github.com/restk/openapi\"\n)\n\n")
+	buf.WriteString("// Schemas are the prebuilt component schemas, see openapi.PrebuiltSchemas.\n")
+	buf.WriteString("var Schemas = []openapi.PrebuiltSchema{\n")
+	for _, name := range names {
+		t := registry.types[name]
+		if t.PkgPath() != pkgPath || t.Name() == "" || bytes.ContainsAny([]byte(t.Name()), "[.") {
+			return fmt.Errorf("type %s must be a named, non-generic type of package %s", t, pkgPath)
+		}
+
+		s := registry.schemas[name]
+		fmt.Fprintf(buf, "{\nType: reflect.TypeOf(%s{}),\nName: %q,\nSchema: ", t.Name(), name)
+		if err := writeSchemaLiteral(buf, s); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+		buf.WriteString(",\n")
+		if len(s.propertyNames) > 0 {
+			buf.WriteString("PropertyOrder: []string{")
+			for i, p := range s.propertyNames {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				buf.WriteString(strconv.Quote(p))
+			}
+			buf.WriteString("},\n")
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// writeSchemaLiteral writes s as an `&openapi.Schema{...}` literal.
+func writeSchemaLiteral(buf *bytes.Buffer, s *Schema) error {
+	if s == nil {
+		buf.WriteString("nil")
+		return nil
+	}
+
+	buf.WriteString("&openapi.Schema{\n")
+
+	field := func(name, value string) {
+		buf.WriteString(name + ": " + value + ",\n")
+	}
+	str := func(name, value string) {
+		if value != "" {
+			field(name, strconv.Quote(value))
+		}
+	}
+	boolean := func(name string, value bool) {
+		if value {
+			field(name, "true")
+		}
+	}
+	float := func(name string, value *float64) {
+		if value != nil {
+			field(name, "openapi.Ptr(float64("+strconv.FormatFloat(*value, 'g', -1, 64)+"))")
+		}
+	}
+	integer := func(name string, value *int) {
+		if value != nil {
+			field(name, "openapi.Ptr("+strconv.Itoa(*value)+")")
+		}
+	}
+	var err error
+	schema := func(name string, value *Schema) {
+		if value != nil && err == nil {
+			buf.WriteString(name + ": ")
+			err = writeSchemaLiteral(buf, value)
+			buf.WriteString(",\n")
+		}
+	}
+	schemas := func(name string, values []*Schema) {
+		if len(values) > 0 && err == nil {
+			buf.WriteString(name + ": []*openapi.Schema{\n")
+			for _, value := range values {
+				if err == nil {
+					err = writeSchemaLiteral(buf, value)
+				}
+				buf.WriteString(",\n")
+			}
+			buf.WriteString("},\n")
+		}
+	}
+	value := func(name string, v any) {
+		if v != nil && err == nil {
+			var literal string
+			literal, err = goLiteral(v)
+			field(name, literal)
+		}
+	}
+
+	str("Type", s.Type)
+	boolean("Nullable", s.Nullable)
+	str("Title", s.Title)
+	str("Description", s.Description)
+	str("DescriptionKey", s.DescriptionKey)
+	str("Ref", s.Ref)
+	str("Format", s.Format)
+	str("ContentEncoding", s.ContentEncoding)
+	str("ContentMediaType", s.ContentMediaType)
+	value("Default", s.Default)
+	if s.Examples != nil {
+		value("Examples", s.Examples)
+	}
+	schema("Items", s.Items)
+	switch additional := s.AdditionalProperties.(type) {
+	case nil:
+	case bool:
+		field("AdditionalProperties", strconv.FormatBool(additional))
+	case *Schema:
+		schema("AdditionalProperties", additional)
+	default:
+		return fmt.Errorf("unsupported additionalProperties %T", additional)
+	}
+	if len(s.Properties) > 0 {
+		buf.WriteString("Properties: map[string]*openapi.Schema{\n")
+		for _, name := range sortedKeys(s.Properties) {
+			buf.WriteString(strconv.Quote(name) + ": ")
+			if err == nil {
+				err = writeSchemaLiteral(buf, s.Properties[name])
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("},\n")
+	}
+	if s.Enum != nil {
+		value("Enum", s.Enum)
+	}
+	float("Minimum", s.Minimum)
+	float("ExclusiveMinimum", s.ExclusiveMinimum)
+	float("Maximum", s.Maximum)
+	float("ExclusiveMaximum", s.ExclusiveMaximum)
+	float("MultipleOf", s.MultipleOf)
+	integer("MinLength", s.MinLength)
+	integer("MaxLength", s.MaxLength)
+	str("Pattern", s.Pattern)
+	str("PatternDescription", s.PatternDescription)
+	integer("MinItems", s.MinItems)
+	integer("MaxItems", s.MaxItems)
+	boolean("UniqueItems", s.UniqueItems)
+	if len(s.Required) > 0 {
+		value("Required", s.Required)
+	}
+	integer("MinProperties", s.MinProperties)
+	integer("MaxProperties", s.MaxProperties)
+	boolean("ReadOnly", s.ReadOnly)
+	boolean("WriteOnly", s.WriteOnly)
+	boolean("Deprecated", s.Deprecated)
+	if len(s.Extensions) > 0 {
+		value("Extensions", s.Extensions)
+	}
+	if len(s.DependentRequired) > 0 {
+		value("DependentRequired", s.DependentRequired)
+	}
+	schemas("OneOf", s.OneOf)
+	schemas("AnyOf", s.AnyOf)
+	schemas("AllOf", s.AllOf)
+	schema("Not", s.Not)
+	if d := s.Discriminator; d != nil {
+		buf.WriteString("Discriminator: &openapi.Discriminator{\n")
+		str("PropertyName", d.PropertyName)
+		if len(d.Mapping) > 0 {
+			value("Mapping", d.Mapping)
+		}
+		buf.WriteString("},\n")
+	}
+
+	buf.WriteString("}")
+	return err
+}
+
+// goLiteral returns the Go source of a value decoded from a struct tag, such
+// as a default or an example.
+func goLiteral(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return "float64(" + strconv.FormatFloat(v, 'g', -1, 64) + ")", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case []string:
+		parts := make([]any, len(v))
+		for i := range v {
+			parts[i] = v[i]
+		}
+		return sliceLiteral("[]string", parts)
+	case []any:
+		return sliceLiteral("[]any", v)
+	case map[string]any:
+		out := "map[string]any{"
+		for _, k := range sortedKeys(v) {
+			item, err := goLiteral(v[k])
+			if err != nil {
+				return "", err
+			}
+			out += strconv.Quote(k) + ": " + item + ", "
+		}
+		return out + "}", nil
+	case map[string]string:
+		out := "map[string]string{"
+		for _, k := range sortedKeys(v) {
+			out += strconv.Quote(k) + ": " + strconv.Quote(v[k]) + ", "
+		}
+		return out + "}", nil
+	case map[string][]string:
+		out := "map[string][]string{"
+		for _, k := range sortedKeys(v) {
+			item, _ := goLiteral(v[k])
+			out += strconv.Quote(k) + ": " + item + ", "
+		}
+		return out + "}", nil
+	}
+	return "", fmt.Errorf("unsupported value %T", v)
+}
+
+func sliceLiteral(typ string, values []any) (string, error) {
+	out := typ + "{"
+	for _, v := range values {
+		item, err := goLiteral(v)
+		if err != nil {
+			return "", err
+		}
+		out += item + ", "
+	}
+	return out + "}", nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+// Code generated by openapi.GenerateSchemas. DO NOT EDIT.
+
+package openapi_test
+
+import (
+	"reflect"
+
+	"github.com/restk/openapi"
+)
+
+// Schemas are the prebuilt component schemas, see openapi.PrebuiltSchemas.
+var Schemas = []openapi.PrebuiltSchema{
+	{
+		Type: reflect.TypeOf(PrebuiltCard{}),
+		Name: "PrebuiltCard",
+		Schema: &openapi.Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*openapi.Schema{
+				"method": &openapi.Schema{
+					Type: "string",
+				},
+				"number": &openapi.Schema{
+					Type: "string",
+				},
+			},
+			Required: []string{"method", "number"},
+		},
+		PropertyOrder: []string{"method", "number"},
+	},
+	{
+		Type: reflect.TypeOf(PrebuiltCustomer{}),
+		Name: "PrebuiltCustomer",
+		Schema: &openapi.Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*openapi.Schema{
+				"name": &openapi.Schema{
+					Type:        "string",
+					Description: "Full name",
+					MaxLength:   openapi.Ptr(80),
+				},
+				"score": &openapi.Schema{
+					Type:             "number",
+					Format:           "double",
+					ExclusiveMaximum: openapi.Ptr(float64(100.5)),
+				},
+			},
+			Required: []string{"name", "score"},
+		},
+		PropertyOrder: []string{"name", "score"},
+	},
+	{
+		Type: reflect.TypeOf(PrebuiltOrder{}),
+		Name: "PrebuiltOrder",
+		Schema: &openapi.Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*openapi.Schema{
+				"customer": &openapi.Schema{
+					Ref: "#/components/schemas/PrebuiltCustomer",
+				},
+				"id": &openapi.Schema{
+					Type:     "integer",
+					Format:   "int64",
+					Examples: []any{float64(42)},
+					Minimum:  openapi.Ptr(float64(1)),
+				},
+				"notes": &openapi.Schema{
+					Type: "object",
+					AdditionalProperties: &openapi.Schema{
+						Type: "string",
+					},
+				},
+				"payment": &openapi.Schema{
+					OneOf: []*openapi.Schema{
+						&openapi.Schema{
+							Ref: "#/components/schemas/PrebuiltCard",
+						},
+						&openapi.Schema{
+							Ref: "#/components/schemas/PrebuiltTransfer",
+						},
+					},
+					Discriminator: &openapi.Discriminator{
+						PropertyName: "method",
+						Mapping:      map[string]string{"card": "#/components/schemas/PrebuiltCard", "transfer": "#/components/schemas/PrebuiltTransfer"},
+					},
+				},
+				"secret": &openapi.Schema{
+					Type:       "string",
+					Extensions: map[string]any{"x-sensitive": true},
+				},
+				"status": &openapi.Schema{
+					Type:    "string",
+					Default: "open",
+					Enum:    []any{"open", "closed"},
+				},
+				"tags": &openapi.Schema{
+					Type: "array",
+					Items: &openapi.Schema{
+						Type: "string",
+					},
+					MaxItems: openapi.Ptr(10),
+				},
+			},
+			Required: []string{"id", "status", "customer", "secret", "payment"},
+		},
+		PropertyOrder: []string{"id", "status", "tags", "customer", "notes", "secret", "payment"},
+	},
+	{
+		Type: reflect.TypeOf(PrebuiltTransfer{}),
+		Name: "PrebuiltTransfer",
+		Schema: &openapi.Schema{
+			Type:                 "object",
+			AdditionalProperties: false,
+			Properties: map[string]*openapi.Schema{
+				"iban": &openapi.Schema{
+					Type: "string",
+				},
+				"method": &openapi.Schema{
+					Type: "string",
+				},
+			},
+			Required: []string{"method", "iban"},
+		},
+		PropertyOrder: []string{"method", "iban"},
+	},
+}
@@ -0,0 +1,88 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type PrebuiltOrder struct {
+	ID       int               `json:"id" minimum:"1" example:"42"`
+	Status   string            `json:"status" enum:"open,closed" default:"open"`
+	Tags     []string          `json:"tags,omitempty" maxItems:"10"`
+	Customer PrebuiltCustomer  `json:"customer"`
+	Notes    map[string]string `json:"notes,omitempty"`
+	Secret   string            `json:"secret" sensitive:"true"`
+	Payment  PrebuiltPayment   `json:"payment"`
+}
+
+type PrebuiltCard struct {
+	Method string `json:"method"`
+	Number string `json:"number"`
+}
+
+type PrebuiltTransfer struct {
+	Method string `json:"method"`
+	IBAN   string `json:"iban"`
+}
+
+// PrebuiltPayment is an inline oneOf with a discriminator.
+type PrebuiltPayment struct{}
+
+func (PrebuiltPayment) Schema(r openapi.Registry) *openapi.Schema {
+	card := r.Schema(reflect.TypeOf(PrebuiltCard{}), true, "")
+	transfer := r.Schema(reflect.TypeOf(PrebuiltTransfer{}), true, "")
+	return &openapi.Schema{
+		OneOf: []*openapi.Schema{card, transfer},
+		Discriminator: &openapi.Discriminator{
+			PropertyName: "method",
+			Mapping:      map[string]string{"card": card.Ref, "transfer": transfer.Ref},
+		},
+	}
+}
+
+type PrebuiltCustomer struct {
+	Name  string  `json:"name" doc:"Full name" maxLength:"80"`
+	Score float64 `json:"score" exclusiveMaximum:"100.5"`
+}
+
+func TestGenerateSchemas(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := openapi.GenerateSchemas(buf, "openapi_test", PrebuiltOrder{}); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := os.ReadFile("aot_gen_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(generated) {
+		t.Errorf("aot_gen_test.go is out of date, got\n%s", buf.String())
+	}
+}
+
+func TestPrebuiltSchemas(t *testing.T) {
+	reflected := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	reflected.Schema(reflect.TypeOf(PrebuiltOrder{}), true, "")
+
+	prebuilt := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer, openapi.PrebuiltSchemas(Schemas...))
+	if ref := prebuilt.Schema(reflect.TypeOf(PrebuiltOrder{}), true, ""); ref.Ref != "#/components/schemas/PrebuiltOrder" {
+		t.Errorf("unexpected ref %v", ref)
+	}
+
+	expected, _ := json.Marshal(reflected)
+	actual, _ := json.Marshal(prebuilt)
+	if string(expected) != string(actual) {
+		t.Errorf("expected prebuilt schemas to match reflected ones\n%s\n%s", expected, actual)
+	}
+
+	res := &openapi.ValidateResult{}
+	openapi.Validate(prebuilt, prebuilt.Map()["PrebuiltOrder"], &openapi.PathBuffer{}, openapi.ModeReadFromServer, map[string]any{"id": 0.0}, res)
+	if len(res.Errors) == 0 {
+		t.Errorf("expected validation errors from the prebuilt schema")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Finding is a problem reported by an analyzer such as AuditSecurity. Findings
+// are meant to be printed or used to fail a CI build.
+type Finding struct {
+	// Rule identifies the check, e.g. `missing-security`.
+	Rule string `json:"rule"`
+
+	// Location of the problem, e.g. `paths./users.get`.
+	Location string `json:"location"`
+
+	// OperationID of the affected operation, if any.
+	OperationID string `json:"operationId,omitempty"`
+
+	// Message is a human-readable explanation of the finding.
+	Message string `json:"message"`
+}
+
+func (f *Finding) String() string {
+	return f.Rule + ": " + f.Location + ": " + f.Message
+}
+
+// AuditSecurity reports operations which have no security requirement,
+// operations documenting a 401 response without a WWW-Authenticate header,
+// and security schemes which are never used. Operations in allowlist, given
+// by operation ID or as `METHOD /path`, are allowed to have no security.
+func (o *OpenAPI) AuditSecurity(allowlist ...string) []*Finding {
+	allowed := map[string]bool{}
+	for _, entry := range allowlist {
+		allowed[entry] = true
+	}
+
+	var findings []*Finding
+	used := map[string]bool{}
+
+	markUsed := func(security []map[string][]string) {
+		for _, requirement := range security {
+			for name := range requirement {
+				used[name] = true
+			}
+		}
+	}
+	markUsed(o.Security)
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		location := operationLocation(path, method)
+
+		security := op.Security
+		if security == nil {
+			security = o.Security
+		}
+		markUsed(op.Security)
+
+		if !allowed[op.OperationID] && !allowed[method+" "+path] {
+			optional := len(security) == 0
+			for _, requirement := range security {
+				if len(requirement) == 0 {
+					optional = true
+				}
+			}
+			if optional {
+				findings = append(findings, &Finding{
+					Rule:        "missing-security",
+					Location:    location,
+					OperationID: op.OperationID,
+					Message:     "operation can be called without authentication",
+				})
+			}
+		}
+
+		if resp := o.resolveResponse(op.Responses["401"]); resp != nil {
+			found := false
+			for name := range resp.Headers {
+				if strings.EqualFold(name, "WWW-Authenticate") {
+					found = true
+				}
+			}
+			if !found {
+				findings = append(findings, &Finding{
+					Rule:        "missing-www-authenticate",
+					Location:    location + ".responses.401",
+					OperationID: op.OperationID,
+					Message:     "401 response does not document the WWW-Authenticate header",
+				})
+			}
+		}
+	})
+
+	if o.Components != nil {
+		names := make([]string, 0, len(o.Components.SecuritySchemes))
+		for name := range o.Components.SecuritySchemes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !used[name] {
+				findings = append(findings, &Finding{
+					Rule:     "unused-security-scheme",
+					Location: "components.securitySchemes." + name,
+					Message:  "security scheme is not used by any operation",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// resolveResponse returns the response, following a reference to
+// components.responses.
+func (o *OpenAPI) resolveResponse(resp *Response) *Response {
+	if resp != nil && resp.Ref != "" && o.Components != nil {
+		return o.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+	}
+	return resp
+}
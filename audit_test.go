@@ -0,0 +1,53 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestAuditSecurity(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.BearerAuth().BasicAuth()
+
+	listUsers := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Security("BearerAuth", nil)
+	listUsers.Response(http.StatusUnauthorized).Header("WWW-Authenticate", openapi.StringType)
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{userId}",
+	})
+	getUser.Response(http.StatusUnauthorized)
+
+	builder.Register(&openapi.Operation{
+		OperationID: "health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+	})
+
+	findings := builder.OpenAPI().AuditSecurity("health")
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule+" "+f.Location)
+	}
+	expected := []string{
+		"missing-security paths./users/{userId}.get",
+		"missing-www-authenticate paths./users/{userId}.get.responses.401",
+		"unused-security-scheme components.securitySchemes.BasicAuth",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got[i])
+		}
+	}
+}
@@ -9,12 +9,29 @@ package openapi
 import (
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // Builder provides builders for building an OpenAPI spec from code
 type Builder struct {
 	openAPI *OpenAPI
+
+	templateValues map[string]any
+	redactor       *Redactor
+	featureFlags   func(flag string) bool
+	splitReadWrite bool
+	mapError       func(err error) (int, any)
+	operationID    func(method, path string) string
+	deprecation    bool
+	standardErrors []int
+	warnings       []*ErrorDetail
+
+	responseHeaders []*responseHeader
 }
 
 // New returns an OpenAPI builder that can be used to easily generate OpenAPI specs from code.
@@ -33,6 +50,7 @@ func New(title, version string) *Builder {
 			Schemas:         registry,
 			SecuritySchemes: make(map[string]*SecurityScheme),
 		},
+		building: true,
 	}
 
 	return &Builder{
@@ -154,43 +172,211 @@ func (b *Builder) TermsOfService(url string) *Builder {
 	return b
 }
 
+// ExternalDocs links the API to additional documentation, e.g. a developer
+// guide.
+func (b *Builder) ExternalDocs(url, description string) *Builder {
+	b.openAPI.ExternalDocs = newExternalDocs(url, description)
+
+	return b
+}
+
+// Tag declares a tag in the root `tags` array so tools can show its
+// description, or returns the builder of the tag if it is already declared.
+// Tags are displayed in the order they are declared, see TagBuilder.Order.
+// Tags which operations use but which are never declared are added by Build.
+func (b *Builder) Tag(name string) *TagBuilder {
+	if name == "" {
+		panic("tag name must be specified")
+	}
+
+	for _, tag := range b.openAPI.Tags {
+		if tag.Name == name {
+			return &TagBuilder{tag: tag, openAPI: b.openAPI}
+		}
+	}
+
+	tag := &Tag{Name: name}
+	b.openAPI.Tags = append(b.openAPI.Tags, tag)
+
+	return &TagBuilder{tag: tag, openAPI: b.openAPI}
+}
+
+// TagBuilder helps with building a Tag
+type TagBuilder struct {
+	tag     *Tag
+	openAPI *OpenAPI
+}
+
+// Description sets the description of the tag
+func (tb *TagBuilder) Description(description string) *TagBuilder {
+	tb.tag.Description = description
+
+	return tb
+}
+
+// ExternalDocs links the tag to additional documentation
+func (tb *TagBuilder) ExternalDocs(url, description string) *TagBuilder {
+	tb.tag.ExternalDocs = newExternalDocs(url, description)
+
+	return tb
+}
+
+// newExternalDocs returns the external docs at url, which is required.
+func newExternalDocs(url, description string) *ExternalDocs {
+	if url == "" {
+		panic("external docs url must be specified")
+	}
+
+	return &ExternalDocs{
+		URL:         url,
+		Description: description,
+	}
+}
+
+// Order moves the tag to the given position of the root `tags` array, which
+// most tools use to order the groups of operations. Positions past the end
+// move it to the end.
+func (tb *TagBuilder) Order(position int) *TagBuilder {
+	tags := tb.openAPI.Tags
+	for i, tag := range tags {
+		if tag == tb.tag {
+			tags = append(tags[:i], tags[i+1:]...)
+			break
+		}
+	}
+	if position < 0 {
+		position = 0
+	}
+	if position > len(tags) {
+		position = len(tags)
+	}
+
+	tags = append(tags, nil)
+	copy(tags[position+1:], tags[position:])
+	tags[position] = tb.tag
+	tb.openAPI.Tags = tags
+
+	return tb
+}
+
+// declareTags adds the tags used by operations which are not declared in the
+// root `tags` array, sorted.
+func declareTags(o *OpenAPI) {
+	declared := map[string]bool{}
+	for _, tag := range o.Tags {
+		declared[tag.Name] = true
+	}
+
+	used := map[string]bool{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		for _, tag := range op.Tags {
+			if !declared[tag] {
+				used[tag] = true
+			}
+		}
+	})
+
+	for _, name := range sortedKeys(used) {
+		o.Tags = append(o.Tags, &Tag{Name: name})
+	}
+}
+
+// BuildInfo describes the build which produced the spec. It is stamped into
+// the Info object as the `x-build-info` extension by Builder.BuildInfo.
+type BuildInfo struct {
+	Version   string     `json:"version,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	BuildTime *time.Time `json:"buildTime,omitempty"`
+}
+
+// BuildInfo stamps the version, commit and build time of the service into the
+// Info object as the `x-build-info` extension. A zero build time is omitted.
+// See VersionHandler for serving it.
+func (b *Builder) BuildInfo(version, commit string, buildTime time.Time) *Builder {
+	if b.openAPI.Info.Extensions == nil {
+		b.openAPI.Info.Extensions = map[string]any{}
+	}
+
+	info := &BuildInfo{
+		Version: version,
+		Commit:  commit,
+	}
+	if !buildTime.IsZero() {
+		utc := buildTime.UTC()
+		info.BuildTime = &utc
+	}
+	b.openAPI.Info.Extensions["x-build-info"] = info
+
+	return b
+}
+
+// ChangelogEntry is a single release in the `x-changelog` extension.
+type ChangelogEntry struct {
+	Version string `json:"version"`
+	Date    string `json:"date,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// ChangelogEntry appends a release to the `x-changelog` extension of the Info
+// object, so the release history ships alongside the spec. Entries are kept in
+// the order they are added, date is free-form but should be `YYYY-MM-DD`.
+func (b *Builder) ChangelogEntry(version, date, notes string) *Builder {
+	if version == "" {
+		panic("version must be specified")
+	}
+
+	if b.openAPI.Info.Extensions == nil {
+		b.openAPI.Info.Extensions = map[string]any{}
+	}
+
+	changelog, _ := b.openAPI.Info.Extensions["x-changelog"].([]*ChangelogEntry)
+	b.openAPI.Info.Extensions["x-changelog"] = append(changelog, &ChangelogEntry{
+		Version: version,
+		Date:    date,
+		Notes:   notes,
+	})
+
+	return b
+}
+
 // BasicAuth adds a BasicAuth security schema
 func (b *Builder) BasicAuth() *Builder {
-	b.openAPI.Components.SecuritySchemes["BasicAuth"] = &SecurityScheme{
+	b.addSecurityScheme("BasicAuth", &SecurityScheme{
 		Type:   "http",
 		Scheme: "basic",
-	}
+	})
 
 	return b
 }
 
 // BearerAuth adds a BearerAuth security schema
 func (b *Builder) BearerAuth() *Builder {
-	b.openAPI.Components.SecuritySchemes["BearerAuth"] = &SecurityScheme{
+	b.addSecurityScheme("BearerAuth", &SecurityScheme{
 		Type:   "http",
 		Scheme: "bearer",
-	}
+	})
 
 	return b
 }
 
 // ApiKeyAuth adds a ApiKeyAuth security schema. We expect the API key to be in a Header and you specify the header as the argument to this function
 func (b *Builder) ApiKeyAuth(header string) *Builder {
-	b.openAPI.Components.SecuritySchemes["ApiKeyAuth"] = &SecurityScheme{
+	b.addSecurityScheme("ApiKeyAuth", &SecurityScheme{
 		Type: "apiKey",
 		In:   "header",
 		Name: header,
-	}
+	})
 
 	return b
 }
 
-// OpenID adds a OpenID security schema. url is an OpenId Connect URL to discover OAuth2
+// OpenID adds a OpenID security schema named "OpenID", apply it with
+// `Security("OpenID", scopes)`. url is an OpenId Connect URL to discover OAuth2
 func (b *Builder) OpenID(url string) *Builder {
-	b.openAPI.Components.SecuritySchemes["ApiKeyAuth"] = &SecurityScheme{
+	b.addSecurityScheme("OpenID", &SecurityScheme{
 		Type:             "openIdConnect",
 		OpenIDConnectURL: url,
-	}
+	})
 
 	return b
 }
@@ -198,10 +384,10 @@ func (b *Builder) OpenID(url string) *Builder {
 func (b *Builder) OAuth2() *OAuth2Builder {
 	flows := &OAuthFlows{}
 
-	b.openAPI.Components.SecuritySchemes["OAuth2"] = &SecurityScheme{
+	b.addSecurityScheme("OAuth2", &SecurityScheme{
 		Type:  "oauth2",
 		Flows: flows,
-	}
+	})
 
 	return &OAuth2Builder{
 		flows: flows,
@@ -236,7 +422,7 @@ func (ob *OAuth2Builder) ClientCredentials() *OAuthFlowBuilder {
 	ob.flows.ClientCredentials = &OAuthFlow{}
 
 	return &OAuthFlowBuilder{
-		flow: ob.flows.Password,
+		flow: ob.flows.ClientCredentials,
 	}
 }
 
@@ -284,11 +470,21 @@ func (b *OAuthFlowBuilder) Scopes(scopes map[string]string) *OAuthFlowBuilder {
 
 // SecurityScheme adds a custom SecurityScheme.
 func (b *Builder) SecurityScheme(name string, scheme *SecurityScheme) *Builder {
-	b.openAPI.Components.SecuritySchemes[name] = scheme
+	b.addSecurityScheme(name, scheme)
 
 	return b
 }
 
+// addSecurityScheme adds a security scheme, recording the order for
+// OrderRegistration.
+func (b *Builder) addSecurityScheme(name string, scheme *SecurityScheme) {
+	c := b.openAPI.Components
+	if _, ok := c.SecuritySchemes[name]; !ok {
+		c.securitySchemeOrder = append(c.securitySchemeOrder, name)
+	}
+	c.SecuritySchemes[name] = scheme
+}
+
 // Register registers a new Operation.
 func (b *Builder) Register(op *Operation) *OperationBuilder {
 	if op.Method == "" || op.Path == "" {
@@ -301,9 +497,232 @@ func (b *Builder) Register(op *Operation) *OperationBuilder {
 	return &OperationBuilder{
 		op:      op,
 		openAPI: b.openAPI,
+		builder: b,
+	}
+}
+
+// Webhook adds an operation to the top-level `webhooks` map (OpenAPI 3.1),
+// describing a request the API sends to its consumers when the event called
+// name happens. The method defaults to POST and the path is ignored, as the
+// consumers choose the URL. Like AddOperation, this calls the hooks and any
+// registered OnAddOperation functions. The request and responses are built
+// like the ones of Register, e.g.
+//
+//	hook := builder.Webhook("userCreated", &openapi.Operation{OperationID: "userCreated"})
+//	hook.Request().Body(UserCreatedEvent{})
+//	hook.Response(http.StatusNoContent).Description("Event received")
+func (b *Builder) Webhook(name string, op *Operation) *OperationBuilder {
+	if name == "" {
+		panic("webhook name must be specified")
+	}
+	if op.Method == "" {
+		op.Method = http.MethodPost
+	}
+	op.Responses = make(map[string]*Response)
+
+	if b.openAPI.Webhooks == nil {
+		b.openAPI.Webhooks = map[string]*PathItem{}
+	}
+	item := b.openAPI.Webhooks[name]
+	if item == nil {
+		item = &PathItem{}
+		b.openAPI.Webhooks[name] = item
+	}
+	item.SetOperation(op)
+	b.openAPI.Invalidate()
+
+	b.openAPI.Hooks.operationRegistered(op)
+	for _, f := range b.openAPI.OnAddOperation {
+		f(b.openAPI, op)
+	}
+
+	return &OperationBuilder{
+		op:      op,
+		openAPI: b.openAPI,
+		builder: b,
+	}
+}
+
+// StandardError adds a canonical error response to components.responses,
+// named after the status text (e.g. `NotFound`), which is attached to
+// operations by OperationBuilder.StandardErrors. f is the type of the body,
+// or nil for a response without one.
+func (b *Builder) StandardError(status int, f any) *Builder {
+	name := strings.ReplaceAll(http.StatusText(status), " ", "")
+	if name == "" {
+		panic("unknown status " + strconv.Itoa(status))
+	}
+
+	response := &Response{
+		Description: http.StatusText(status),
+	}
+	if f != nil {
+		response.Content = map[string]*MediaType{
+			"application/json": {
+				Schema: b.openAPI.Components.Schemas.Schema(reflect.TypeOf(f), true, ""),
+			},
+		}
+	}
+
+	if b.openAPI.Components.Responses == nil {
+		b.openAPI.Components.Responses = map[string]*Response{}
+	}
+	if _, ok := b.openAPI.Components.Responses[name]; !ok {
+		b.standardErrors = append(b.standardErrors, status)
+	}
+	b.openAPI.Components.Responses[name] = response
+
+	return b
+}
+
+// RegistryOptions applies options to the schema registry of the builder, such
+// as StrictTags. Options only affect schemas generated afterwards, so call
+// this before registering operations.
+func (b *Builder) RegistryOptions(opts ...RegistryOption) *Builder {
+	r, ok := b.openAPI.Components.Schemas.(*mapRegistry)
+	if !ok {
+		panic("registry options are only supported by the map registry")
+	}
+
+	for _, opt := range opts {
+		opt.apply(r)
+	}
+
+	return b
+}
+
+// Preload generates the component schemas of the given root types using
+// workers goroutines, cutting the start-up time of services with hundreds of
+// types. Registering operations afterwards only creates references to the
+// preloaded schemas. A panic while generating a schema, e.g. for an invalid
+// tag, is re-raised by Preload. The map registry is safe for concurrent use,
+// custom registries must be as well.
+func (b *Builder) Preload(workers int, types ...any) *Builder {
+	if workers < 1 {
+		workers = 1
+	}
+
+	registry := b.openAPI.Components.Schemas
+	queue := make(chan reflect.Type)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure any
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { failure = r })
+					// Drain the queue so the producer doesn't block.
+					for range queue {
+					}
+				}
+			}()
+
+			for t := range queue {
+				registry.Schema(t, true, "")
+			}
+		}()
+	}
+
+	for _, t := range types {
+		queue <- reflect.TypeOf(t)
+	}
+	close(queue)
+	wg.Wait()
+
+	if failure != nil {
+		panic(failure)
+	}
+
+	return b
+}
+
+// Path returns a PathItemBuilder for the path, creating the path item if
+// needed, so resource-level summaries, descriptions, servers and parameters
+// can be set once instead of on every operation.
+func (b *Builder) Path(path string) *PathItemBuilder {
+	if path == "" {
+		panic("path must be specified")
+	}
+
+	item := b.openAPI.PathItem(path)
+	if item == nil {
+		item = &PathItem{}
+		b.openAPI.AddPathItem(path, item)
+	}
+
+	return &PathItemBuilder{
+		builder: b,
+		path:    path,
+		item:    item,
+	}
+}
+
+// PathItemBuilder helps build a PathItem
+type PathItemBuilder struct {
+	builder *Builder
+	path    string
+	item    *PathItem
+}
+
+// Summary sets the summary of the path item
+func (pib *PathItemBuilder) Summary(summary string) *PathItemBuilder {
+	pib.item.Summary = summary
+
+	return pib
+}
+
+// Description sets the description of the path item
+func (pib *PathItemBuilder) Description(description string) *PathItemBuilder {
+	pib.item.Description = description
+
+	return pib
+}
+
+// Server adds a server for all operations of the path item
+func (pib *PathItemBuilder) Server() *ServerBuilder {
+	server := &Server{}
+
+	pib.item.Servers = append(pib.item.Servers, server)
+
+	return &ServerBuilder{
+		server: server,
 	}
 }
 
+// PathParam adds a path param shared by all operations of the path item
+func (pib *PathItemBuilder) PathParam(name string, f any) *ParamBuilder {
+	registry := pib.builder.openAPI.Components.Schemas
+
+	param := &Param{
+		Name:     name,
+		In:       "path",
+		Schema:   registry.Schema(reflect.TypeOf(f), true, ""),
+		Required: true,
+	}
+
+	pib.item.Parameters = append(pib.item.Parameters, param)
+
+	return &ParamBuilder{
+		param: param,
+	}
+}
+
+// Register registers an operation on the path item. op.Path may be left empty.
+func (pib *PathItemBuilder) Register(op *Operation) *OperationBuilder {
+	if op.Path == "" {
+		op.Path = pib.path
+	}
+	if op.Path != pib.path {
+		panic("operation path " + op.Path + " does not match path item " + pib.path)
+	}
+
+	return pib.builder.Register(op)
+}
+
 // FindOperationIdByTag finds the first operation with the tag and returns its id. If nothing is found, this returns an empty string.
 /*
 func (b *Builder) FindOperationIdByTag(tag string) string {
@@ -358,6 +777,7 @@ func (b *Builder) FindOperationIdByTag(tag string) string {
 type OperationBuilder struct {
 	op      *Operation
 	openAPI *OpenAPI
+	builder *Builder
 }
 
 // Tag adds a tag
@@ -367,6 +787,14 @@ func (ob *OperationBuilder) Tag(tag string) *OperationBuilder {
 	return ob
 }
 
+// OperationID sets the operation ID, which code generators use to name the
+// method of the operation.
+func (ob *OperationBuilder) OperationID(id string) *OperationBuilder {
+	ob.op.OperationID = id
+
+	return ob
+}
+
 // Summary sets the summary.
 func (ob *OperationBuilder) Summary(summary string) *OperationBuilder {
 	ob.op.Summary = summary
@@ -381,6 +809,35 @@ func (ob *OperationBuilder) Description(description string) *OperationBuilder {
 	return ob
 }
 
+// ExternalDocs links the operation to additional documentation, e.g. a
+// long-form guide.
+func (ob *OperationBuilder) ExternalDocs(url, description string) *OperationBuilder {
+	ob.op.ExternalDocs = newExternalDocs(url, description)
+
+	return ob
+}
+
+// Deprecated marks the operation as deprecated, see Builder.DeprecationNotices.
+func (ob *OperationBuilder) Deprecated() *OperationBuilder {
+	ob.op.Deprecated = true
+
+	return ob
+}
+
+// Extension sets a vendor extension on the operation, name must start with `x-`
+func (ob *OperationBuilder) Extension(name string, value any) *OperationBuilder {
+	if len(name) < 2 || name[:2] != "x-" {
+		panic("extension name must start with x-")
+	}
+
+	if ob.op.Extensions == nil {
+		ob.op.Extensions = map[string]any{}
+	}
+	ob.op.Extensions[name] = value
+
+	return ob
+}
+
 // Security sets the security for this operation
 func (ob *OperationBuilder) Security(securitySchema string, params []string) *OperationBuilder {
 	if ob.op.Security == nil {
@@ -406,16 +863,53 @@ func (ob *OperationBuilder) Server() *ServerBuilder {
 	}
 }
 
+// StandardErrors attaches the standard error responses of the builder, see
+// Builder.StandardError, as references to components.responses. If none were
+// configured, 400, 401, 403, 404, 429 and 500 responses without a body are
+// added to the builder first. Statuses the operation already documents are
+// left alone.
+func (ob *OperationBuilder) StandardErrors() *OperationBuilder {
+	b := ob.builder
+	if len(b.standardErrors) == 0 {
+		for _, status := range []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+		} {
+			b.StandardError(status, nil)
+		}
+	}
+
+	for _, status := range b.standardErrors {
+		statusStr := strconv.Itoa(status)
+		if ob.op.Responses[statusStr] != nil {
+			continue
+		}
+		ob.op.Responses[statusStr] = &Response{
+			Ref: "#/components/responses/" + strings.ReplaceAll(http.StatusText(status), " ", ""),
+		}
+		ob.op.responseOrder = append(ob.op.responseOrder, statusStr)
+	}
+
+	return ob
+}
+
 // Response adds a response with a status code.
 func (ob *OperationBuilder) Response(status int) *ResponseBuilder {
 	statusStr := strconv.Itoa(status)
 
 	if ob.op.Responses[statusStr] == nil {
 		ob.op.Responses[statusStr] = &Response{}
+		ob.op.responseOrder = append(ob.op.responseOrder, statusStr)
 	}
 
 	return &ResponseBuilder{
 		openAPI:            ob.openAPI,
+		builder:            ob.builder,
+		location:           operationLocation(ob.op.Path, ob.op.Method) + ".responses." + statusStr,
 		response:           ob.op.Responses[statusStr],
 		defaultContentType: "application/json",
 		nextContentType:    "",
@@ -424,12 +918,21 @@ func (ob *OperationBuilder) Response(status int) *ResponseBuilder {
 
 type ResponseBuilder struct {
 	openAPI  *OpenAPI
+	builder  *Builder
+	location string
 	response *Response
 
 	defaultContentType string
 	nextContentType    string
 }
 
+// Description sets the description of the Response
+func (rb *ResponseBuilder) Description(description string) *ResponseBuilder {
+	rb.response.Description = description
+
+	return rb
+}
+
 // DefaultContentType sets the default content type of the Response. By default, the content type is application/json
 func (rb *ResponseBuilder) DefaultContentType(contentType string) *ResponseBuilder {
 	rb.defaultContentType = contentType
@@ -453,6 +956,55 @@ func (rb *ResponseBuilder) Body(f any) *MediaTypeBuilder {
 	registry := rb.openAPI.Components.Schemas
 	schema := registry.Schema(responseType, true, "")
 
+	return rb.body(schema)
+}
+
+// OneOfBodies adds a body which is one of several types, for responses whose
+// payload varies, e.g. by a query parameter. Use Discriminator on the
+// returned builder to tell clients which type applies.
+func (rb *ResponseBuilder) OneOfBodies(bodies ...any) *MediaTypeBuilder {
+	if len(bodies) < 2 {
+		panic("OneOfBodies needs at least two bodies")
+	}
+
+	return rb.body(OneOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// AnyOfBodies adds a body which matches at least one of several types. See
+// OneOfBodies.
+func (rb *ResponseBuilder) AnyOfBodies(bodies ...any) *MediaTypeBuilder {
+	if len(bodies) < 2 {
+		panic("AnyOfBodies needs at least two bodies")
+	}
+
+	return rb.body(AnyOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// AllOfBodies adds a body which matches all of several types, e.g. a base
+// type and its extension.
+func (rb *ResponseBuilder) AllOfBodies(bodies ...any) *MediaTypeBuilder {
+	return rb.body(AllOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// VersionedBody adds the body f under the vendor media type of every version,
+// e.g. `application/vnd.myco.v2+json` for VersionedBody("myco", User{}, "v2"),
+// for APIs versioned through the Accept header. See VendorMediaType.
+func (rb *ResponseBuilder) VersionedBody(vendor string, f any, versions ...string) *ResponseBuilder {
+	if len(versions) == 0 {
+		panic("at least one version must be specified")
+	}
+
+	schema := rb.openAPI.Components.Schemas.Schema(reflect.TypeOf(f), true, "")
+	for _, version := range versions {
+		rb.nextContentType = VendorMediaType(vendor, version)
+		rb.body(schema)
+	}
+
+	return rb
+}
+
+// body sets the schema of the content type of the next body.
+func (rb *ResponseBuilder) body(schema *Schema) *MediaTypeBuilder {
 	var contentType string
 	var resetNextContentType bool
 	if rb.nextContentType != "" {
@@ -470,6 +1022,8 @@ func (rb *ResponseBuilder) Body(f any) *MediaTypeBuilder {
 	}
 	if rb.response.Content[contentType] != nil && rb.response.Content[contentType].Schema == nil {
 		rb.response.Content[contentType].Schema = schema
+	} else if !reflect.DeepEqual(rb.response.Content[contentType].Schema, schema) {
+		rb.builder.warn(rb.location+".content."+contentType, "content type already has a schema, the new body is ignored")
 	}
 
 	if resetNextContentType {
@@ -497,6 +1051,30 @@ func (mtb *MediaTypeBuilder) Schema(f any) {
 	mtb.mediaType.Schema = schema
 }
 
+// OneOf replaces the schema of this media type with one matching exactly one
+// of the types of values, see openapi.OneOf.
+func (mtb *MediaTypeBuilder) OneOf(values ...any) *MediaTypeBuilder {
+	mtb.mediaType.Schema = OneOf(mtb.openAPI.Components.Schemas, values...)
+
+	return mtb
+}
+
+// AnyOf replaces the schema of this media type with one matching at least one
+// of the types of values, see openapi.AnyOf.
+func (mtb *MediaTypeBuilder) AnyOf(values ...any) *MediaTypeBuilder {
+	mtb.mediaType.Schema = AnyOf(mtb.openAPI.Components.Schemas, values...)
+
+	return mtb
+}
+
+// AllOf replaces the schema of this media type with one matching all of the
+// types of values, see openapi.AllOf.
+func (mtb *MediaTypeBuilder) AllOf(values ...any) *MediaTypeBuilder {
+	mtb.mediaType.Schema = AllOf(mtb.openAPI.Components.Schemas, values...)
+
+	return mtb
+}
+
 // Example sets the example for this media type
 func (mtb *MediaTypeBuilder) Example(example string) *MediaTypeBuilder {
 	mtb.mediaType.Example = example
@@ -504,6 +1082,71 @@ func (mtb *MediaTypeBuilder) Example(example string) *MediaTypeBuilder {
 	return mtb
 }
 
+// ContentMediaType sets the media type of the content of a string body, e.g.
+// `image/png` for a base64 encoded image. Panics if the body isn't a string.
+func (mtb *MediaTypeBuilder) ContentMediaType(contentMediaType string) *MediaTypeBuilder {
+	mtb.stringSchema("contentMediaType").ContentMediaType = contentMediaType
+
+	return mtb
+}
+
+// ContentEncoding sets the encoding of the content of a string body, e.g.
+// `base64`. Panics if the body isn't a string.
+func (mtb *MediaTypeBuilder) ContentEncoding(contentEncoding string) *MediaTypeBuilder {
+	mtb.stringSchema("contentEncoding").ContentEncoding = contentEncoding
+
+	return mtb
+}
+
+// Discriminator sets the property which tells the types of a OneOfBodies
+// body apart. mapping maps the values of the property to the types, e.g.
+// `map[string]any{"cat": Cat{}, "dog": Dog{}}`, and may be nil if the values
+// are the schema names. Panics if the body isn't a oneOf or anyOf.
+func (mtb *MediaTypeBuilder) Discriminator(propertyName string, mapping map[string]any) *MediaTypeBuilder {
+	schema := mtb.mediaType.Schema
+	if schema == nil || (schema.OneOf == nil && schema.AnyOf == nil) {
+		panic("discriminator only applies to oneOf or anyOf bodies")
+	}
+
+	schema.Discriminator = newDiscriminator(mtb.openAPI.Components.Schemas, propertyName, mapping)
+
+	return mtb
+}
+
+// newDiscriminator returns a discriminator mapping the values to the
+// references of their types, which must be named structs.
+func newDiscriminator(registry Registry, propertyName string, mapping map[string]any) *Discriminator {
+	if propertyName == "" {
+		panic("discriminator property name must be specified")
+	}
+
+	d := &Discriminator{PropertyName: propertyName}
+	// The schemas are generated in the order of the values, so the registration
+	// order is stable.
+	for _, value := range sortedKeys(mapping) {
+		t := reflect.TypeOf(mapping[value])
+		ref := registry.Schema(t, true, "").Ref
+		if ref == "" {
+			panic("discriminator value " + value + " must map to a named struct, got " + t.String())
+		}
+		if d.Mapping == nil {
+			d.Mapping = map[string]string{}
+		}
+		d.Mapping[value] = ref
+	}
+
+	return d
+}
+
+// stringSchema returns the schema of the body, which must be a string.
+func (mtb *MediaTypeBuilder) stringSchema(keyword string) *Schema {
+	if mtb.mediaType.Schema == nil || mtb.mediaType.Schema.Type != TypeString {
+		panic(keyword + " only applies to string bodies")
+	}
+
+	return mtb.mediaType.Schema
+}
+
 // AddExample adds an example with a name.
 func (mtb *MediaTypeBuilder) AddExample(name string) *ExampleBuilder {
 	example := &Example{}
@@ -518,6 +1161,83 @@ func (mtb *MediaTypeBuilder) AddExample(name string) *ExampleBuilder {
 	}
 }
 
+// Encoding returns an EncodingBuilder for the property of a multipart or form
+// body, e.g. `Encoding("avatar").ContentType("image/png")`.
+func (mtb *MediaTypeBuilder) Encoding(property string) *EncodingBuilder {
+	if mtb.mediaType.Encoding == nil {
+		mtb.mediaType.Encoding = map[string]*Encoding{}
+	}
+
+	encoding := mtb.mediaType.Encoding[property]
+	if encoding == nil {
+		encoding = &Encoding{}
+		mtb.mediaType.Encoding[property] = encoding
+	}
+
+	return &EncodingBuilder{
+		openAPI:  mtb.openAPI,
+		encoding: encoding,
+	}
+}
+
+// EncodingBuilder helps build the encoding of a single body property
+type EncodingBuilder struct {
+	openAPI  *OpenAPI
+	encoding *Encoding
+}
+
+// ContentType sets the content type of the property, e.g. "image/png". Several
+// content types can be given as a comma-separated list.
+func (eb *EncodingBuilder) ContentType(contentType string) *EncodingBuilder {
+	eb.encoding.ContentType = contentType
+
+	return eb
+}
+
+// Header adds a header for the part, such as Content-Disposition. This only
+// applies to multipart bodies.
+func (eb *EncodingBuilder) Header(name string, f any) *ParamBuilder {
+	registry := eb.openAPI.Components.Schemas
+	schema := registry.Schema(reflect.TypeOf(f), true, "")
+
+	header := &Header{
+		Schema: schema,
+	}
+
+	if eb.encoding.Headers == nil {
+		eb.encoding.Headers = map[string]*Header{}
+	}
+	eb.encoding.Headers[name] = header
+
+	return &ParamBuilder{
+		param: header,
+	}
+}
+
+// Style sets how the property is serialized. This only applies to
+// application/x-www-form-urlencoded bodies.
+func (eb *EncodingBuilder) Style(style string) *EncodingBuilder {
+	eb.encoding.Style = style
+
+	return eb
+}
+
+// Explode sets whether array and object properties generate separate
+// parameters. This only applies to application/x-www-form-urlencoded bodies.
+func (eb *EncodingBuilder) Explode(explode bool) *EncodingBuilder {
+	eb.encoding.Explode = &explode
+
+	return eb
+}
+
+// AllowReserved allows reserved characters in the property without percent
+// encoding. This only applies to application/x-www-form-urlencoded bodies.
+func (eb *EncodingBuilder) AllowReserved(allowReserved bool) *EncodingBuilder {
+	eb.encoding.AllowReserved = allowReserved
+
+	return eb
+}
+
 // Header adds a Header.
 func (rb *ResponseBuilder) Header(name string, f any) *ParamBuilder {
 	responseType := reflect.TypeOf(f)
@@ -529,6 +1249,9 @@ func (rb *ResponseBuilder) Header(name string, f any) *ParamBuilder {
 		Schema: schema,
 	}
 
+	if rb.response.Headers == nil {
+		rb.response.Headers = map[string]*Param{}
+	}
 	rb.response.Headers[name] = param
 	return &ParamBuilder{
 		param: param,
@@ -538,6 +1261,9 @@ func (rb *ResponseBuilder) Header(name string, f any) *ParamBuilder {
 // Link adds a link to the response.
 func (rb *ResponseBuilder) Link(name string) *LinkBuilder {
 	link := &Link{}
+	if rb.response.Links == nil {
+		rb.response.Links = map[string]*Link{}
+	}
 	rb.response.Links[name] = link
 
 	return &LinkBuilder{
@@ -573,6 +1299,9 @@ func (lb *LinkBuilder) OperationRef(operationRef string) *LinkBuilder {
 
 // AddParam adds a link param, expression is a OpenAPI runtime expressions (see https://swagger.io/docs/specification/links/)
 func (lb *LinkBuilder) AddParam(name string, expression any) *LinkBuilder {
+	if lb.link.Parameters == nil {
+		lb.link.Parameters = map[string]any{}
+	}
 	lb.link.Parameters[name] = expression
 
 	return lb
@@ -621,10 +1350,24 @@ func (sb *ServerBuilder) Description(description string) *ServerBuilder {
 	return sb
 }
 
+// Environment sets the deployment environment of the server, e.g. "staging",
+// as the `x-environment` extension. See OpenAPI.ForEnvironment.
+func (sb *ServerBuilder) Environment(environment string) *ServerBuilder {
+	if sb.server.Extensions == nil {
+		sb.server.Extensions = map[string]any{}
+	}
+	sb.server.Extensions["x-environment"] = environment
+
+	return sb
+}
+
 // AddVariable adds a variable to the server.
 func (sb *ServerBuilder) AddVariable(name string) *ServerVariableBuilder {
 	variable := &ServerVariable{}
 
+	if sb.server.Variables == nil {
+		sb.server.Variables = map[string]*ServerVariable{}
+	}
 	sb.server.Variables[name] = variable
 
 	return &ServerVariableBuilder{
@@ -679,38 +1422,53 @@ func (ob *OperationBuilder) Callback(event string, op *Operation) *OperationBuil
 
 	op.Responses = make(map[string]*Response)
 
-	switch op.Method {
-	case http.MethodGet:
-		item.Get = op
-	case http.MethodPost:
-		item.Post = op
-	case http.MethodPut:
-		item.Put = op
-	case http.MethodPatch:
-		item.Patch = op
-	case http.MethodDelete:
-		item.Delete = op
-	case http.MethodHead:
-		item.Head = op
-	case http.MethodOptions:
-		item.Options = op
-	case http.MethodTrace:
-		item.Trace = op
-	default:
-		panic("unknown method " + op.Method)
-	}
+	item.SetOperation(op)
 
 	return &OperationBuilder{
 		op:      op,
 		openAPI: ob.openAPI,
+		builder: ob.builder,
+	}
+}
+
+// CallbackEvent returns a CallbackBuilder for adding operations to the callback
+// event, with URLs built from runtime expressions.
+//
+//	ob.CallbackEvent("userCreated").
+//		Operation(http.MethodPost, openapi.Expr.RequestBody("callbackUrl")).
+//		Request().Body(&Event{})
+func (ob *OperationBuilder) CallbackEvent(event string) *CallbackBuilder {
+	if event == "" {
+		panic("event must be specified")
+	}
+
+	return &CallbackBuilder{
+		operation: ob,
+		event:     event,
 	}
 }
 
+// CallbackBuilder helps build the operations of a callback event
+type CallbackBuilder struct {
+	operation *OperationBuilder
+	event     string
+}
+
+// Operation adds an operation to the callback. url is made of literal strings
+// and RuntimeExpression values, see CallbackURL.
+func (cb *CallbackBuilder) Operation(method string, url ...any) *OperationBuilder {
+	return cb.operation.Callback(cb.event, &Operation{
+		Method: method,
+		Path:   CallbackURL(url...),
+	})
+}
+
 // Request returns a RequestBuilder which helps build a request
 func (ob *OperationBuilder) Request() *RequestBuilder {
 	return &RequestBuilder{
 		op:                 ob.op,
 		openAPI:            ob.openAPI,
+		builder:            ob.builder,
 		defaultContentType: "application/json",
 		nextContentType:    "",
 	}
@@ -719,6 +1477,7 @@ func (ob *OperationBuilder) Request() *RequestBuilder {
 // RequestBuilder helps build a Request
 type RequestBuilder struct {
 	openAPI *OpenAPI
+	builder *Builder
 	op      *Operation
 
 	defaultContentType string
@@ -739,6 +1498,14 @@ func (rb *RequestBuilder) ContentType(contentType string) *RequestBuilder {
 	return rb
 }
 
+// NoBody removes the RequestBody, making explicit that the operation takes
+// no body, e.g. when an operation was copied from one which has one.
+func (rb *RequestBuilder) NoBody() *RequestBuilder {
+	rb.op.RequestBody = nil
+
+	return rb
+}
+
 // Body sets the RequestBody
 func (rb *RequestBuilder) Body(f any) *RequestBodyBuilder {
 	responseType := reflect.TypeOf(f)
@@ -746,6 +1513,35 @@ func (rb *RequestBuilder) Body(f any) *RequestBodyBuilder {
 	registry := rb.openAPI.Components.Schemas
 	ref := registry.Schema(responseType, true, "")
 
+	return rb.body(ref)
+}
+
+// OneOfBodies sets a body which is one of several types. See
+// ResponseBuilder.OneOfBodies.
+func (rb *RequestBuilder) OneOfBodies(bodies ...any) *RequestBodyBuilder {
+	if len(bodies) < 2 {
+		panic("OneOfBodies needs at least two bodies")
+	}
+
+	return rb.body(OneOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// AnyOfBodies sets a body which matches at least one of several types.
+func (rb *RequestBuilder) AnyOfBodies(bodies ...any) *RequestBodyBuilder {
+	if len(bodies) < 2 {
+		panic("AnyOfBodies needs at least two bodies")
+	}
+
+	return rb.body(AnyOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// AllOfBodies sets a body which matches all of several types.
+func (rb *RequestBuilder) AllOfBodies(bodies ...any) *RequestBodyBuilder {
+	return rb.body(AllOf(rb.openAPI.Components.Schemas, bodies...))
+}
+
+// body sets the schema of the content type of the next body.
+func (rb *RequestBuilder) body(ref *Schema) *RequestBodyBuilder {
 	var contentType string
 	if rb.nextContentType != "" {
 		contentType = rb.nextContentType
@@ -765,6 +1561,9 @@ func (rb *RequestBuilder) Body(f any) *RequestBodyBuilder {
 			},
 		}
 	} else {
+		if rb.op.RequestBody.Content[contentType] != nil {
+			rb.builder.warn(operationLocation(rb.op.Path, rb.op.Method)+".requestBody.content."+contentType, "content type is overwritten by a new body")
+		}
 		rb.op.RequestBody.Content[contentType] = mediaType
 	}
 
@@ -782,6 +1581,32 @@ func (rb *RequestBuilder) Body(f any) *RequestBodyBuilder {
 	}
 }
 
+// VersionedBody sets the body f under the vendor media type of every version,
+// e.g. `application/vnd.myco.v2+json` for VersionedBody("myco", User{}, "v2"),
+// for APIs versioned through the Content-Type header. See VendorMediaType.
+func (rb *RequestBuilder) VersionedBody(vendor string, f any, versions ...string) *RequestBuilder {
+	if len(versions) == 0 {
+		panic("at least one version must be specified")
+	}
+
+	for _, version := range versions {
+		rb.ContentType(VendorMediaType(vendor, version)).Body(f)
+	}
+
+	return rb
+}
+
+// VendorMediaType returns the media type of a version of a vendor specific
+// JSON format, e.g. `application/vnd.myco.v2+json` for vendor "myco" and
+// version "v2".
+func VendorMediaType(vendor, version string) string {
+	if vendor == "" || version == "" {
+		panic("vendor and version must be specified")
+	}
+
+	return "application/vnd." + vendor + "." + version + "+json"
+}
+
 type RequestBodyBuilder struct {
 	mediaTypeBuilder *MediaTypeBuilder
 	requestBody      *RequestBody
@@ -801,6 +1626,15 @@ func (rbb *RequestBodyBuilder) Required(required bool) *RequestBodyBuilder {
 	return rbb
 }
 
+// AllowOnSafeMethod silences the warning of Builder.Warnings for a body on a
+// GET, HEAD or DELETE operation, for APIs whose clients and gateways are known
+// to send it, e.g. search endpoints with complex filters.
+func (rbb *RequestBodyBuilder) AllowOnSafeMethod() *RequestBodyBuilder {
+	rbb.requestBody.allowOnSafeMethod = true
+
+	return rbb
+}
+
 // Example sets the example for the body
 func (rbb *RequestBodyBuilder) Example(example string) *RequestBodyBuilder {
 	rbb.mediaTypeBuilder.Example(example)
@@ -813,12 +1647,17 @@ func (rbb *RequestBodyBuilder) AddExample(example string) *ExampleBuilder {
 	return rbb.mediaTypeBuilder.AddExample(example)
 }
 
+// Encoding returns an EncodingBuilder for a property of a multipart or form body
+func (rbb *RequestBodyBuilder) Encoding(property string) *EncodingBuilder {
+	return rbb.mediaTypeBuilder.Encoding(property)
+}
+
 // QueryParam adds a query param
 func (rb *RequestBuilder) QueryParam(name string, f any) *ParamBuilder {
 	return rb.Param("query", name, f)
 }
 
-// PathParam adds a path param
+// PathParam adds a path param. Path params are always required.
 func (rb *RequestBuilder) PathParam(name string, f any) *ParamBuilder {
 	return rb.Param("path", name, f)
 }
@@ -836,9 +1675,10 @@ func (rb *RequestBuilder) Param(in string, name string, f any) *ParamBuilder {
 	schema := registry.Schema(paramType, true, "")
 
 	param := &Param{
-		Name:   name,
-		In:     in,
-		Schema: schema,
+		Name:     name,
+		In:       in,
+		Schema:   schema,
+		Required: in == "path",
 	}
 
 	rb.op.Parameters = append(rb.op.Parameters, param)
@@ -873,6 +1713,59 @@ func (pb *ParamBuilder) Required(required bool) *ParamBuilder {
 	return pb
 }
 
+// Deprecated marks the param as deprecated, see Builder.DeprecationNotices.
+func (pb *ParamBuilder) Deprecated() *ParamBuilder {
+	pb.param.Deprecated = true
+	return pb
+}
+
+// Enum sets the possible values of the param, or of its items if it is an
+// array, e.g. `Enum("asc", "desc")`.
+func (pb *ParamBuilder) Enum(values ...any) *ParamBuilder {
+	if len(values) == 0 {
+		panic("enum values must be specified")
+	}
+
+	schema := *pb.param.Schema
+	if schema.Type == TypeArray && schema.Items != nil {
+		// Copy the items as they may be shared with other schemas.
+		items := *schema.Items
+		items.shared = false
+		items.Enum = enumValues(values)
+		items.PrecomputeMessages()
+		schema.Items = &items
+	} else {
+		schema.Enum = enumValues(values)
+	}
+	schema.PrecomputeMessages()
+	pb.param.Schema = &schema
+
+	return pb
+}
+
+// Pattern sets the regular expression the param must match, e.g.
+// `PathParam("id", "").Pattern("^[0-9]+$")`.
+func (pb *ParamBuilder) Pattern(pattern string) *ParamBuilder {
+	// Copy the schema as it may be shared with other schemas.
+	schema := *pb.param.Schema
+	schema.shared = false
+	schema.Pattern = pattern
+	schema.PrecomputeMessages()
+	pb.param.Schema = &schema
+
+	return pb
+}
+
+// Default documents the value the server uses when the param is omitted,
+// e.g. `QueryParam("limit", 0).Default(20)`.
+func (pb *ParamBuilder) Default(value any) *ParamBuilder {
+	schema := *pb.param.Schema
+	schema.Default = jsonValue("default", value)
+	pb.param.Schema = &schema
+
+	return pb
+}
+
 // Example sets the example for the param. If you want to add multiple examples, call AddExample() instead.
 func (pb *ParamBuilder) Example(example string) *ParamBuilder {
 	pb.param.Example = example
@@ -957,7 +1850,316 @@ func (b *Builder) Registry() Registry {
 	return b.openAPI.Components.Schemas
 }
 
+// AutoLinks accepts every link proposed by OpenAPI.SuggestLinks. To
+// pick which links are added, call SuggestLinks and Accept them instead.
+func (b *Builder) AutoLinks() *Builder {
+	for _, suggestion := range b.openAPI.SuggestLinks() {
+		suggestion.Accept()
+	}
+
+	return b
+}
+
 // OpenAPI returns the OpenAPI struct.
 func (b *Builder) OpenAPI() *OpenAPI {
 	return b.openAPI
 }
+
+// TemplateValues sets the values used to resolve template placeholders such as
+// `{{.ProductName}}` in titles, summaries and descriptions when calling Build.
+func (b *Builder) TemplateValues(values map[string]any) *Builder {
+	if b.templateValues == nil {
+		b.templateValues = map[string]any{}
+	}
+
+	for k, v := range values {
+		b.templateValues[k] = v
+	}
+
+	return b
+}
+
+// Redact masks sensitive values in the examples of the document using r when
+// calling Build, e.g. `Redact(openapi.DefaultRedactor)`.
+func (b *Builder) Redact(r *Redactor) *Builder {
+	b.redactor = r
+
+	return b
+}
+
+// FeatureFlags sets the evaluation of the feature flags of operations when
+// calling Build. Operations whose OperationBuilder.FeatureFlag is off are
+// removed from the built document, together with the tags and components
+// only they use, so experimental endpoints are only published where they are
+// enabled. Without it every operation is kept.
+func (b *Builder) FeatureFlags(enabled func(flag string) bool) *Builder {
+	b.featureFlags = enabled
+
+	return b
+}
+
+// OperationIDs generates the IDs of the operations without one when calling
+// Build, as code generators require them, e.g.
+// `OperationIDs(openapi.DefaultOperationID)`. Generated IDs which are already
+// taken get a number appended.
+func (b *Builder) OperationIDs(generate func(method, path string) string) *Builder {
+	b.operationID = generate
+
+	return b
+}
+
+// DefaultOperationID generates an operation ID from the method and the path,
+// e.g. `getUserById` for `GET /users/{id}` and `postUsers` for `POST /users`.
+// Path segments followed by a parameter are naively made singular.
+func DefaultOperationID(method, path string) string {
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+
+	id := strings.ToLower(method)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			id += "By" + camelCase(segment[1:len(segment)-1])
+			continue
+		}
+		if i+1 < len(segments) && strings.HasPrefix(segments[i+1], "{") {
+			segment = singular(segment)
+		}
+		id += camelCase(segment)
+	}
+
+	return id
+}
+
+// camelCase joins the words of s, separated by `-`, `_`, `.` or spaces, with
+// their first letter uppercased, e.g. `user-roles` becomes `UserRoles`.
+func camelCase(s string) string {
+	result := ""
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	}) {
+		r, size := utf8.DecodeRuneInString(word)
+		result += strings.ToUpper(string(r)) + word[size:]
+	}
+	return result
+}
+
+// singular returns the singular of a plural English noun for the common
+// cases, e.g. `users`, `categories` and `addresses`.
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// generateOperationIDs sets the IDs of the operations without one.
+func generateOperationIDs(o *OpenAPI, generate func(method, path string) string) {
+	taken := map[string]bool{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		taken[op.OperationID] = true
+	})
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		if op.OperationID != "" {
+			return
+		}
+
+		id := generate(method, path)
+		for i := 2; taken[id]; i++ {
+			id = generate(method, path) + strconv.Itoa(i)
+		}
+		taken[id] = true
+		op.OperationID = id
+	})
+}
+
+// DeprecationNotices appends a standard deprecation notice to the
+// description of the deprecated operations and params when calling Build, as
+// not every docs renderer highlights the `deprecated` flag. The notice of an
+// operation with a sunset date, see OperationBuilder.Sunset, includes the date
+// and the link to the migration guide.
+func (b *Builder) DeprecationNotices() *Builder {
+	b.deprecation = true
+
+	return b
+}
+
+// addDeprecationNotices appends the deprecation notices to the descriptions
+// of the deprecated operations and params.
+func addDeprecationNotices(o *OpenAPI) {
+	params := func(params []*Param) {
+		for _, p := range params {
+			if p.Deprecated && p.Ref == "" {
+				p.Description = appendNotice(p.Description, "**Deprecated:** this parameter will be removed in a future version.")
+			}
+		}
+	}
+
+	for _, item := range o.Paths {
+		params(item.Parameters)
+	}
+	if o.Components != nil {
+		for _, p := range o.Components.Parameters {
+			params([]*Param{p})
+		}
+	}
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		params(op.Parameters)
+		if !op.Deprecated {
+			return
+		}
+
+		notice := "**Deprecated:** this operation will be removed in a future version."
+		var sunset SunsetPolicy
+		if extensionAs(op.Extensions["x-sunset"], &sunset) {
+			notice = "**Deprecated:** this operation stops working on " + sunset.Date + "."
+			if sunset.Link != "" {
+				notice += " See the [migration guide](" + sunset.Link + ")."
+			}
+		}
+		op.Description = appendNotice(op.Description, notice)
+	})
+}
+
+// appendNotice appends notice as a new paragraph of description.
+func appendNotice(description, notice string) string {
+	if description == "" {
+		return notice
+	}
+	return description + "\n\n" + notice
+}
+
+// SplitReadWrite publishes separate read and write variants of schemas with
+// readOnly or writeOnly properties when calling Build, see
+// OpenAPI.SplitReadWrite.
+func (b *Builder) SplitReadWrite() *Builder {
+	b.splitReadWrite = true
+
+	return b
+}
+
+// MapError sets how the handlers created by Handle turn the errors returned
+// by their function into a status and a JSON body. The default is the
+// MapError function, so return a StatusError for anything but a 500.
+func (b *Builder) MapError(mapError func(err error) (status int, body any)) *Builder {
+	b.mapError = mapError
+
+	return b
+}
+
+// warn records a non-fatal issue, see Warnings.
+func (b *Builder) warn(location, message string) {
+	b.warnings = append(b.warnings, &ErrorDetail{
+		Message:  message,
+		Location: location,
+	})
+}
+
+// Warnings returns non-fatal issues encountered while building, such as
+// tags which look like misspelled schema tags, content types whose body was
+// overwritten, responses without a description and request bodies on GET,
+// HEAD or DELETE operations. Unlike Validate, these don't break the document
+// but are worth logging or failing CI on.
+func (b *Builder) Warnings() []*ErrorDetail {
+	warnings := append([]*ErrorDetail{}, b.warnings...)
+
+	if r, ok := b.openAPI.Components.Schemas.(*mapRegistry); ok {
+		warnings = append(warnings, r.warnings...)
+	}
+
+	b.openAPI.WalkOperations(func(path, method string, op *Operation) {
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			if op.RequestBody != nil && !op.RequestBody.allowOnSafeMethod {
+				warnings = append(warnings, &ErrorDetail{
+					Message:  "request body on a " + method + " operation is rejected by many gateways and clients",
+					Location: operationLocation(path, method) + ".requestBody",
+				})
+			}
+		}
+
+		statuses := make([]string, 0, len(op.Responses))
+		for status := range op.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		for _, status := range statuses {
+			if response := op.Responses[status]; response.Ref == "" && response.Description == "" {
+				warnings = append(warnings, &ErrorDetail{
+					Message:  "response has no description",
+					Location: operationLocation(path, method) + ".responses." + status,
+				})
+			}
+		}
+	})
+
+	return warnings
+}
+
+// Validate checks the document for mistakes which would otherwise silently
+// produce a broken spec, such as security requirements using undefined
+// scopes. Every error is an *ErrorDetail locating the problem.
+func (b *Builder) Validate() []error {
+	errs := validateSpec(b.openAPI)
+	b.openAPI.Hooks.validationFailed(errs)
+	return errs
+}
+
+// Build returns a copy of the OpenAPI which is ready to be published, with
+// disabled feature flagged operations removed, operation IDs generated,
+// deprecation notices added, used tags declared, read and write schemas split,
+// template placeholders resolved, examples redacted and response headers
+// added. Unlike OpenAPI, the builder's document is left untouched so Build may
+// be called repeatedly. An error is returned if the document does not pass
+// Validate.
+func (b *Builder) Build() (*OpenAPI, error) {
+	o := b.openAPI.Clone()
+
+	if b.featureFlags != nil {
+		o = o.Filter(func(path, method string, op *Operation) bool {
+			var flag string
+			return !extensionAs(op.Extensions["x-feature-flag"], &flag) || b.featureFlags(flag)
+		})
+	}
+
+	if b.operationID != nil {
+		generateOperationIDs(o, b.operationID)
+	}
+
+	if b.deprecation {
+		addDeprecationNotices(o)
+	}
+
+	declareTags(o)
+
+	if b.splitReadWrite {
+		o.SplitReadWrite()
+	}
+
+	if b.templateValues != nil {
+		if err := renderTemplates(o, b.templateValues); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.redactor != nil {
+		o.RedactExamples(b.redactor)
+	}
+
+	if b.responseHeaders != nil {
+		addResponseHeaders(o, b.responseHeaders)
+	}
+
+	if errs := validateSpec(o); len(errs) > 0 {
+		b.openAPI.Hooks.validationFailed(errs)
+		return nil, errorList(errs)
+	}
+
+	return o, nil
+}
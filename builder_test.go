@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/restk/openapi"
 )
@@ -190,3 +191,132 @@ func TestBuilder(t *testing.T) {
 	})
 
 }
+
+func TestTagBuilder(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Tag("users").Description("Manage users")
+	builder.Tag("orders").ExternalDocs("https://example.com/orders", "Orders guide")
+	builder.Tag("admin").Order(0)
+	builder.Tag("users").Description("Manage users and their roles")
+
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Tag("users").Tag("beta")
+
+	tags := builder.OpenAPI().Tags
+	if len(tags) != 3 || tags[0].Name != "admin" || tags[1].Name != "users" || tags[2].Name != "orders" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	if tags[1].Description != "Manage users and their roles" || tags[2].ExternalDocs.URL != "https://example.com/orders" {
+		t.Errorf("unexpected tag metadata %v %v", tags[1], tags[2])
+	}
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Tags) != 4 || o.Tags[3].Name != "beta" {
+		t.Errorf("expected the undeclared beta tag to be declared, got %v", o.Tags)
+	}
+	if len(builder.OpenAPI().Tags) != 3 {
+		t.Errorf("expected the builder's document to be left untouched")
+	}
+}
+
+func TestOperationIDs(t *testing.T) {
+	builder := openapi.New("title", "version").OperationIDs(openapi.DefaultOperationID)
+	builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/users/{id}"}).
+		Request().PathParam("id", openapi.IntType).Required(true)
+	builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/categories/{category-id}/entries"}).
+		Request().PathParam("category-id", openapi.IntType).Required(true)
+	builder.Register(&openapi.Operation{Method: http.MethodPost, Path: "/users"}).OperationID("createUser")
+	builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/users"})
+	builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/users/"})
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]string{
+		"/users/{id}":                       "getUserById",
+		"/categories/{category-id}/entries": "getCategoryByCategoryIdEntries",
+		"/users":                            "getUsers",
+		"/users/":                           "getUsers2",
+	} {
+		if id := o.Paths[path].Get.OperationID; id != expected {
+			t.Errorf("expected %s for %s, got %s", expected, path, id)
+		}
+	}
+	if id := o.Paths["/users"].Post.OperationID; id != "createUser" {
+		t.Errorf("expected the explicit operation ID to be kept, got %s", id)
+	}
+	if builder.OpenAPI().Paths["/users/{id}"].Get.OperationID != "" {
+		t.Errorf("expected the builder's document to be left untouched")
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	builder := openapi.New("title", "version").DeprecationNotices()
+	listUsers := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Description("List users").Deprecated()
+	listUsers.Request().QueryParam("sort", "").Deprecated()
+	builder.Register(&openapi.Operation{
+		OperationID: "listOrders",
+		Method:      http.MethodGet,
+		Path:        "/orders",
+	}).Sunset(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), "https://example.com/migrate")
+
+	op := builder.OpenAPI().Paths["/users"].Get
+	if !op.Deprecated || !op.Parameters[0].Deprecated || op.Description != "List users" {
+		t.Errorf("expected the operation and param to be deprecated, got %v %v", op, op.Parameters[0])
+	}
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := o.Paths["/users"].Get; op.Description != "List users\n\n**Deprecated:** this operation will be removed in a future version." {
+		t.Errorf("unexpected operation description %q", op.Description)
+	}
+	if p := o.Paths["/users"].Get.Parameters[0]; p.Description != "**Deprecated:** this parameter will be removed in a future version." {
+		t.Errorf("unexpected param description %q", p.Description)
+	}
+	if op := o.Paths["/orders"].Get; op.Description != "**Deprecated:** this operation stops working on 2025-06-30. See the [migration guide](https://example.com/migrate)." {
+		t.Errorf("unexpected sunset description %q", op.Description)
+	}
+}
+
+func TestExternalDocs(t *testing.T) {
+	builder := openapi.New("title", "version").
+		ExternalDocs("https://example.com/docs", "Developer guide")
+	builder.Tag("users").ExternalDocs("https://example.com/docs/users", "")
+	builder.Register(&openapi.Operation{
+		OperationID: "importUsers",
+		Method:      http.MethodPost,
+		Path:        "/users/import",
+	}).ExternalDocs("https://example.com/docs/import", "Importing users")
+
+	o := builder.OpenAPI()
+	if o.ExternalDocs.URL != "https://example.com/docs" || o.ExternalDocs.Description != "Developer guide" {
+		t.Errorf("unexpected root external docs %v", o.ExternalDocs)
+	}
+	if o.Tags[0].ExternalDocs.URL != "https://example.com/docs/users" {
+		t.Errorf("unexpected tag external docs %v", o.Tags[0].ExternalDocs)
+	}
+	if docs := o.Paths["/users/import"].Post.ExternalDocs; docs.URL != "https://example.com/docs/import" || docs.Description != "Importing users" {
+		t.Errorf("unexpected operation external docs %v", docs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a missing url")
+		}
+	}()
+	builder.ExternalDocs("", "Developer guide")
+}
@@ -0,0 +1,336 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxCaptureBody is the largest request or response body which is recorded.
+const maxCaptureBody = 1 << 20
+
+// maxUndocumentedRoutes is the number of undocumented routes recorded while
+// Infer is enabled.
+const maxUndocumentedRoutes = 100
+
+// Sample is a request/response pair recorded by a Recorder. Bodies are only
+// kept when they are JSON, and headers are never recorded.
+type Sample struct {
+	Method     string
+	Path       string
+	Query      url.Values
+	PathParams map[string]string
+	Status     int
+
+	RequestContentType  string
+	RequestBody         any
+	ResponseContentType string
+	ResponseBody        any
+}
+
+// Recorder is a middleware which records real traffic for each operation of
+// a document so it can be merged back into the spec as examples.
+//
+//	recorder := openapi.NewRecorder(openAPI, 3)
+//	http.ListenAndServe(":8080", recorder.Middleware(mux))
+//	...
+//	recorder.MergeExamples()
+type Recorder struct {
+	// Redactor masks sensitive values in the recorded bodies and query
+	// parameters. It defaults to DefaultRedactor, set it to nil to disable.
+	Redactor *Redactor
+
+	// Sanitize is called for every sample after redaction, before it is
+	// stored, and may modify it. Returning false drops the sample.
+	Sanitize func(s *Sample) bool
+
+	// Infer enables recording requests to routes which are not in the
+	// document, so a draft spec can be inferred from them with Draft. At most
+	// 100 routes are recorded, and responses with a 404 or 405 status are
+	// ignored so scanners don't fill them up.
+	Infer bool
+
+	openAPI *OpenAPI
+	matcher *Matcher
+	max     int
+
+	mu           sync.Mutex
+	samples      map[*Operation][]*Sample
+	seen         map[*Operation]map[string]bool
+	undocumented map[string]*Operation
+}
+
+// NewRecorder returns a Recorder for the operations of the OpenAPI, keeping
+// at most maxPerOperation distinct samples for each operation.
+func NewRecorder(o *OpenAPI, maxPerOperation int) *Recorder {
+	return &Recorder{
+		Redactor: DefaultRedactor,
+
+		openAPI:      o,
+		matcher:      NewMatcher(o),
+		max:          maxPerOperation,
+		samples:      map[*Operation][]*Sample{},
+		seen:         map[*Operation]map[string]bool{},
+		undocumented: map[string]*Operation{},
+	}
+}
+
+// Middleware records the requests handled by next.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, params := rec.matcher.Match(r.Method, r.URL.Path)
+		undocumented := ""
+		if op == nil && rec.Infer {
+			op, params, undocumented = rec.undocumentedOperation(r.Method, r.URL.Path)
+		}
+		if op == nil || rec.full(op) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var reqBody []byte
+		if r.Body != nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(r.Body, maxCaptureBody+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+		}
+
+		cw := &captureWriter{statusWriter: statusWriter{ResponseWriter: w, status: http.StatusOK}}
+		next.ServeHTTP(cw, r)
+
+		s := &Sample{
+			Method:              r.Method,
+			Path:                r.URL.Path,
+			Query:               r.URL.Query(),
+			PathParams:          params,
+			Status:              cw.status,
+			RequestContentType:  mediaType(r.Header.Get("Content-Type")),
+			ResponseContentType: mediaType(cw.Header().Get("Content-Type")),
+		}
+		s.RequestBody = decodeJSONBody(s.RequestContentType, reqBody)
+		if !cw.truncated {
+			s.ResponseBody = decodeJSONBody(s.ResponseContentType, cw.body.Bytes())
+		}
+
+		if undocumented != "" {
+			if cw.status == http.StatusNotFound || cw.status == http.StatusMethodNotAllowed {
+				return
+			}
+			if op = rec.addUndocumented(undocumented, op); op == nil {
+				return
+			}
+		}
+
+		rec.record(op, s)
+	})
+}
+
+func (rec *Recorder) full(op *Operation) bool {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.samples[op]) >= rec.max
+}
+
+func (rec *Recorder) record(op *Operation, s *Sample) {
+	if rec.Redactor != nil {
+		rec.redact(op, s)
+	}
+
+	if rec.Sanitize != nil && !rec.Sanitize(s) {
+		return
+	}
+
+	key, err := json.Marshal([]any{s.Status, s.RequestBody, s.ResponseBody})
+	if err != nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if len(rec.samples[op]) >= rec.max || rec.seen[op][string(key)] {
+		return
+	}
+	if rec.seen[op] == nil {
+		rec.seen[op] = map[string]bool{}
+	}
+	rec.seen[op][string(key)] = true
+	rec.samples[op] = append(rec.samples[op], s)
+}
+
+// redact masks the sensitive values of the sample, using the schemas of the
+// operation where they are documented.
+func (rec *Recorder) redact(op *Operation, s *Sample) {
+	redactSample(rec.openAPI, rec.Redactor, op, s, false)
+}
+
+// redactSample masks the sensitive values of the sample, using the schemas of
+// the operation, which may be nil, where they are documented. The `writeOnly`
+// properties are masked too if writeOnly is true.
+func redactSample(o *OpenAPI, r *Redactor, op *Operation, s *Sample, writeOnly bool) {
+	var registry Registry
+	if o.Components != nil {
+		registry = o.Components.Schemas
+	}
+
+	for name, values := range s.Query {
+		if r.sensitiveName(name) {
+			for i := range values {
+				values[i] = r.Mask
+			}
+		}
+	}
+
+	if s.RequestBody != nil {
+		var schema *Schema
+		if op != nil && op.RequestBody != nil && op.RequestBody.Content[s.RequestContentType] != nil {
+			schema = op.RequestBody.Content[s.RequestContentType].Schema
+		}
+		s.RequestBody = r.redact(registry, schema, s.RequestBody, writeOnly)
+	}
+
+	if s.ResponseBody != nil {
+		var schema *Schema
+		if op != nil {
+			if resp := op.Responses[documentedResponse(op, s.Status)]; resp != nil && resp.Content[s.ResponseContentType] != nil {
+				schema = resp.Content[s.ResponseContentType].Schema
+			}
+		}
+		s.ResponseBody = r.redact(registry, schema, s.ResponseBody, writeOnly)
+	}
+}
+
+// Samples returns the samples recorded for the operation.
+func (rec *Recorder) Samples(op *Operation) []*Sample {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]*Sample{}, rec.samples[op]...)
+}
+
+// MergeExamples adds the recorded bodies to the document as named examples,
+// `captured-1`, `captured-2`, etc. Examples are only added to request bodies
+// and responses whose status and content type are already documented.
+// Existing examples with the same name are replaced.
+//
+// The document is modified in place, so this must not be called while the
+// document is being served.
+func (rec *Recorder) MergeExamples() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.openAPI.WalkOperations(func(path, method string, op *Operation) {
+		var requests, responses int
+		for _, s := range rec.samples[op] {
+			if s.RequestBody != nil && op.RequestBody != nil {
+				if mt := op.RequestBody.Content[s.RequestContentType]; mt != nil {
+					requests++
+					addCapturedExample(mt, requests, "request", s, s.RequestBody)
+				}
+			}
+
+			if s.ResponseBody != nil {
+				if resp := op.Responses[documentedResponse(op, s.Status)]; resp != nil {
+					if mt := resp.Content[s.ResponseContentType]; mt != nil {
+						responses++
+						addCapturedExample(mt, responses, "response", s, s.ResponseBody)
+					}
+				}
+			}
+		}
+	})
+	rec.openAPI.Invalidate()
+}
+
+func addCapturedExample(mt *MediaType, n int, kind string, s *Sample, value any) {
+	if mt.Examples == nil {
+		mt.Examples = map[string]*Example{}
+	}
+	mt.Examples["captured-"+strconv.Itoa(n)] = &Example{
+		Summary: fmt.Sprintf("Captured %s to %s %s", kind, s.Method, s.Path),
+		Value:   value,
+	}
+}
+
+// statusWriter records the status of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// captureWriter copies the response body while writing it.
+type captureWriter struct {
+	statusWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) > maxCaptureBody {
+		w.truncated = true
+	} else if !w.truncated {
+		w.body.Write(b)
+	}
+	return w.statusWriter.Write(b)
+}
+
+// mediaType returns the media type of a Content-Type header without its
+// parameters.
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
+
+// decodeJSONBody decodes body if the content type is JSON, or returns nil.
+func decodeJSONBody(contentType string, body []byte) any {
+	if len(body) == 0 || len(body) > maxCaptureBody || !isJSON(contentType) {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// isJSON reports whether the media type is JSON, including `+json` suffixes.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,150 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type CaptureUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestRecorderMergeExamples(t *testing.T) {
+	builder := openapi.New("title", "version")
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	createUser.Request().Body(CaptureUser{})
+	createUser.Response(http.StatusCreated).Body(CaptureUser{})
+
+	recorder := openapi.NewRecorder(builder.OpenAPI(), 2)
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user CaptureUser
+		json.NewDecoder(r.Body).Decode(&user)
+		user.ID = 1
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	}))
+
+	for _, name := range []string{"alice", "alice", "bob", "carol"} {
+		r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"`+name+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	op := builder.OpenAPI().Paths["/users"].Post
+	if samples := recorder.Samples(op); len(samples) != 2 {
+		t.Fatalf("expected 2 distinct samples, got %d", len(samples))
+	}
+
+	recorder.MergeExamples()
+
+	requestExamples := op.RequestBody.Content["application/json"].Examples
+	if len(requestExamples) != 2 || requestExamples["captured-2"].Value.(map[string]any)["name"] != "bob" {
+		t.Errorf("unexpected request examples %v", requestExamples)
+	}
+
+	responseExamples := op.Responses["201"].Content["application/json"].Examples
+	if len(responseExamples) != 2 || responseExamples["captured-1"].Value.(map[string]any)["id"] != float64(1) {
+		t.Errorf("unexpected response examples %v", responseExamples)
+	}
+}
+
+func TestRecorderDraft(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	recorder := openapi.NewRecorder(builder.OpenAPI(), 10)
+	recorder.Infer = true
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/2") {
+			w.Write([]byte(`{"id": 2, "name": "bob", "email": null}`))
+			return
+		}
+		w.Write([]byte(`{"id": 1, "name": "alice", "email": "alice@example.com", "createdAt": "2024-01-01T00:00:00Z"}`))
+	}))
+
+	for _, target := range []string{"/users/1?verbose=true", "/users/2"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	draft := recorder.Draft("draft", "0.0.1")
+
+	item := draft.Paths["/users/{userId}"]
+	if item == nil || item.Get == nil {
+		t.Fatalf("expected GET /users/{userId}, got %v", draft.Paths)
+	}
+	op := item.Get
+	if op.OperationID != "get-users-by-userId" || len(op.Parameters) != 2 {
+		t.Fatalf("unexpected operation %v", op)
+	}
+	if p := op.Parameters[0]; p.In != "path" || p.Schema.Type != openapi.TypeInteger {
+		t.Errorf("unexpected path param %v", p)
+	}
+	if p := op.Parameters[1]; p.In != "query" || p.Schema.Type != openapi.TypeBoolean {
+		t.Errorf("unexpected query param %v", p)
+	}
+
+	s := op.Responses["200"].Content["application/json"].Schema
+	if strings.Join(s.Required, ",") != "email,id,name" {
+		t.Errorf("unexpected required %v", s.Required)
+	}
+	if email := s.Properties["email"]; email.Type != openapi.TypeString || !email.Nullable {
+		t.Errorf("unexpected email schema %v", email)
+	}
+	if createdAt := s.Properties["createdAt"]; createdAt.Format != "date-time" {
+		t.Errorf("unexpected createdAt schema %v", createdAt)
+	}
+}
+
+func TestRecorderDraftLimits(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	recorder := openapi.NewRecorder(builder.OpenAPI(), 10)
+	recorder.Infer = true
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/wp-") {
+			http.NotFound(w, r)
+			return
+		}
+		if flag := r.URL.Query().Get("flag"); flag != "" {
+			// Distinct bodies, so both samples are kept.
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"flag":%q}`, flag)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(target string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	serve("/wp-admin")
+	serve("/items?flag=2")
+	serve("/items?flag=true")
+	for i := 0; i < 150; i++ {
+		serve(fmt.Sprintf("/posts/title-%d", i))
+	}
+
+	draft := recorder.Draft("draft", "0.0.1")
+	if draft.Paths["/wp-admin"] != nil {
+		t.Errorf("expected not found responses to be ignored")
+	}
+	if len(draft.Paths) != 100 {
+		t.Errorf("expected the undocumented routes to be capped, got %d", len(draft.Paths))
+	}
+	if p := draft.Paths["/items"].Get.Parameters[0]; p.Schema.Type != openapi.TypeString {
+		t.Errorf("expected mixed values to be a string, got %v", p.Schema.Type)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var pathTemplateRe = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// specChecks are run by Builder.Validate and Builder.Build, in order.
+var specChecks = []func(o *OpenAPI) []error{
+	checkSecurity,
+	checkPathParams,
+}
+
+func validateSpec(o *OpenAPI) []error {
+	var errs []error
+	for _, check := range specChecks {
+		errs = append(errs, check(o)...)
+	}
+	return errs
+}
+
+// operationLocation returns the location of an operation for error details,
+// e.g. `paths./users.get`.
+func operationLocation(path, method string) string {
+	return "paths." + path + "." + strings.ToLower(method)
+}
+
+// checkSecurity reports security requirements referencing schemes which are
+// not registered, or OAuth2 scopes which no flow of the scheme declares.
+func checkSecurity(o *OpenAPI) []error {
+	var errs []error
+
+	checkRequirements := func(location string, security []map[string][]string) {
+		for _, requirement := range security {
+			names := make([]string, 0, len(requirement))
+			for name := range requirement {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				var schemes map[string]*SecurityScheme
+				if o.Components != nil {
+					schemes = o.Components.SecuritySchemes
+				}
+
+				scheme := schemes[name]
+				if scheme == nil {
+					msg := "security scheme is not defined in components.securitySchemes"
+					candidates := make([]string, 0, len(schemes))
+					for candidate := range schemes {
+						candidates = append(candidates, candidate)
+					}
+					if suggestion := closestMatch(name, candidates); suggestion != "" {
+						msg += fmt.Sprintf(", did you mean %q?", suggestion)
+					}
+					errs = append(errs, &ErrorDetail{
+						Message:  msg,
+						Location: location,
+						Value:    name,
+					})
+					continue
+				}
+				if scheme.Type != "oauth2" {
+					continue
+				}
+
+				declared := scheme.Flows.scopes()
+				for _, scope := range requirement[name] {
+					if !declared[scope] {
+						errs = append(errs, &ErrorDetail{
+							Message:  fmt.Sprintf("scope is not defined by any flow of security scheme %q", name),
+							Location: location,
+							Value:    scope,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	checkRequirements("security", o.Security)
+	o.WalkOperations(func(path, method string, op *Operation) {
+		checkRequirements(operationLocation(path, method)+".security", op.Security)
+	})
+
+	return errs
+}
+
+// checkPathParams reports `{param}` templates without a matching required
+// `in: path` parameter, and path parameters missing from the template.
+func checkPathParams(o *OpenAPI) []error {
+	var errs []error
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		location := operationLocation(path, method)
+
+		templated := map[string]bool{}
+		for _, match := range pathTemplateRe.FindAllStringSubmatch(path, -1) {
+			templated[match[1]] = true
+		}
+
+		declared := map[string]*Param{}
+		var names []string
+		for _, p := range o.operationParams(path, op) {
+			if p.In == "path" {
+				declared[p.Name] = p
+				names = append(names, p.Name)
+			}
+		}
+
+		for _, match := range pathTemplateRe.FindAllStringSubmatch(path, -1) {
+			if declared[match[1]] == nil {
+				errs = append(errs, &ErrorDetail{
+					Message:  "path template has no matching path parameter",
+					Location: location,
+					Value:    match[1],
+				})
+			}
+		}
+
+		for _, name := range names {
+			if !templated[name] {
+				errs = append(errs, &ErrorDetail{
+					Message:  "path parameter does not appear in the path template",
+					Location: location + ".parameters",
+					Value:    name,
+				})
+			} else if !declared[name].Required {
+				errs = append(errs, &ErrorDetail{
+					Message:  "path parameter must be required",
+					Location: location + ".parameters",
+					Value:    name,
+				})
+			}
+		}
+	})
+
+	return errs
+}
+
+// closestMatch returns the candidate nearest to name, ignoring case, or an
+// empty string if none is close enough to be a likely typo.
+func closestMatch(name string, candidates []string) string {
+	sort.Strings(candidates)
+
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
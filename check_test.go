@@ -0,0 +1,188 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestValidateUndefinedScope(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.OAuth2().ClientCredentials().TokenURL("https://example.com/token").Scopes(map[string]string{
+		"read_users": "Read users",
+	})
+	builder.Security("OAuth2", []string{"read_users"})
+	builder.Register(&openapi.Operation{
+		OperationID: "deleteUser",
+		Method:      http.MethodDelete,
+		Path:        "/users",
+	}).Security("OAuth2", []string{"delete_users"})
+
+	errs := builder.Validate()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	detail := errs[0].(*openapi.ErrorDetail)
+	if detail.Location != "paths./users.delete.security" || detail.Value != "delete_users" {
+		t.Errorf("unexpected error %v", detail)
+	}
+
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("expected Build to fail")
+	}
+}
+
+func TestValidateUndefinedScheme(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.BearerAuth()
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Security("bearerauth", nil)
+
+	errs := builder.Validate()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	detail := errs[0].(*openapi.ErrorDetail)
+	if detail.Value != "bearerauth" || !strings.Contains(detail.Message, `did you mean "BearerAuth"?`) {
+		t.Errorf("unexpected error %v", detail)
+	}
+}
+
+func TestOpenIDScheme(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.ApiKeyAuth("X-API-Key").OpenID("https://example.com/.well-known/openid-configuration")
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Security("OpenID", nil)
+
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	schemes := builder.OpenAPI().Components.SecuritySchemes
+	if schemes["OpenID"].Type != "openIdConnect" || schemes["ApiKeyAuth"].Type != "apiKey" {
+		t.Errorf("unexpected schemes %v", schemes)
+	}
+}
+
+func TestValidatePathParams(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/orgs/{orgId}/users/{userId}",
+	})
+	getUser.Request().PathParam("userId", openapi.IntType)
+	getUser.Request().PathParam("id", openapi.IntType)
+	getUser.Request().Param("path", "userId", openapi.IntType).Required(false)
+
+	errs := builder.Validate()
+
+	var values []any
+	for _, err := range errs {
+		values = append(values, err.(*openapi.ErrorDetail).Value)
+	}
+	if len(errs) != 3 || values[0] != "orgId" || values[1] != "userId" || values[2] != "id" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestStandardErrors(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.StandardError(http.StatusNotFound, openapi.ErrorDetail{}).
+		StandardError(http.StatusInternalServerError, nil)
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	getUser.Response(http.StatusInternalServerError).Description("Custom")
+	getUser.StandardErrors()
+
+	o := builder.OpenAPI()
+	responses := o.Paths["/users"].Get.Responses
+	if responses["404"].Ref != "#/components/responses/NotFound" || responses["500"].Description != "Custom" {
+		t.Errorf("unexpected responses %v", responses)
+	}
+	if o.Components.Responses["NotFound"].Content["application/json"].Schema.Ref != "#/components/schemas/ErrorDetail" {
+		t.Errorf("unexpected NotFound component %v", o.Components.Responses["NotFound"])
+	}
+
+	defaults := openapi.New("title", "version")
+	defaults.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).StandardErrors()
+	if responses := defaults.OpenAPI().Paths["/users"].Get.Responses; len(responses) != 6 || responses["429"].Ref != "#/components/responses/TooManyRequests" {
+		t.Errorf("unexpected default responses %v", responses)
+	}
+}
+
+type WarnedUser struct {
+	Name string `json:"name" exmaple:"Alice"`
+}
+
+func TestWarnings(t *testing.T) {
+	builder := openapi.New("title", "version")
+	op := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	op.Request().Body(WarnedUser{})
+	op.Request().Body(openapi.StringType)
+	op.Response(http.StatusCreated).Description("Created").Body(WarnedUser{})
+	op.Response(http.StatusOK).Body(WarnedUser{})
+
+	search := builder.Register(&openapi.Operation{
+		OperationID: "searchUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	search.Request().Body(openapi.StringType)
+	search.Response(http.StatusOK).Description("OK")
+
+	remove := builder.Register(&openapi.Operation{
+		OperationID: "deleteUsers",
+		Method:      http.MethodDelete,
+		Path:        "/users",
+	})
+	remove.Request().Body(openapi.StringType)
+	remove.Request().NoBody()
+	remove.Response(http.StatusNoContent).Description("Deleted")
+
+	query := builder.Register(&openapi.Operation{
+		OperationID: "queryUsers",
+		Method:      http.MethodGet,
+		Path:        "/users/query",
+	})
+	query.Request().Body(openapi.StringType).AllowOnSafeMethod()
+	query.Response(http.StatusOK).Description("OK")
+
+	warnings := builder.Warnings()
+	expected := []string{
+		"paths./users.post.requestBody.content.application/json",
+		"WarnedUser.Name",
+		"paths./users.get.requestBody",
+		"paths./users.post.responses.200",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, location := range expected {
+		if warnings[i].Location != location {
+			t.Errorf("expected warning at %s, got %v", location, warnings[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "sort"
+
+// Data classifications for the `classification` struct tag, which is emitted
+// as the `x-data-classification` extension.
+const (
+	ClassificationPII    = "pii"
+	ClassificationSecret = "secret"
+	ClassificationPublic = "public"
+)
+
+// DataExposure is a field with a data classification which is sent to or
+// returned by an operation.
+type DataExposure struct {
+	Path        string
+	Method      string
+	OperationID string
+
+	// Location of the field, e.g. `request.body.email` or
+	// `response.200.body.items[].email`.
+	Location string
+
+	Classification string
+}
+
+// DataExposures lists every field with the given classification which is
+// sent to or returned by an operation, e.g. to build an inventory of the
+// operations exposing PII. Fields are found by following the request and
+// response body schemas, including references.
+func (o *OpenAPI) DataExposures(classification string) []*DataExposure {
+	var registry Registry
+	if o.Components != nil {
+		registry = o.Components.Schemas
+	}
+
+	var exposures []*DataExposure
+	o.WalkOperations(func(path, method string, op *Operation) {
+		add := func(location string) {
+			exposures = append(exposures, &DataExposure{
+				Path:           path,
+				Method:         method,
+				OperationID:    op.OperationID,
+				Location:       location,
+				Classification: classification,
+			})
+		}
+
+		if op.RequestBody != nil {
+			for _, mt := range sortedContent(op.RequestBody.Content) {
+				findClassified(registry, mt.Schema, classification, "request.body", map[*Schema]bool{}, add)
+			}
+		}
+
+		statuses := make([]string, 0, len(op.Responses))
+		for status := range op.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			for _, mt := range sortedContent(op.Responses[status].Content) {
+				findClassified(registry, mt.Schema, classification, "response."+status+".body", map[*Schema]bool{}, add)
+			}
+		}
+	})
+
+	// The same field may be found in several content types.
+	deduped := exposures[:0]
+	seen := map[string]bool{}
+	for _, e := range exposures {
+		key := e.Method + " " + e.Path + " " + e.Location
+		if !seen[key] {
+			seen[key] = true
+			deduped = append(deduped, e)
+		}
+	}
+
+	return deduped
+}
+
+func findClassified(registry Registry, s *Schema, classification, location string, visiting map[*Schema]bool, add func(location string)) {
+	if s == nil {
+		return
+	}
+	if s.Extensions["x-data-classification"] == classification {
+		add(location)
+	}
+	if s.Ref != "" && registry != nil {
+		s = registry.SchemaFromRef(s.Ref)
+		if s == nil {
+			return
+		}
+	}
+
+	if visiting[s] {
+		return
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		findClassified(registry, s.Properties[name], classification, location+"."+name, visiting, add)
+	}
+
+	findClassified(registry, s.Items, classification, location+"[]", visiting, add)
+
+	for _, subs := range [][]*Schema{s.OneOf, s.AnyOf, s.AllOf} {
+		for _, sub := range subs {
+			findClassified(registry, sub, classification, location, visiting, add)
+		}
+	}
+}
+
+// sortedContent returns the media types of content sorted by content type.
+func sortedContent(content map[string]*MediaType) []*MediaType {
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	mediaTypes := make([]*MediaType, 0, len(content))
+	for _, contentType := range contentTypes {
+		mediaTypes = append(mediaTypes, content[contentType])
+	}
+	return mediaTypes
+}
@@ -0,0 +1,59 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type ClassifiedAddress struct {
+	Street string `json:"street" classification:"pii"`
+}
+
+type ClassifiedUser struct {
+	ID        int                 `json:"id" classification:"public"`
+	Email     string              `json:"email" classification:"pii"`
+	Addresses []ClassifiedAddress `json:"addresses"`
+	APIKey    string              `json:"apiKey" classification:"secret"`
+}
+
+func TestDataExposures(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{userId}",
+	})
+	getUser.Request().PathParam("userId", openapi.IntType)
+	getUser.Response(http.StatusOK).Body(ClassifiedUser{})
+
+	builder.Register(&openapi.Operation{
+		OperationID: "health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+	}).Response(http.StatusOK).Body(openapi.StringType)
+
+	exposures := builder.OpenAPI().DataExposures(openapi.ClassificationPII)
+
+	var locations []string
+	for _, e := range exposures {
+		if e.OperationID != "getUser" {
+			t.Errorf("unexpected operation %s", e.OperationID)
+		}
+		locations = append(locations, e.Location)
+	}
+	if len(locations) != 2 || locations[0] != "response.200.body.addresses[].street" || locations[1] != "response.200.body.email" {
+		t.Errorf("unexpected locations %v", locations)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for an invalid classification")
+		}
+	}()
+	builder.Registry().Schema(reflect.TypeOf(struct {
+		Name string `json:"name" classification:"private"`
+	}{}), false, "Invalid")
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "reflect"
+
+var pkgPath = reflect.TypeOf(OpenAPI{}).PkgPath()
+
+// Clone returns a deep copy of the OpenAPI, including the schemas in the
+// registry, so the copy can be modified without affecting the original.
+// Pointers which are shared within the document remain shared in the copy.
+// Values from other packages, e.g. examples, are not copied.
+func (o *OpenAPI) Clone() *OpenAPI {
+	c := &cloner{seen: map[cloneKey]reflect.Value{}}
+	copied := c.clone(reflect.ValueOf(o)).Interface().(*OpenAPI)
+	copied.pathOrder = append([]string(nil), o.pathOrder...)
+	copied.building = false
+	copied.Invalidate()
+	return copied
+}
+
+type cloneKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+type cloner struct {
+	seen map[cloneKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Struct && elem.PkgPath() != pkgPath {
+			return v
+		}
+
+		key := cloneKey{v.Type(), v.Pointer()}
+		if n, ok := c.seen[key]; ok {
+			return n
+		}
+
+		n := reflect.New(elem)
+		c.seen[key] = n
+		n.Elem().Set(c.clone(v.Elem()))
+
+		if r, ok := n.Interface().(*mapRegistry); ok {
+			orig := v.Interface().(*mapRegistry)
+			r.schemas = c.clone(reflect.ValueOf(r.schemas)).Interface().(map[string]*Schema)
+			r.types = map[string]reflect.Type{}
+			for k, t := range orig.types {
+				r.types[k] = t
+			}
+			r.seen = map[reflect.Type]bool{}
+			for t := range orig.seen {
+				r.seen[t] = true
+			}
+			r.aliases = map[reflect.Type]reflect.Type{}
+			for t, alias := range orig.aliases {
+				r.aliases[t] = alias
+			}
+			r.order = append([]string(nil), orig.order...)
+			r.elems = c.clone(reflect.ValueOf(r.elems)).Interface().(map[reflect.Kind]*Schema)
+		}
+		if op, ok := n.Interface().(*Operation); ok {
+			op.responseOrder = append([]string(nil), op.responseOrder...)
+		}
+		if components, ok := n.Interface().(*Components); ok {
+			components.securitySchemeOrder = append([]string(nil), components.securitySchemeOrder...)
+		}
+
+		return n
+	case reflect.Struct:
+		if v.Type().PkgPath() != pkgPath {
+			return v
+		}
+
+		// Copy unexported fields (e.g. precomputed messages) as is, then
+		// replace the exported ones with their copies.
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if n.Field(i).CanSet() {
+				n.Field(i).Set(c.clone(v.Field(i)))
+			}
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		n := reflect.New(v.Type()).Elem()
+		n.Set(c.clone(v.Elem()))
+		return n
+	}
+
+	return v
+}
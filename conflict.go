@@ -0,0 +1,76 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "reflect"
+
+// Conflicts compares the current and the requested value of the schema s and
+// returns an ErrorDetail for every field which differs, to explain a 409
+// Conflict or 412 Precondition Failed response in the documented error
+// format, e.g.
+//
+//	return nil, &openapi.StatusError{
+//		Status:  http.StatusConflict,
+//		Message: "user was modified by someone else",
+//		Details: openapi.Conflicts(registry, schema, current, in.Body),
+//	}
+//
+// Values are compared as they are marshaled to JSON. Locations start with
+// `body`, e.g. `body.address.city`, and the values of the details are the
+// current ones. Write-only properties are never reported so secrets don't
+// leak through the details.
+func Conflicts(r Registry, s *Schema, current, requested any) []*ErrorDetail {
+	var details []*ErrorDetail
+	conflicts(r, s, Loc.Body(), jsonValue("current", current), jsonValue("requested", requested), &details)
+	return details
+}
+
+func conflicts(r Registry, s *Schema, loc Location, current, requested any, details *[]*ErrorDetail) {
+	for s != nil && s.Ref != "" {
+		s = r.SchemaFromRef(s.Ref)
+	}
+	if s == nil || s.WriteOnly {
+		return
+	}
+
+	switch cur := current.(type) {
+	case map[string]any:
+		req, ok := requested.(map[string]any)
+		if !ok || len(s.Properties) == 0 {
+			break
+		}
+		names := s.propertyNames
+		if names == nil {
+			names = sortedKeys(s.Properties)
+		}
+		for _, name := range names {
+			prop := s.Properties[name]
+			if prop.WriteOnly {
+				continue
+			}
+			conflicts(r, prop, loc.Field(name), cur[name], req[name], details)
+		}
+		return
+	case []any:
+		req, ok := requested.([]any)
+		if !ok || len(cur) != len(req) || s.Items == nil {
+			break
+		}
+		for i := range cur {
+			conflicts(r, s.Items, loc.Index(i), cur[i], req[i], details)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(current, requested) {
+		*details = append(*details, &ErrorDetail{
+			Message:  "conflicts with the current value",
+			Location: loc.String(),
+			Value:    current,
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package openapi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type ConflictAddress struct {
+	City string `json:"city"`
+}
+
+type ConflictUser struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password" writeOnly:"true"`
+	Address  ConflictAddress   `json:"address"`
+	Previous []ConflictAddress `json:"previous"`
+	Tags     []string          `json:"tags"`
+}
+
+func TestConflicts(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	schema := registry.Schema(reflect.TypeOf(ConflictUser{}), true, "")
+
+	current := ConflictUser{
+		Name:     "kari",
+		Password: "old",
+		Address:  ConflictAddress{City: "Oslo"},
+		Previous: []ConflictAddress{{City: "Bergen"}, {City: "Tromsø"}},
+		Tags:     []string{"a"},
+	}
+	requested := ConflictUser{
+		Name:     "kari",
+		Password: "new",
+		Address:  ConflictAddress{City: "Oslo"},
+		Previous: []ConflictAddress{{City: "Bergen"}, {City: "Bodø"}},
+		Tags:     []string{"a", "b"},
+	}
+
+	details := openapi.Conflicts(registry, schema, current, requested)
+
+	var got []string
+	for _, d := range details {
+		got = append(got, d.Error())
+	}
+	expected := []string{
+		"conflicts with the current value (body.previous[1].city: Tromsø)",
+		"conflicts with the current value (body.tags: [a])",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected conflicts %v", got)
+	}
+
+	if details := openapi.Conflicts(registry, schema, current, current); len(details) != 0 {
+		t.Errorf("expected no conflicts, got %v", details)
+	}
+}
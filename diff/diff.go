@@ -0,0 +1,608 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package diff compares two OpenAPI documents and classifies every change as
+// breaking or non-breaking for existing clients, e.g. to fail a CI build
+// when a change would break clients of the previously published spec.
+//
+//	previous, _ := openapi.LoadFile("openapi.published.json")
+//	changes := diff.Diff(previous, builder.OpenAPI())
+//	if changes.HasBreaking() {
+//		log.Fatal(changes.Breaking())
+//	}
+//
+// Request schemas break clients when they get narrower, e.g. a new required
+// property or a removed enum value, and response schemas break clients when
+// they get wider, e.g. a property which is no longer required.
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/restk/openapi"
+)
+
+// Kind is the kind of a Change.
+type Kind string
+
+const (
+	PathAdded          Kind = "path-added"
+	PathRemoved        Kind = "path-removed"
+	OperationAdded     Kind = "operation-added"
+	OperationRemoved   Kind = "operation-removed"
+	ParameterAdded     Kind = "parameter-added"
+	ParameterRemoved   Kind = "parameter-removed"
+	RequestBodyAdded   Kind = "request-body-added"
+	RequestBodyRemoved Kind = "request-body-removed"
+	ResponseAdded      Kind = "response-added"
+	ResponseRemoved    Kind = "response-removed"
+	ContentTypeAdded   Kind = "content-type-added"
+	ContentTypeRemoved Kind = "content-type-removed"
+	PropertyAdded      Kind = "property-added"
+	PropertyRemoved    Kind = "property-removed"
+	RequiredAdded      Kind = "required-added"
+	RequiredRemoved    Kind = "required-removed"
+	TypeChanged        Kind = "type-changed"
+	EnumValueAdded     Kind = "enum-value-added"
+	EnumValueRemoved   Kind = "enum-value-removed"
+	ConcurrencyChanged Kind = "concurrency-changed"
+
+	// Narrowed means fewer values are accepted, e.g. a lower maxLength.
+	Narrowed Kind = "narrowed"
+
+	// Widened means more values are accepted, e.g. a removed pattern.
+	Widened Kind = "widened"
+)
+
+// Change is a single difference between two documents.
+type Change struct {
+	Kind     Kind
+	Breaking bool
+
+	Path   string
+	Method string
+
+	// Location of the change within the operation, e.g. `query.limit`,
+	// `request.body.name` or `response.200.body.items[].id`. Empty for
+	// added and removed paths and operations.
+	Location string
+
+	// Message describes the change, e.g. `maxLength changed from 10 to 5`.
+	Message string
+}
+
+func (c *Change) String() string {
+	s := c.Path
+	if c.Method != "" {
+		s = c.Method + " " + s
+	}
+	if c.Location != "" {
+		s += " " + c.Location
+	}
+	return s + ": " + c.Message
+}
+
+// ChangeSet is the result of Diff, sorted by path, method and location.
+type ChangeSet struct {
+	Changes []*Change
+}
+
+// Breaking returns the changes which break existing clients.
+func (cs *ChangeSet) Breaking() []*Change {
+	var breaking []*Change
+	for _, c := range cs.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// HasBreaking reports whether any change breaks existing clients.
+func (cs *ChangeSet) HasBreaking() bool {
+	return len(cs.Breaking()) > 0
+}
+
+var methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+var pathParamRe = regexp.MustCompile(`\{[^}]*\}`)
+
+// Diff compares the new document against the old one. Paths are matched
+// ignoring the names of their path parameters, so renaming `{id}` to
+// `{userId}` is not a change.
+func Diff(old, new *openapi.OpenAPI) *ChangeSet {
+	d := &differ{old: old, new: new, seen: map[string]bool{}}
+
+	oldPaths := pathsByTemplate(old)
+	newPaths := pathsByTemplate(new)
+
+	for key, oldPath := range oldPaths {
+		newPath, ok := newPaths[key]
+		if !ok {
+			d.path = oldPath
+			d.add(PathRemoved, true, "", "path removed")
+			continue
+		}
+
+		oldItem, newItem := old.Paths[oldPath], new.Paths[newPath]
+		d.path = newPath
+		for _, method := range methods {
+			oldOp, newOp := oldItem.Operation(method), newItem.Operation(method)
+			d.method = method
+			switch {
+			case oldOp == nil && newOp == nil:
+			case oldOp == nil:
+				d.add(OperationAdded, false, "", "operation added")
+			case newOp == nil:
+				d.add(OperationRemoved, true, "", "operation removed")
+			default:
+				d.operation(oldPath, newPath, oldOp, newOp)
+			}
+		}
+		d.method = ""
+	}
+
+	for key, newPath := range newPaths {
+		if _, ok := oldPaths[key]; !ok {
+			d.path = newPath
+			d.add(PathAdded, false, "", "path added")
+		}
+	}
+
+	return d.changeSet()
+}
+
+// MediaTypes compares two versions of the media types of the document, e.g.
+// `application/vnd.myco.v1+json` and `application/vnd.myco.v2+json`, by
+// comparing the request and response schemas of every operation which has
+// both, as if clients switched from one to the other.
+func MediaTypes(o *openapi.OpenAPI, from, to string) *ChangeSet {
+	d := &differ{old: o, new: o, seen: map[string]bool{}}
+
+	o.WalkOperations(func(path, method string, op *openapi.Operation) {
+		d.path, d.method = path, method
+
+		if body := requestBody(o, op.RequestBody); body != nil {
+			d.mediaTypes("request.body", true, body.Content[from], body.Content[to])
+		}
+		for _, status := range sortedKeys(op.Responses) {
+			resp := response(o, op.Responses[status])
+			d.mediaTypes("response."+status+".body", false, resp.Content[from], resp.Content[to])
+		}
+	})
+
+	return d.changeSet()
+}
+
+func (d *differ) mediaTypes(location string, request bool, from, to *openapi.MediaType) {
+	if from != nil && to != nil {
+		d.schema(location, request, from.Schema, to.Schema, map[[2]*openapi.Schema]bool{})
+	}
+}
+
+// changeSet returns the changes sorted by path, method and location.
+func (d *differ) changeSet() *ChangeSet {
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		a, b := d.changes[i], d.changes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Location < b.Location
+	})
+
+	return &ChangeSet{Changes: d.changes}
+}
+
+func pathsByTemplate(o *openapi.OpenAPI) map[string]string {
+	paths := map[string]string{}
+	for path := range o.Paths {
+		paths[pathParamRe.ReplaceAllString(path, "{}")] = path
+	}
+	return paths
+}
+
+type differ struct {
+	old, new *openapi.OpenAPI
+
+	path, method string
+	changes      []*Change
+
+	// seen dedupes changes found through several content types.
+	seen map[string]bool
+}
+
+func (d *differ) add(kind Kind, breaking bool, location, message string) {
+	key := d.method + " " + d.path + " " + location + " " + message
+	if d.seen[key] {
+		return
+	}
+	d.seen[key] = true
+
+	d.changes = append(d.changes, &Change{
+		Kind:     kind,
+		Breaking: breaking,
+		Path:     d.path,
+		Method:   d.method,
+		Location: location,
+		Message:  message,
+	})
+}
+
+func (d *differ) operation(oldPath, newPath string, old, new *openapi.Operation) {
+	if oldConcurrency, newConcurrency := old.Concurrency(), new.Concurrency(); oldConcurrency != newConcurrency {
+		// Clients which call the operation in parallel break when it becomes
+		// sequential.
+		d.add(ConcurrencyChanged, newConcurrency == openapi.ConcurrencySequential, "", "concurrency changed from "+quote(oldConcurrency)+" to "+quote(newConcurrency))
+	}
+
+	d.params(oldPath, newPath, params(d.old, oldPath, old), params(d.new, newPath, new))
+	d.requestBody(requestBody(d.old, old.RequestBody), requestBody(d.new, new.RequestBody))
+
+	for _, status := range sortedKeys(old.Responses) {
+		oldResp := response(d.old, old.Responses[status])
+		newResp, ok := new.Responses[status]
+		if !ok {
+			// Clients usually only depend on the success responses.
+			d.add(ResponseRemoved, strings.HasPrefix(status, "2") || status == "default", "response."+status, "response removed")
+			continue
+		}
+		d.content("response."+status+".body", false, oldResp.Content, response(d.new, newResp).Content)
+	}
+	for _, status := range sortedKeys(new.Responses) {
+		if _, ok := old.Responses[status]; !ok {
+			d.add(ResponseAdded, false, "response."+status, "response added")
+		}
+	}
+}
+
+// params compares the parameters of an operation. Path parameters are
+// matched by their position in the path, as they may have been renamed.
+func (d *differ) params(oldPath, newPath string, old, new []*openapi.Param) {
+	oldPositions, newPositions := pathParamPositions(oldPath), pathParamPositions(newPath)
+	key := func(p *openapi.Param, positions map[string]int) string {
+		if i, ok := positions[p.Name]; ok && p.In == "path" {
+			return fmt.Sprintf("path.%d", i)
+		}
+		return p.In + "." + p.Name
+	}
+	find := func(params []*openapi.Param, positions map[string]int, k string) *openapi.Param {
+		for _, candidate := range params {
+			if key(candidate, positions) == k {
+				return candidate
+			}
+		}
+		return nil
+	}
+
+	for _, oldParam := range old {
+		newParam := find(new, newPositions, key(oldParam, oldPositions))
+		if newParam == nil {
+			d.add(ParameterRemoved, false, oldParam.In+"."+oldParam.Name, "parameter removed")
+			continue
+		}
+
+		location := newParam.In + "." + newParam.Name
+		if newParam.Required && !oldParam.Required {
+			d.add(RequiredAdded, true, location, "parameter became required")
+		} else if oldParam.Required && !newParam.Required {
+			d.add(RequiredRemoved, false, location, "parameter became optional")
+		}
+		d.schema(location, true, oldParam.Schema, newParam.Schema, map[[2]*openapi.Schema]bool{})
+	}
+
+	for _, newParam := range new {
+		if find(old, oldPositions, key(newParam, newPositions)) == nil {
+			message := "optional parameter added"
+			if newParam.Required {
+				message = "required parameter added"
+			}
+			d.add(ParameterAdded, newParam.Required, newParam.In+"."+newParam.Name, message)
+		}
+	}
+}
+
+func (d *differ) requestBody(old, new *openapi.RequestBody) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		message := "optional request body added"
+		if new.Required {
+			message = "required request body added"
+		}
+		d.add(RequestBodyAdded, new.Required, "request.body", message)
+		return
+	case new == nil:
+		d.add(RequestBodyRemoved, false, "request.body", "request body removed")
+		return
+	}
+
+	if new.Required && !old.Required {
+		d.add(RequiredAdded, true, "request.body", "request body became required")
+	}
+	d.content("request.body", true, old.Content, new.Content)
+}
+
+// content compares the schemas of the content types both sides define.
+func (d *differ) content(location string, request bool, old, new map[string]*openapi.MediaType) {
+	for _, contentType := range sortedKeys(old) {
+		newMT, ok := new[contentType]
+		if !ok {
+			d.add(ContentTypeRemoved, true, location, "content type "+contentType+" removed")
+			continue
+		}
+		if oldMT := old[contentType]; oldMT != nil && newMT != nil {
+			d.schema(location, request, oldMT.Schema, newMT.Schema, map[[2]*openapi.Schema]bool{})
+		}
+	}
+	for _, contentType := range sortedKeys(new) {
+		if _, ok := old[contentType]; !ok {
+			d.add(ContentTypeAdded, false, location, "content type "+contentType+" added")
+		}
+	}
+}
+
+// schema compares two schemas. Request schemas break clients when they
+// accept fewer values, response schemas when they return more values.
+func (d *differ) schema(location string, request bool, old, new *openapi.Schema, visiting map[[2]*openapi.Schema]bool) {
+	old, new = resolve(d.old, old), resolve(d.new, new)
+	if old == nil || new == nil {
+		return
+	}
+
+	pair := [2]*openapi.Schema{old, new}
+	if visiting[pair] {
+		return
+	}
+	visiting[pair] = true
+	defer delete(visiting, pair)
+
+	narrowed := func(message string) {
+		d.add(Narrowed, request, location, message)
+	}
+	widened := func(message string) {
+		d.add(Widened, !request, location, message)
+	}
+
+	if old.Type != new.Type {
+		d.add(TypeChanged, true, location, "type changed from "+quote(old.Type)+" to "+quote(new.Type))
+		return
+	}
+	if old.Format != new.Format {
+		d.add(TypeChanged, true, location, "format changed from "+quote(old.Format)+" to "+quote(new.Format))
+	}
+	if old.Nullable && !new.Nullable {
+		narrowed("no longer nullable")
+	} else if new.Nullable && !old.Nullable {
+		widened("became nullable")
+	}
+
+	d.enum(location, request, old.Enum, new.Enum)
+
+	compareBound("minimum", false, old.Minimum, new.Minimum, narrowed, widened)
+	compareBound("exclusiveMinimum", false, old.ExclusiveMinimum, new.ExclusiveMinimum, narrowed, widened)
+	compareBound("maximum", true, old.Maximum, new.Maximum, narrowed, widened)
+	compareBound("exclusiveMaximum", true, old.ExclusiveMaximum, new.ExclusiveMaximum, narrowed, widened)
+	compareBound("minLength", false, old.MinLength, new.MinLength, narrowed, widened)
+	compareBound("maxLength", true, old.MaxLength, new.MaxLength, narrowed, widened)
+	compareBound("minItems", false, old.MinItems, new.MinItems, narrowed, widened)
+	compareBound("maxItems", true, old.MaxItems, new.MaxItems, narrowed, widened)
+	compareBound("minProperties", false, old.MinProperties, new.MinProperties, narrowed, widened)
+	compareBound("maxProperties", true, old.MaxProperties, new.MaxProperties, narrowed, widened)
+
+	switch {
+	case old.Pattern == new.Pattern:
+	case old.Pattern == "":
+		narrowed("pattern " + quote(new.Pattern) + " added")
+	case new.Pattern == "":
+		widened("pattern " + quote(old.Pattern) + " removed")
+	default:
+		narrowed("pattern changed from " + quote(old.Pattern) + " to " + quote(new.Pattern))
+	}
+
+	if old.AdditionalProperties != false && new.AdditionalProperties == false {
+		narrowed("additional properties no longer allowed")
+	} else if old.AdditionalProperties == false && new.AdditionalProperties != false {
+		widened("additional properties allowed")
+	}
+
+	oldRequired, newRequired := set(old.Required), set(new.Required)
+	for _, name := range sortedKeys(old.Properties) {
+		propLocation := location + "." + name
+		newProp, ok := new.Properties[name]
+		if !ok {
+			// Clients may read the property from responses.
+			d.add(PropertyRemoved, !request, propLocation, "property removed")
+			continue
+		}
+		d.schema(propLocation, request, old.Properties[name], newProp, visiting)
+	}
+	for _, name := range sortedKeys(new.Properties) {
+		if _, ok := old.Properties[name]; !ok {
+			d.add(PropertyAdded, false, location+"."+name, "property added")
+		}
+	}
+	for _, name := range new.Required {
+		if !oldRequired[name] {
+			d.add(RequiredAdded, request, location+"."+name, "property became required")
+		}
+	}
+	for _, name := range old.Required {
+		if !newRequired[name] {
+			if _, ok := new.Properties[name]; ok {
+				d.add(RequiredRemoved, !request, location+"."+name, "property became optional")
+			}
+		}
+	}
+
+	d.schema(location+"[]", request, old.Items, new.Items, visiting)
+	if oldAP, ok := old.AdditionalProperties.(*openapi.Schema); ok {
+		if newAP, ok := new.AdditionalProperties.(*openapi.Schema); ok {
+			d.schema(location+".*", request, oldAP, newAP, visiting)
+		}
+	}
+
+	for _, variants := range [][2][]*openapi.Schema{{old.OneOf, new.OneOf}, {old.AnyOf, new.AnyOf}} {
+		oldVariants, newVariants := variants[0], variants[1]
+		if len(newVariants) < len(oldVariants) {
+			narrowed(fmt.Sprintf("variants reduced from %d to %d", len(oldVariants), len(newVariants)))
+		} else if len(newVariants) > len(oldVariants) {
+			widened(fmt.Sprintf("variants increased from %d to %d", len(oldVariants), len(newVariants)))
+		}
+		for i := 0; i < len(oldVariants) && i < len(newVariants); i++ {
+			d.schema(location, request, oldVariants[i], newVariants[i], visiting)
+		}
+	}
+	for i := 0; i < len(old.AllOf) && i < len(new.AllOf); i++ {
+		d.schema(location, request, old.AllOf[i], new.AllOf[i], visiting)
+	}
+}
+
+func (d *differ) enum(location string, request bool, old, new []any) {
+	switch {
+	case len(old) == 0 && len(new) == 0:
+		return
+	case len(old) == 0:
+		d.add(Narrowed, request, location, "enum added")
+		return
+	case len(new) == 0:
+		d.add(Widened, !request, location, "enum removed")
+		return
+	}
+
+	oldValues, newValues := map[string]bool{}, map[string]bool{}
+	for _, v := range old {
+		oldValues[fmt.Sprint(v)] = true
+	}
+	for _, v := range new {
+		newValues[fmt.Sprint(v)] = true
+	}
+
+	// Clients may send removed values and may not handle added values.
+	for _, v := range old {
+		if !newValues[fmt.Sprint(v)] {
+			d.add(EnumValueRemoved, request, location, fmt.Sprintf("enum value %v removed", v))
+		}
+	}
+	for _, v := range new {
+		if !oldValues[fmt.Sprint(v)] {
+			d.add(EnumValueAdded, !request, location, fmt.Sprintf("enum value %v added", v))
+		}
+	}
+}
+
+// compareBound reports a changed lower or upper bound as narrowed or
+// widened.
+func compareBound[T int | float64](name string, upper bool, old, new *T, narrowed, widened func(message string)) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		narrowed(fmt.Sprintf("%s %v added", name, *new))
+	case new == nil:
+		widened(fmt.Sprintf("%s %v removed", name, *old))
+	case *old != *new:
+		message := fmt.Sprintf("%s changed from %v to %v", name, *old, *new)
+		if (*new < *old) == upper {
+			narrowed(message)
+		} else {
+			widened(message)
+		}
+	}
+}
+
+// pathParamPositions returns the position of every parameter in the path
+// template.
+func pathParamPositions(path string) map[string]int {
+	positions := map[string]int{}
+	for i, match := range pathParamRe.FindAllString(path, -1) {
+		positions[strings.Trim(match, "{}")] = i
+	}
+	return positions
+}
+
+// params returns the parameters of the path item and operation, with
+// references resolved and operation parameters overriding path parameters.
+func params(o *openapi.OpenAPI, path string, op *openapi.Operation) []*openapi.Param {
+	var resolved []*openapi.Param
+	for _, p := range append(append([]*openapi.Param{}, o.Paths[path].Parameters...), op.Parameters...) {
+		if p.Ref != "" && o.Components != nil {
+			if c := o.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; c != nil {
+				p = c
+			}
+		}
+
+		replaced := false
+		for i, existing := range resolved {
+			if existing.In == p.In && existing.Name == p.Name {
+				resolved[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			resolved = append(resolved, p)
+		}
+	}
+	return resolved
+}
+
+func requestBody(o *openapi.OpenAPI, body *openapi.RequestBody) *openapi.RequestBody {
+	if body != nil && body.Ref != "" && o.Components != nil {
+		if c := o.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]; c != nil {
+			return c
+		}
+	}
+	return body
+}
+
+func response(o *openapi.OpenAPI, resp *openapi.Response) *openapi.Response {
+	if resp != nil && resp.Ref != "" && o.Components != nil {
+		if c := o.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]; c != nil {
+			return c
+		}
+	}
+	if resp == nil {
+		return &openapi.Response{}
+	}
+	return resp
+}
+
+func resolve(o *openapi.OpenAPI, s *openapi.Schema) *openapi.Schema {
+	if s != nil && s.Ref != "" && o.Components != nil && o.Components.Schemas != nil {
+		return o.Components.Schemas.SchemaFromRef(s.Ref)
+	}
+	return s
+}
+
+func set(values []string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quote(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return `"` + s + `"`
+}
@@ -0,0 +1,182 @@
+package diff_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+	"github.com/restk/openapi/diff"
+)
+
+type oldUser struct {
+	Name     string `json:"name" maxLength:"50"`
+	Role     string `json:"role" enum:"admin,user"`
+	Nickname string `json:"nickname,omitempty"`
+}
+
+type newUser struct {
+	Name     string `json:"name" maxLength:"20"`
+	Role     string `json:"role" enum:"user"`
+	Nickname string `json:"nickname,omitempty"`
+	Email    string `json:"email"`
+}
+
+type oldProfile struct {
+	ID     string `json:"id"`
+	Avatar string `json:"avatar"`
+}
+
+type newProfile struct {
+	ID string `json:"id"`
+}
+
+func TestDiff(t *testing.T) {
+	old := openapi.New("title", "1.0.0")
+	createUser := old.Register(&openapi.Operation{OperationID: "createUser", Method: http.MethodPost, Path: "/users"})
+	createUser.Request().Body(&oldUser{})
+	createUser.Response(http.StatusOK).Description("OK").Body(&oldProfile{})
+	getUser := old.Register(&openapi.Operation{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{id}"})
+	getUser.Request().PathParam("id", openapi.StringType)
+	old.Register(&openapi.Operation{OperationID: "health", Method: http.MethodGet, Path: "/health"})
+
+	new := openapi.New("title", "2.0.0")
+	createUser = new.Register(&openapi.Operation{OperationID: "createUser", Method: http.MethodPost, Path: "/users"})
+	createUser.Request().Body(&newUser{})
+	createUser.Response(http.StatusOK).Description("OK").Body(&newProfile{})
+	getUser = new.Register(&openapi.Operation{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{userId}"})
+	getUser.Request().PathParam("userId", openapi.StringType)
+	getUser.Request().QueryParam("limit", openapi.IntType)
+	new.Register(&openapi.Operation{OperationID: "listOrders", Method: http.MethodGet, Path: "/orders"})
+
+	changes := diff.Diff(old.OpenAPI(), new.OpenAPI())
+
+	type change struct {
+		kind     diff.Kind
+		breaking bool
+		path     string
+		location string
+	}
+	expected := []change{
+		{diff.PathRemoved, true, "/health", ""},
+		{diff.PathAdded, false, "/orders", ""},
+		{diff.RequiredAdded, true, "/users", "request.body.email"},
+		{diff.PropertyAdded, false, "/users", "request.body.email"},
+		{diff.Narrowed, true, "/users", "request.body.name"},
+		{diff.EnumValueRemoved, true, "/users", "request.body.role"},
+		{diff.PropertyRemoved, true, "/users", "response.200.body.avatar"},
+		{diff.ParameterAdded, false, "/users/{userId}", "query.limit"},
+	}
+	for _, e := range expected {
+		found := false
+		for _, c := range changes.Changes {
+			if c.Kind == e.kind && c.Breaking == e.breaking && c.Path == e.path && c.Location == e.location {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected change %v, got %v", e, changes.Changes)
+		}
+	}
+	if len(changes.Changes) != len(expected) {
+		t.Errorf("expected %d changes, got %v", len(expected), changes.Changes)
+	}
+	if !changes.HasBreaking() || len(changes.Breaking()) != 5 {
+		t.Errorf("expected 5 breaking changes, got %v", changes.Breaking())
+	}
+
+	if unchanged := diff.Diff(old.OpenAPI(), old.OpenAPI()); len(unchanged.Changes) != 0 {
+		t.Errorf("expected no changes, got %v", unchanged.Changes)
+	}
+}
+
+func TestDiffResponseWidened(t *testing.T) {
+	type oldItem struct {
+		Status string `json:"status" enum:"active"`
+		Name   string `json:"name"`
+	}
+	type newItem struct {
+		Status string `json:"status" enum:"active,archived"`
+		Name   string `json:"name,omitempty"`
+	}
+
+	old := openapi.New("title", "1.0.0")
+	old.Register(&openapi.Operation{OperationID: "list", Method: http.MethodGet, Path: "/items"}).
+		Response(http.StatusOK).Description("OK").Body([]oldItem{})
+	new := openapi.New("title", "1.0.0")
+	new.Register(&openapi.Operation{OperationID: "list", Method: http.MethodGet, Path: "/items"}).
+		Response(http.StatusOK).Description("OK").Body([]newItem{})
+
+	breaking := diff.Diff(old.OpenAPI(), new.OpenAPI()).Breaking()
+	if len(breaking) != 2 {
+		t.Fatalf("expected 2 breaking changes, got %v", breaking)
+	}
+	if c := breaking[0]; c.Kind != diff.RequiredRemoved || c.Location != "response.200.body[].name" {
+		t.Errorf("unexpected change %v", c)
+	}
+	if c := breaking[1]; c.Kind != diff.EnumValueAdded || c.String() != `GET /items response.200.body[].status: enum value archived added` {
+		t.Errorf("unexpected change %v", c)
+	}
+}
+
+func TestMediaTypes(t *testing.T) {
+	type userV1 struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type userV2 struct {
+		Name string `json:"name" maxLength:"20"`
+	}
+
+	builder := openapi.New("title", "1.0.0")
+	putUser := builder.Register(&openapi.Operation{OperationID: "putUser", Method: http.MethodPut, Path: "/user"})
+	putUser.Request().VersionedBody("myco", userV1{}, "v1").VersionedBody("myco", userV2{}, "v2")
+	putUser.Response(http.StatusOK).Description("OK").
+		VersionedBody("myco", userV1{}, "v1").
+		VersionedBody("myco", userV2{}, "v2")
+
+	changes := diff.MediaTypes(builder.OpenAPI(), openapi.VendorMediaType("myco", "v1"), openapi.VendorMediaType("myco", "v2"))
+
+	var got []string
+	for _, c := range changes.Changes {
+		got = append(got, c.String())
+	}
+	expected := []string{
+		"PUT /user request.body.email: property removed",
+		"PUT /user request.body.name: maxLength 20 added",
+		"PUT /user response.200.body.email: property removed",
+		"PUT /user response.200.body.name: maxLength 20 added",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected changes %v", got)
+	}
+	if len(changes.Breaking()) != 2 {
+		t.Errorf("expected 2 breaking changes, got %v", changes.Breaking())
+	}
+}
+
+func TestDiffConcurrency(t *testing.T) {
+	old := openapi.New("title", "1.0.0")
+	old.Register(&openapi.Operation{OperationID: "createOrder", Method: http.MethodPost, Path: "/orders"}).
+		Concurrency(openapi.ConcurrencyParallel)
+	old.Register(&openapi.Operation{OperationID: "listOrders", Method: http.MethodGet, Path: "/orders"})
+
+	new := openapi.New("title", "1.0.0")
+	new.Register(&openapi.Operation{OperationID: "createOrder", Method: http.MethodPost, Path: "/orders"}).
+		Concurrency(openapi.ConcurrencySequential)
+	new.Register(&openapi.Operation{OperationID: "listOrders", Method: http.MethodGet, Path: "/orders"}).
+		Concurrency(openapi.ConcurrencyParallel)
+
+	var got []string
+	for _, c := range diff.Diff(old.OpenAPI(), new.OpenAPI()).Changes {
+		got = append(got, c.String()+" "+strconv.FormatBool(c.Breaking))
+	}
+	expected := []string{
+		`GET /orders: concurrency changed from none to "parallel" false`,
+		`POST /orders: concurrency changed from "parallel" to "sequential" true`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected changes %v", got)
+	}
+}
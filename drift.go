@@ -0,0 +1,203 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Route is a method and path served by a router, e.g. from chi.Walk or
+// gin's Routes(). Path parameters may use any of the common syntaxes:
+// `{id}`, `{id:[0-9]+}`, `:id` or `*`.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// DriftReport lists the differences between the routes a server provides and
+// the operations of a document.
+type DriftReport struct {
+	// Undocumented are routes which are served but not in the document.
+	Undocumented []Route
+
+	// Missing are operations in the document which are not served.
+	Missing []Route
+}
+
+// HasDrift reports whether the server and the document differ.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Undocumented) > 0 || len(r.Missing) > 0
+}
+
+var routeParamRe = regexp.MustCompile(`^(\{[^}]*\}|:.+|\*.*)$`)
+
+// routeKey normalizes the path parameters of a route so paths from different
+// routers can be compared, e.g. `/users/:id` and `/users/{userId}` are both
+// `/users/{}`.
+func routeKey(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if routeParamRe.MatchString(segment) {
+			segments[i] = "{}"
+		} else if pathTemplateRe.MatchString(segment) {
+			segments[i] = pathTemplateRe.ReplaceAllString(segment, "{}")
+		}
+	}
+	return strings.ToUpper(method) + " " + strings.Join(segments, "/")
+}
+
+// CompareRoutes compares a dump of the routes of a server against the
+// operations of the document.
+func (o *OpenAPI) CompareRoutes(routes []Route) *DriftReport {
+	report := &DriftReport{}
+
+	served := map[string]bool{}
+	documented := map[string]bool{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		documented[routeKey(method, path)] = true
+	})
+
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		if served[key] {
+			continue
+		}
+		served[key] = true
+		if !documented[key] {
+			report.Undocumented = append(report.Undocumented, route)
+		}
+	}
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		if !served[routeKey(method, path)] {
+			report.Missing = append(report.Missing, Route{Method: method, Path: path})
+		}
+	})
+
+	return report
+}
+
+// Probe checks a running server at baseURL for the documented paths, using
+// only OPTIONS and HEAD requests so it is safe to run against production.
+// The methods of a path are taken from the Allow header of the OPTIONS
+// response, which reveals undocumented methods on documented paths. Servers
+// which don't answer OPTIONS are only checked for a 404 on HEAD. Routes on
+// paths which are not documented at all can't be discovered this way, use
+// CompareRoutes with a route dump for those.
+//
+// Path parameters are filled in with the example of the parameter, or `1`.
+func (o *OpenAPI) Probe(ctx context.Context, client *http.Client, baseURL string) (*DriftReport, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	report := &DriftReport{}
+
+	paths := make([]string, 0, len(o.Paths))
+	for path := range o.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := o.Paths[path]
+
+		documented := map[string]bool{}
+		var anyOp *Operation
+		item.WalkOperations(func(method string, op *Operation) {
+			documented[method] = true
+			anyOp = op
+		})
+		if anyOp == nil {
+			continue
+		}
+
+		target := baseURL + o.examplePath(path, anyOp)
+
+		allow, found, err := probeAllow(ctx, client, target)
+		if err != nil {
+			return nil, err
+		}
+
+		item.WalkOperations(func(method string, op *Operation) {
+			if !found || (allow != nil && !allow[method]) {
+				report.Missing = append(report.Missing, Route{Method: method, Path: path})
+			}
+		})
+
+		methods := make([]string, 0, len(allow))
+		for method := range allow {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			if !documented[method] && method != http.MethodOptions && method != http.MethodHead {
+				report.Undocumented = append(report.Undocumented, Route{Method: method, Path: path})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// probeAllow returns the methods allowed for the URL, or nil if the server
+// doesn't say, and whether the URL exists at all.
+func probeAllow(ctx context.Context, client *http.Client, target string) (map[string]bool, bool, error) {
+	for _, method := range []string{http.MethodOptions, http.MethodHead} {
+		req, err := http.NewRequestWithContext(ctx, method, target, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, false, err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+
+		if header := resp.Header.Get("Allow"); header != "" {
+			allow := map[string]bool{}
+			for _, m := range strings.Split(header, ",") {
+				allow[strings.ToUpper(strings.TrimSpace(m))] = true
+			}
+			return allow, true, nil
+		}
+
+		if resp.StatusCode < 400 || method == http.MethodHead {
+			return nil, true, nil
+		}
+	}
+
+	return nil, true, nil
+}
+
+// examplePath fills in the path template using the examples of the path
+// parameters, or `1` when there is none.
+func (o *OpenAPI) examplePath(path string, op *Operation) string {
+	examples := map[string]string{}
+	for _, p := range o.operationParams(path, op) {
+		if p.In == "path" && p.Example != nil {
+			examples[p.Name] = url.PathEscape(fmt.Sprint(p.Example))
+		}
+	}
+
+	return pathTemplateRe.ReplaceAllStringFunc(path, func(match string) string {
+		if example, ok := examples[match[1:len(match)-1]]; ok {
+			return example
+		}
+		return "1"
+	})
+}
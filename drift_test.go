@@ -0,0 +1,65 @@
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func driftBuilder() *openapi.Builder {
+	builder := openapi.New("title", "version")
+	for _, op := range []*openapi.Operation{
+		{OperationID: "listUsers", Method: http.MethodGet, Path: "/users"},
+		{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{userId}"},
+		{OperationID: "deleteUser", Method: http.MethodDelete, Path: "/users/{userId}"},
+		{OperationID: "health", Method: http.MethodGet, Path: "/health"},
+	} {
+		builder.Register(op)
+	}
+	return builder
+}
+
+func TestCompareRoutes(t *testing.T) {
+	report := driftBuilder().OpenAPI().CompareRoutes([]openapi.Route{
+		{Method: "GET", Path: "/users"},
+		{Method: "GET", Path: "/users/:id"},
+		{Method: "DELETE", Path: "/users/{id:[0-9]+}"},
+		{Method: "POST", Path: "/users"},
+	})
+
+	if len(report.Undocumented) != 1 || report.Undocumented[0].Method != "POST" {
+		t.Errorf("unexpected undocumented routes %v", report.Undocumented)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Path != "/health" {
+		t.Errorf("unexpected missing routes %v", report.Missing)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			w.Header().Set("Allow", "GET, POST, OPTIONS")
+		case "/users/1":
+			w.Header().Set("Allow", "GET")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	report, err := driftBuilder().OpenAPI().Probe(context.Background(), server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Undocumented) != 1 || report.Undocumented[0] != (openapi.Route{Method: "POST", Path: "/users"}) {
+		t.Errorf("unexpected undocumented routes %v", report.Undocumented)
+	}
+	if len(report.Missing) != 2 || report.Missing[0].Path != "/health" || report.Missing[1].Method != "DELETE" {
+		t.Errorf("unexpected missing routes %v", report.Missing)
+	}
+}
@@ -0,0 +1,101 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type UploadForm struct {
+	Name   string `json:"name"`
+	Avatar []byte `json:"avatar" encoding:"binary"`
+}
+
+func TestEncodingBuilder(t *testing.T) {
+	builder := openapi.New("title", "version")
+	upload := builder.Register(&openapi.Operation{
+		OperationID: "upload",
+		Method:      http.MethodPost,
+		Path:        "/upload",
+	})
+
+	upload.Request().ContentType("multipart/form-data").Body(UploadForm{}).
+		Encoding("avatar").ContentType("image/png").
+		Header("X-Checksum", openapi.StringType).Description("SHA-256 of the file")
+
+	encoding := builder.OpenAPI().Paths["/upload"].Post.RequestBody.Content["multipart/form-data"].Encoding["avatar"]
+	if encoding == nil || encoding.ContentType != "image/png" {
+		t.Fatalf("unexpected encoding %v", encoding)
+	}
+	if header := encoding.Headers["X-Checksum"]; header == nil || header.Description != "SHA-256 of the file" {
+		t.Errorf("unexpected header %v", encoding.Headers)
+	}
+}
+
+type Invoice struct {
+	Document string `json:"document" contentMediaType:"application/xml"`
+}
+
+func TestContentMediaType(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getInvoice := builder.Register(&openapi.Operation{
+		OperationID: "getInvoice",
+		Method:      http.MethodGet,
+		Path:        "/invoice",
+	})
+	getInvoice.Response(http.StatusOK).Description("OK").Body(Invoice{})
+	getInvoice.Response(http.StatusOK).ContentType("text/plain").Body(openapi.StringType).
+		ContentMediaType("image/png").ContentEncoding("base64")
+
+	invoice := builder.OpenAPI().Components.Schemas.SchemaFromRef("#/components/schemas/Invoice")
+	if mediaType := invoice.Properties["document"].ContentMediaType; mediaType != "application/xml" {
+		t.Errorf("unexpected contentMediaType %q", mediaType)
+	}
+
+	schema := builder.OpenAPI().Paths["/invoice"].Get.Responses["200"].Content["text/plain"].Schema
+	if schema.ContentMediaType != "image/png" || schema.ContentEncoding != "base64" {
+		t.Errorf("unexpected schema %v", schema)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for non-string body")
+		}
+	}()
+	getInvoice.Response(http.StatusOK).Body(Invoice{}).ContentMediaType("application/xml")
+}
+
+type UserV1 struct {
+	Name string `json:"name"`
+}
+
+type UserV2 struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+func TestVersionedBody(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodPut,
+		Path:        "/user",
+	})
+	getUser.Request().VersionedBody("myco", UserV1{}, "v1").VersionedBody("myco", UserV2{}, "v2")
+	getUser.Response(http.StatusOK).Description("OK").
+		VersionedBody("myco", UserV1{}, "v1").
+		VersionedBody("myco", UserV2{}, "v2", "v3")
+
+	op := builder.OpenAPI().Paths["/user"].Put
+	if mt := op.RequestBody.Content["application/vnd.myco.v2+json"]; mt == nil || mt.Schema.Ref != "#/components/schemas/UserV2" {
+		t.Errorf("unexpected request content %v", op.RequestBody.Content)
+	}
+	content := op.Responses["200"].Content
+	if len(content) != 3 || content["application/vnd.myco.v3+json"].Schema.Ref != "#/components/schemas/UserV2" {
+		t.Errorf("unexpected response content %v", content)
+	}
+	if _, ok := content["application/json"]; ok {
+		t.Errorf("expected no default content type")
+	}
+}
@@ -6,7 +6,13 @@
 // THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package openapi
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // ErrorDetail provides details about a specific error.
 type ErrorDetail struct {
@@ -15,7 +21,7 @@ type ErrorDetail struct {
 
 	// Location is a path-like string indicating where the error occurred.
 	// It typically begins with `path`, `query`, `header`, or `body`. Example:
-	// `body.items[3].tags` or `path.thing-id`.
+	// `body.items[3].tags` or `path.thing-id`. See Loc and ParseLocation.
 	Location string `json:"location,omitempty" doc:"Where the error occurred, e.g. 'body.items[3].tags' or 'path.thing-id'"`
 
 	// Value is the value at the given location, echoed back to the client
@@ -35,7 +41,184 @@ func (e *ErrorDetail) Error() string {
 	return fmt.Sprintf("%s (%s: %v)", e.Message, e.Location, e.Value)
 }
 
+// ErrorDetailer is implemented by errors which provide details for the
+// client, so they can be found with `errors.As` once wrapped.
+type ErrorDetailer interface {
+	ErrorDetail() *ErrorDetail
+}
+
 // ErrorDetail satisfies the `ErrorDetailer` interface.
 func (e *ErrorDetail) ErrorDetail() *ErrorDetail {
 	return e
 }
+
+// ErrorModel is the body of the error responses written by Middleware and
+// Handle. Pass it to Builder.StandardError to document it, e.g.
+// `StandardError(http.StatusNotFound, openapi.ErrorModel{})`.
+type ErrorModel struct {
+	Status int            `json:"status" doc:"HTTP status code"`
+	Title  string         `json:"title" doc:"Short summary of the status, e.g. 'Not Found'"`
+	Detail string         `json:"detail,omitempty" doc:"Explanation specific to this occurrence of the error"`
+	Errors []*ErrorDetail `json:"errors,omitempty" doc:"Details about the individual errors"`
+}
+
+// StatusError is an error with the HTTP status and details to respond with,
+// e.g. returned by the handlers of Handle. It can be wrapped and found again
+// with `errors.As`.
+type StatusError struct {
+	// Status is the HTTP status code, e.g. 404.
+	Status int
+
+	// Message is the explanation sent to the client.
+	Message string
+
+	// Details are sent to the client as the errors of the ErrorModel.
+	Details []*ErrorDetail
+
+	// Err is the underlying error, which is not sent to the client.
+	Err error
+}
+
+// NewError returns a StatusError with the given status, message and details,
+// e.g. `openapi.NewError(http.StatusNotFound, "user not found")`.
+func NewError(status int, message string, details ...*ErrorDetail) *StatusError {
+	return &StatusError{
+		Status:  status,
+		Message: message,
+		Details: details,
+	}
+}
+
+func (e *StatusError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// MapError is the default mapping of errors to an HTTP status and an
+// ErrorModel body, see Builder.MapError. A StatusError found with
+// `errors.As` provides the status and details, an ErrorList or ErrorDetailer
+// is a 422 with their details, and any other error is a 500 without details
+// so internal messages don't leak to clients.
+func MapError(err error) (int, any) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status, &ErrorModel{
+			Status: statusErr.Status,
+			Title:  http.StatusText(statusErr.Status),
+			Detail: statusErr.Message,
+			Errors: statusErr.Details,
+		}
+	}
+
+	var list ErrorList
+	if errors.As(err, &list) {
+		return http.StatusUnprocessableEntity, &ErrorModel{
+			Status: http.StatusUnprocessableEntity,
+			Title:  http.StatusText(http.StatusUnprocessableEntity),
+			Errors: list,
+		}
+	}
+
+	var detailer ErrorDetailer
+	if errors.As(err, &detailer) {
+		return http.StatusUnprocessableEntity, &ErrorModel{
+			Status: http.StatusUnprocessableEntity,
+			Title:  http.StatusText(http.StatusUnprocessableEntity),
+			Errors: []*ErrorDetail{detailer.ErrorDetail()},
+		}
+	}
+
+	return http.StatusInternalServerError, &ErrorModel{
+		Status: http.StatusInternalServerError,
+		Title:  http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// ErrorList collects several error details so all the problems of a request
+// can be reported at once instead of failing on the first, e.g.
+//
+//	var errs openapi.ErrorList
+//	if in.Start.After(in.End) {
+//		errs = append(errs, &openapi.ErrorDetail{
+//			Message:  "start must be before end",
+//			Location: openapi.Loc.Body().Field("start").String(),
+//		})
+//	}
+//	return nil, errs.Err()
+//
+// It marshals to the 422 ErrorModel body and MapError responds with it.
+type ErrorList []*ErrorDetail
+
+// newErrorList returns the details of errs. Errors which are not an
+// ErrorDetailer only keep their message.
+func newErrorList(errs []error) ErrorList {
+	list := make(ErrorList, 0, len(errs))
+	for _, err := range errs {
+		var detailer ErrorDetailer
+		if errors.As(err, &detailer) {
+			list = append(list, detailer.ErrorDetail())
+		} else {
+			list = append(list, &ErrorDetail{Message: err.Error()})
+		}
+	}
+	return list
+}
+
+// errorList combines several errors into one, keeping the original errors for
+// errors.Is and errors.As. ErrorList is only used for response bodies.
+type errorList []error
+
+func (e errorList) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e errorList) Unwrap() []error {
+	return e
+}
+
+func (e ErrorList) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e ErrorList) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Err returns the list as an error, or nil if it is empty.
+func (e ErrorList) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// MarshalJSON marshals the list as a 422 ErrorModel.
+func (e ErrorList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&ErrorModel{
+		Status: http.StatusUnprocessableEntity,
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Errors: []*ErrorDetail(e),
+	})
+}
@@ -0,0 +1,63 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestErrorList(t *testing.T) {
+	var errs openapi.ErrorList
+	if errs.Err() != nil {
+		t.Errorf("expected no error for an empty list")
+	}
+	errs = append(errs,
+		&openapi.ErrorDetail{Message: "expected name", Location: "body.name"},
+		&openapi.ErrorDetail{Message: "expected email", Location: "body.email"},
+	)
+
+	err := fmt.Errorf("create user: %w", errs.Err())
+	if err.Error() != "create user: expected name (body.name: <nil>); expected email (body.email: <nil>)" {
+		t.Errorf("unexpected message %s", err)
+	}
+	var list openapi.ErrorList
+	if !errors.As(err, &list) || len(list) != 2 || len(list.Unwrap()) != 2 {
+		t.Errorf("expected the list to be found, got %v", list)
+	}
+
+	b, _ := json.Marshal(errs)
+	if string(b) != `{"status":422,"title":"Unprocessable Entity","errors":[{"message":"expected name","location":"body.name"},{"message":"expected email","location":"body.email"}]}` {
+		t.Errorf("unexpected body %s", b)
+	}
+	if status, body := openapi.MapError(err); status != http.StatusUnprocessableEntity || len(body.(*openapi.ErrorModel).Errors) != 2 {
+		t.Errorf("unexpected mapping %d %v", status, body)
+	}
+}
+
+func TestURLErrorsUnwrap(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	}).Request().PathParam("id", 0).Required(true)
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = o.URL("getUser", nil, nil)
+	var detail *openapi.ErrorDetail
+	if !errors.As(err, &detail) || detail.Location != "path.id" {
+		t.Errorf("expected the original error detail, got %v", err)
+	}
+	var list openapi.ErrorList
+	if errors.As(err, &list) {
+		t.Errorf("expected ErrorList to be reserved for response bodies")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// ExamplePair adds correlated request and response examples to the operation.
+// The examples share the name, which is how docs renderers such as Swagger UI
+// and Redoc match a request example to its response, e.g.
+//
+//	op.ExamplePair("create-basic").
+//		Summary("Create a user").
+//		Request(`{"name": "alice"}`).
+//		Response(http.StatusCreated, `{"id": 1, "name": "alice"}`)
+//
+// Add the request body and responses before the pair.
+func (ob *OperationBuilder) ExamplePair(name string) *ExamplePairBuilder {
+	if name == "" {
+		panic("example pair name must be specified")
+	}
+
+	return &ExamplePairBuilder{
+		op:   ob.op,
+		name: name,
+	}
+}
+
+// ExamplePairBuilder helps build correlated request and response examples.
+type ExamplePairBuilder struct {
+	op       *Operation
+	name     string
+	summary  string
+	examples []*Example
+}
+
+// Summary sets the summary of the request and response examples.
+func (epb *ExamplePairBuilder) Summary(summary string) *ExamplePairBuilder {
+	epb.summary = summary
+	for _, example := range epb.examples {
+		example.Summary = summary
+	}
+
+	return epb
+}
+
+// Request adds the request example to every media type of the request body.
+// Strings and byte slices holding JSON are decoded, other values are used
+// as is. Panics if the operation has no request body.
+func (epb *ExamplePairBuilder) Request(value any) *ExamplePairBuilder {
+	if epb.op.RequestBody == nil || len(epb.op.RequestBody.Content) == 0 {
+		panic("example pair " + epb.name + " needs a request body on " + epb.op.OperationID)
+	}
+
+	epb.add(epb.op.RequestBody.Content, value)
+
+	return epb
+}
+
+// Response adds the response example to every media type of the response
+// with the status. Values are handled like Request. Panics if the operation
+// has no such response with a body.
+func (epb *ExamplePairBuilder) Response(status int, value any) *ExamplePairBuilder {
+	response := epb.op.Responses[strconv.Itoa(status)]
+	if response == nil || len(response.Content) == 0 {
+		panic("example pair " + epb.name + " needs a " + strconv.Itoa(status) + " response body on " + epb.op.OperationID)
+	}
+
+	epb.add(response.Content, value)
+
+	return epb
+}
+
+func (epb *ExamplePairBuilder) add(content map[string]*MediaType, value any) {
+	value = exampleValue(value)
+	for _, mt := range content {
+		if mt.Examples == nil {
+			mt.Examples = map[string]*Example{}
+		}
+
+		example := &Example{
+			Summary: epb.summary,
+			Value:   value,
+		}
+		mt.Examples[epb.name] = example
+		epb.examples = append(epb.examples, example)
+	}
+}
+
+// exampleValue decodes JSON strings and byte slices, so the example is
+// emitted as a value rather than an escaped string.
+func exampleValue(value any) any {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		return value
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,45 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type ExampleUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestExamplePair(t *testing.T) {
+	builder := openapi.New("title", "version")
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	createUser.Request().Body(ExampleUser{})
+	createUser.Response(http.StatusCreated).Description("Created").Body(ExampleUser{})
+	createUser.ExamplePair("create-basic").
+		Request(`{"name": "alice"}`).
+		Response(http.StatusCreated, ExampleUser{ID: 1, Name: "alice"}).
+		Summary("Create a user")
+
+	op := builder.OpenAPI().Paths["/users"].Post
+	request := op.RequestBody.Content["application/json"].Examples["create-basic"]
+	if value, ok := request.Value.(map[string]any); !ok || value["name"] != "alice" || request.Summary != "Create a user" {
+		t.Errorf("unexpected request example %+v", request)
+	}
+	response := op.Responses["201"].Content["application/json"].Examples["create-basic"]
+	if value, ok := response.Value.(ExampleUser); !ok || value.ID != 1 || response.Summary != "Create a user" {
+		t.Errorf("unexpected response example %+v", response)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a missing response")
+		}
+	}()
+	createUser.ExamplePair("missing").Response(http.StatusOK, "{}")
+}
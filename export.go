@@ -0,0 +1,213 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var schemaType = reflect.TypeOf(Schema{})
+
+// ExportForAWSAPIGateway returns the document as OpenAPI 3.0 JSON which
+// imports cleanly into AWS API Gateway. AWS models only understand a subset
+// of JSON Schema draft 4, so keywords such as `discriminator`, `readOnly` and
+// `examples` are removed, and every operation without an
+// `x-amazon-apigateway-integration` gets a mock integration stub so the
+// import does not fail. The document itself is left untouched.
+func (o *OpenAPI) ExportForAWSAPIGateway() ([]byte, error) {
+	exported := o.exportClone()
+
+	walkStructs(reflect.ValueOf(exported), func(v reflect.Value) {
+		if v.Type() != schemaType {
+			return
+		}
+		s := v.Addr().Interface().(*Schema)
+		s.Discriminator = nil
+		s.ReadOnly = false
+		s.WriteOnly = false
+		s.Examples = nil
+	})
+
+	exported.WalkOperations(func(path, method string, op *Operation) {
+		if _, ok := op.Extensions["x-amazon-apigateway-integration"]; ok {
+			return
+		}
+		if op.Extensions == nil {
+			op.Extensions = map[string]any{}
+		}
+		op.Extensions["x-amazon-apigateway-integration"] = &AWSIntegration{
+			Type: "mock",
+			RequestTemplates: map[string]string{
+				"application/json": `{"statusCode": 200}`,
+			},
+			Responses: map[string]*AWSIntegrationResponse{
+				"default": {StatusCode: "200"},
+			},
+		}
+	})
+
+	return exported.Downgrade()
+}
+
+// ExportForAzureAPIM returns the document as OpenAPI 3.0 JSON which imports
+// cleanly into Azure API Management. APIM uses the first server as the
+// backend and names operations after their operationId, so the other servers
+// are dropped and operations without an ID get one generated from the method
+// and path, e.g. `get-users-by-id`. The document itself is left untouched.
+func (o *OpenAPI) ExportForAzureAPIM() ([]byte, error) {
+	exported := o.exportClone()
+
+	if len(exported.Servers) > 1 {
+		exported.Servers = exported.Servers[:1]
+	}
+
+	used := map[string]bool{}
+	exported.WalkOperations(func(path, method string, op *Operation) {
+		used[op.OperationID] = true
+	})
+	exported.WalkOperations(func(path, method string, op *Operation) {
+		if op.OperationID != "" {
+			return
+		}
+		id := inferOperationID(method, path)
+		for i := 2; used[id]; i++ {
+			id = inferOperationID(method, path) + "-" + strconv.Itoa(i)
+		}
+		used[id] = true
+		op.OperationID = id
+	})
+
+	return exported.Downgrade()
+}
+
+// Downgrade30 returns the document as valid OpenAPI 3.0.3 JSON, for the
+// gateways and generators which still reject 3.1. Unlike Downgrade, which
+// only rewrites the keywords that changed between the versions, it also
+// removes the 3.1 features 3.0 has no equivalent for: webhooks, the JSON
+// Schema dialect, license identifiers, reusable path items, which are inlined
+// into the paths referencing them, and schema keywords such as
+// `dependentRequired`. Nullable types and `anyOf` / `oneOf` with a null branch
+// become `nullable: true`, and numeric exclusive bounds become boolean
+// `exclusiveMinimum` / `exclusiveMaximum` next to the bound. The document
+// itself is left untouched.
+func (o *OpenAPI) Downgrade30() ([]byte, error) {
+	exported := o.exportClone()
+
+	if exported.Components != nil && exported.Components.PathItems != nil {
+		for path, item := range exported.Paths {
+			name := strings.TrimPrefix(item.Ref, "#/components/pathItems/")
+			if shared, ok := exported.Components.PathItems[name]; ok && item.Ref != "" {
+				exported.Paths[path] = shared
+			}
+		}
+		exported.Components.PathItems = nil
+	}
+
+	b, err := exported.Downgrade()
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	downgrade30Spec(v)
+	if _, ok := v["paths"]; !ok {
+		// Paths are required in 3.0.
+		v["paths"] = map[string]any{}
+	}
+
+	return json.Marshal(v)
+}
+
+// downgrade30Spec converts the leftovers of downgradeSpec which are not valid
+// 3.0: null branches of `anyOf` / `oneOf` and schema `examples` arrays.
+func downgrade30Spec(input any) {
+	switch value := input.(type) {
+	case map[string]any:
+		for _, k := range []string{"anyOf", "oneOf"} {
+			schemas, ok := value[k].([]any)
+			if !ok {
+				continue
+			}
+
+			var rest []any
+			for _, schema := range schemas {
+				if m, ok := schema.(map[string]any); ok && m["type"] == "null" && len(m) == 1 {
+					value["nullable"] = true
+					continue
+				}
+				rest = append(rest, schema)
+			}
+			if len(rest) == len(schemas) || len(rest) == 0 {
+				continue
+			}
+
+			delete(value, k)
+			if len(rest) > 1 {
+				value[k] = rest
+				continue
+			}
+			if m, ok := rest[0].(map[string]any); ok && m["$ref"] != nil {
+				// Siblings of $ref are ignored in 3.0, so wrap it.
+				value["allOf"] = rest
+			} else if ok {
+				for mk, mv := range m {
+					if _, exists := value[mk]; !exists {
+						value[mk] = mv
+					}
+				}
+			}
+		}
+
+		// Schema examples are a single example in 3.0, which downgradeSpec
+		// already provides.
+		if _, ok := value["examples"].([]any); ok {
+			delete(value, "examples")
+		}
+
+		for _, v := range value {
+			downgrade30Spec(v)
+		}
+	case []any:
+		for _, item := range value {
+			downgrade30Spec(item)
+		}
+	}
+}
+
+// exportClone returns a copy of the document without the OpenAPI 3.1 features
+// gateways reject and which Downgrade does not convert: webhooks, the JSON
+// Schema dialect, license identifiers and the schema keywords which have no
+// 3.0 equivalent.
+func (o *OpenAPI) exportClone() *OpenAPI {
+	exported := o.Clone()
+	exported.Webhooks = nil
+	exported.JSONSchemaDialect = ""
+	if exported.Info != nil && exported.Info.License != nil {
+		exported.Info.License.Identifier = ""
+	}
+
+	walkStructs(reflect.ValueOf(exported), func(v reflect.Value) {
+		if v.Type() != schemaType {
+			return
+		}
+		s := v.Addr().Interface().(*Schema)
+		s.ContentMediaType = ""
+		s.DependentRequired = nil
+		s.PatternDescription = ""
+		if s.ContentEncoding != "base64" {
+			s.ContentEncoding = ""
+		}
+	})
+
+	return exported
+}
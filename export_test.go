@@ -0,0 +1,151 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type exportedUser struct {
+	ID     string `json:"id" readOnly:"true" example:"u1"`
+	Avatar string `json:"avatar" contentMediaType:"image/png" contentEncoding:"base64"`
+}
+
+func exportBuilder() *openapi.Builder {
+	builder := openapi.New("title", "version")
+	builder.Server().URL("https://api.example.com")
+	builder.Server().URL("https://staging.example.com")
+	getUser := builder.Register(&openapi.Operation{
+		Method: http.MethodGet,
+		Path:   "/users/{id}",
+	})
+	getUser.Request().PathParam("id", openapi.StringType)
+	getUser.Response(http.StatusOK).Description("OK").Body(&exportedUser{})
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).AWSIntegration(&openapi.AWSIntegration{
+		Type:       "http_proxy",
+		URI:        "https://backend.example.com/users",
+		HTTPMethod: http.MethodGet,
+	})
+	return builder
+}
+
+func TestExportForAWSAPIGateway(t *testing.T) {
+	o := exportBuilder().OpenAPI()
+	b, err := o.ExportForAWSAPIGateway()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := string(b)
+	if !strings.Contains(spec, `"openapi":"3.0.3"`) {
+		t.Errorf("expected a 3.0 document, got %s", spec)
+	}
+	for _, keyword := range []string{"readOnly", "examples", "example", "contentMediaType"} {
+		if strings.Contains(spec, `"`+keyword+`"`) {
+			t.Errorf("expected %s to be removed, got %s", keyword, spec)
+		}
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Integration openapi.AWSIntegration `json:"x-amazon-apigateway-integration"`
+		}
+	}
+	json.Unmarshal(b, &doc)
+	if integration := doc.Paths["/users/{id}"]["get"].Integration; integration.Type != "mock" || integration.Responses["default"].StatusCode != "200" {
+		t.Errorf("expected a mock integration stub, got %v", integration)
+	}
+	if integration := doc.Paths["/users"]["get"].Integration; integration.Type != "http_proxy" {
+		t.Errorf("expected the existing integration to be kept, got %v", integration)
+	}
+
+	if op := o.Paths["/users/{id}"].Get; op.Extensions["x-amazon-apigateway-integration"] != nil {
+		t.Errorf("expected the document to be left untouched")
+	}
+}
+
+func TestExportForAzureAPIM(t *testing.T) {
+	o := exportBuilder().OpenAPI()
+	b, err := o.ExportForAzureAPIM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Servers []openapi.Server
+		Paths   map[string]map[string]struct {
+			OperationID string
+		}
+	}
+	json.Unmarshal(b, &doc)
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("expected only the first server, got %v", doc.Servers)
+	}
+	if id := doc.Paths["/users/{id}"]["get"].OperationID; id != "get-users-by-id" {
+		t.Errorf("expected a generated operationId, got %q", id)
+	}
+	if id := doc.Paths["/users"]["get"].OperationID; id != "listUsers" {
+		t.Errorf("expected the existing operationId to be kept, got %q", id)
+	}
+	if len(o.Servers) != 2 {
+		t.Errorf("expected the document to be left untouched")
+	}
+}
+
+func TestDowngrade30(t *testing.T) {
+	o := exportBuilder().OpenAPI()
+	minimum := 0.0
+	o.Components.Schemas.Map()["Score"] = &openapi.Schema{
+		Type:             "number",
+		Nullable:         true,
+		ExclusiveMinimum: &minimum,
+	}
+	o.Components.Schemas.Map()["MaybeUser"] = &openapi.Schema{
+		AnyOf: []*openapi.Schema{
+			{Ref: "#/components/schemas/ExportedUser"},
+			{Type: "null"},
+		},
+	}
+	o.Webhooks = map[string]*openapi.PathItem{
+		"userCreated": {Post: &openapi.Operation{OperationID: "userCreated"}},
+	}
+
+	b, err := o.Downgrade30()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		OpenAPI    string
+		Webhooks   map[string]any
+		Components struct {
+			Schemas map[string]map[string]any
+		}
+	}
+	json.Unmarshal(b, &doc)
+	if doc.OpenAPI != "3.0.3" || doc.Webhooks != nil {
+		t.Errorf("unexpected document %s", b)
+	}
+	score := doc.Components.Schemas["Score"]
+	if score["type"] != "number" || score["nullable"] != true || score["exclusiveMinimum"] != true || score["minimum"] != 0.0 {
+		t.Errorf("unexpected score schema %v", score)
+	}
+	maybeUser := doc.Components.Schemas["MaybeUser"]
+	if maybeUser["nullable"] != true || maybeUser["anyOf"] != nil || maybeUser["allOf"] == nil {
+		t.Errorf("unexpected nullable reference %v", maybeUser)
+	}
+	if user := doc.Components.Schemas["ExportedUser"]["properties"].(map[string]any)["id"].(map[string]any); user["examples"] != nil || user["example"] != "u1" {
+		t.Errorf("expected a single example, got %v", user)
+	}
+
+	if o.Webhooks == nil {
+		t.Errorf("expected the document to be left untouched")
+	}
+}
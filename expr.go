@@ -0,0 +1,113 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuntimeExpression is an OpenAPI runtime expression such as
+// `$request.body#/callbackUrl`, used in callback URLs and link parameters.
+// Use the helpers on Expr rather than typing them by hand.
+type RuntimeExpression string
+
+// String returns the bare expression, as used in link parameters.
+func (e RuntimeExpression) String() string {
+	return string(e)
+}
+
+// Template returns the expression wrapped in braces, as used when embedding it
+// in a callback URL, e.g. `{$request.body#/callbackUrl}`.
+func (e RuntimeExpression) Template() string {
+	return "{" + string(e) + "}"
+}
+
+// Expr provides helpers for building runtime expressions.
+//
+//	openapi.Expr.RequestBody("callbackUrl")   // $request.body#/callbackUrl
+//	openapi.Expr.RequestHeader("X-Callback")  // $request.header.X-Callback
+//	openapi.Expr.ResponseBody("items", "0")   // $response.body#/items/0
+var Expr expressions
+
+type expressions struct{}
+
+// URL returns `$url`, the full URL of the request.
+func (expressions) URL() RuntimeExpression { return "$url" }
+
+// Method returns `$method`, the HTTP method of the request.
+func (expressions) Method() RuntimeExpression { return "$method" }
+
+// StatusCode returns `$statusCode`, the status code of the response.
+func (expressions) StatusCode() RuntimeExpression { return "$statusCode" }
+
+// RequestHeader returns `$request.header.{name}`.
+func (expressions) RequestHeader(name string) RuntimeExpression {
+	return RuntimeExpression("$request.header." + name)
+}
+
+// RequestQuery returns `$request.query.{name}`.
+func (expressions) RequestQuery(name string) RuntimeExpression {
+	return RuntimeExpression("$request.query." + name)
+}
+
+// RequestPath returns `$request.path.{name}`.
+func (expressions) RequestPath(name string) RuntimeExpression {
+	return RuntimeExpression("$request.path." + name)
+}
+
+// RequestBody returns `$request.body`, followed by a JSON pointer built from
+// the given field names or array indexes, if any.
+func (expressions) RequestBody(pointer ...string) RuntimeExpression {
+	return RuntimeExpression("$request.body" + jsonPointer(pointer))
+}
+
+// ResponseHeader returns `$response.header.{name}`.
+func (expressions) ResponseHeader(name string) RuntimeExpression {
+	return RuntimeExpression("$response.header." + name)
+}
+
+// ResponseBody returns `$response.body`, followed by a JSON pointer built from
+// the given field names or array indexes, if any.
+func (expressions) ResponseBody(pointer ...string) RuntimeExpression {
+	return RuntimeExpression("$response.body" + jsonPointer(pointer))
+}
+
+// jsonPointer returns a JSON pointer fragment, e.g. `#/a~1b/0`, escaping the
+// reference tokens as defined by RFC 6901.
+func jsonPointer(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var sb strings.Builder
+	sb.WriteString("#")
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(escaper.Replace(token))
+	}
+	return sb.String()
+}
+
+// CallbackURL joins literal strings and runtime expressions into a callback
+// URL, e.g. `CallbackURL("https://example.com/hook?id=", Expr.RequestBody("id"))`
+// returns `https://example.com/hook?id={$request.body#/id}`.
+func CallbackURL(parts ...any) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		switch p := part.(type) {
+		case RuntimeExpression:
+			sb.WriteString(p.Template())
+		case string:
+			sb.WriteString(p)
+		default:
+			panic(fmt.Sprintf("callback url parts must be strings or runtime expressions, got %T", part))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,615 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitPolicy is the `x-ratelimit` extension written by
+// OperationBuilder.RateLimit.
+type RateLimitPolicy struct {
+	// Limit is the number of requests allowed per window.
+	Limit int `json:"limit"`
+
+	// WindowSeconds is the length of the window in seconds.
+	WindowSeconds int64 `json:"windowSeconds"`
+
+	// Scope is what the limit applies to, e.g. "user", "ip" or "global".
+	Scope string `json:"scope,omitempty"`
+}
+
+// RateLimit documents the quota of the operation as the `x-ratelimit`
+// extension, e.g. `RateLimit(100, time.Minute)`. Call Document on the
+// returned builder to also document the 429 response.
+func (ob *OperationBuilder) RateLimit(limit int, window time.Duration) *RateLimitBuilder {
+	if limit <= 0 || window < time.Second {
+		panic("rate limit must be positive and the window at least one second")
+	}
+
+	policy := &RateLimitPolicy{
+		Limit:         limit,
+		WindowSeconds: int64(window / time.Second),
+	}
+	ob.Extension("x-ratelimit", policy)
+
+	return &RateLimitBuilder{
+		operation: ob,
+		policy:    policy,
+	}
+}
+
+// RateLimitBuilder helps build a rate limit policy
+type RateLimitBuilder struct {
+	operation *OperationBuilder
+	policy    *RateLimitPolicy
+}
+
+// Scope sets what the limit applies to, e.g. "user", "ip" or "global".
+func (rlb *RateLimitBuilder) Scope(scope string) *RateLimitBuilder {
+	rlb.policy.Scope = scope
+
+	return rlb
+}
+
+// Document adds a 429 Too Many Requests response with a Retry-After header to
+// the operation, unless it already has one.
+func (rlb *RateLimitBuilder) Document() *RateLimitBuilder {
+	if rlb.operation.op.Responses["429"] != nil {
+		return rlb
+	}
+
+	rb := rlb.operation.Response(http.StatusTooManyRequests).
+		Description("Too Many Requests")
+	rb.Header("Retry-After", IntType).
+		Description("Number of seconds to wait before making a new request")
+
+	return rlb
+}
+
+// MaxBodySize documents the largest request body the operation accepts, in
+// bytes, as the `x-max-body-size` extension and in the description of the
+// operation, and adds a 413 response unless the operation already has one.
+// Middleware rejects larger bodies with a 413. Calling it again replaces the
+// documented size.
+func (ob *OperationBuilder) MaxBodySize(bytes int64) *OperationBuilder {
+	if bytes <= 0 {
+		panic("max body size must be positive")
+	}
+
+	limit := func(bytes int64) string {
+		return "The request body must not exceed " + formatBytes(bytes) + "."
+	}
+	exceeds := func(bytes int64) string {
+		return "The request body exceeds " + formatBytes(bytes)
+	}
+
+	var previous int64
+	extensionAs(ob.op.Extensions["x-max-body-size"], &previous)
+	if previous > 0 && strings.Contains(ob.op.Description, limit(previous)) {
+		ob.Description(strings.Replace(ob.op.Description, limit(previous), limit(bytes), 1))
+	} else {
+		ob.Description(appendNotice(ob.op.Description, limit(bytes)))
+	}
+	ob.Extension("x-max-body-size", bytes)
+
+	if resp := ob.op.Responses["413"]; resp == nil {
+		ob.Response(http.StatusRequestEntityTooLarge).Description(exceeds(bytes))
+	} else if previous > 0 && resp.Description == exceeds(previous) {
+		resp.Description = exceeds(bytes)
+	}
+
+	return ob
+}
+
+// formatBytes returns a size like `1 MiB` or `1500 bytes`.
+func formatBytes(bytes int64) string {
+	for _, unit := range []struct {
+		name string
+		size int64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if bytes >= unit.size && bytes%unit.size == 0 {
+			return strconv.FormatInt(bytes/unit.size, 10) + " " + unit.name
+		}
+	}
+	return strconv.FormatInt(bytes, 10) + " bytes"
+}
+
+// TimeoutPolicy is the `x-timeout` extension written by
+// OperationBuilder.Timeout.
+type TimeoutPolicy struct {
+	// TimeoutMs is the time after which the server gives up on a request, in
+	// milliseconds.
+	TimeoutMs int64 `json:"timeoutMs"`
+}
+
+// Timeout documents how long the server works on a request before giving up
+// as the `x-timeout` extension, so generated clients can use a matching
+// deadline.
+func (ob *OperationBuilder) Timeout(d time.Duration) *OperationBuilder {
+	if d < time.Millisecond {
+		panic("timeout must be at least one millisecond")
+	}
+
+	return ob.Extension("x-timeout", &TimeoutPolicy{
+		TimeoutMs: d.Milliseconds(),
+	})
+}
+
+// RetryPolicy is the `x-retry` extension written by
+// OperationBuilder.Retryable.
+type RetryPolicy struct {
+	// Idempotent reports whether sending the request several times has the
+	// same effect as sending it once.
+	Idempotent bool `json:"idempotent"`
+
+	// Statuses are the response statuses after which the request may be
+	// retried.
+	Statuses []int `json:"statuses"`
+}
+
+// Retryable documents that clients may retry the operation as the `x-retry`
+// extension, and adds a 503 Service Unavailable response with a Retry-After
+// header unless the operation already has one. Idempotent operations may be
+// retried after 408, 429, 502, 503 and 504 responses, others only after 429
+// and 503 responses, which guarantee the request was not processed.
+func (ob *OperationBuilder) Retryable(idempotent bool) *OperationBuilder {
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	if idempotent {
+		statuses = []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	ob.Extension("x-retry", &RetryPolicy{
+		Idempotent: idempotent,
+		Statuses:   statuses,
+	})
+
+	if ob.op.Responses["503"] == nil {
+		rb := ob.Response(http.StatusServiceUnavailable).
+			Description("Service Unavailable")
+		rb.Header("Retry-After", IntType).
+			Description("Number of seconds to wait before retrying the request")
+	}
+
+	return ob
+}
+
+// SunsetPolicy is the `x-sunset` extension written by OperationBuilder.Sunset.
+type SunsetPolicy struct {
+	// Date is the day the operation stops working, e.g. "2025-06-30".
+	Date string `json:"date"`
+
+	// Link is the URL of the migration guide.
+	Link string `json:"link,omitempty"`
+}
+
+// Sunset marks the operation as deprecated until date, when it stops working,
+// and records the date and a link to the migration guide as the `x-sunset`
+// extension. The Sunset (RFC 8594) and Deprecation (RFC 9745) headers, and the
+// Link header if link is set, are documented on every response of the
+// operation, so call Sunset after adding the responses. See
+// OpenAPI.DeprecationReport.
+func (ob *OperationBuilder) Sunset(date time.Time, link string) *OperationBuilder {
+	if date.IsZero() {
+		panic("sunset date must be specified")
+	}
+
+	ob.op.Deprecated = true
+	ob.Extension("x-sunset", &SunsetPolicy{
+		Date: date.UTC().Format("2006-01-02"),
+		Link: link,
+	})
+
+	headers := map[string]string{
+		"Sunset":      "Date after which the operation stops working, e.g. `" + date.UTC().Format(http.TimeFormat) + "`",
+		"Deprecation": "Date since which the operation is deprecated, as a Unix timestamp, e.g. `@1688169599`",
+	}
+	if link != "" {
+		headers["Link"] = "Link to the migration guide, e.g. `<" + link + ">; rel=\"sunset\"`"
+	}
+
+	registry := ob.openAPI.Components.Schemas
+	for _, response := range ob.op.Responses {
+		if response.Headers == nil {
+			response.Headers = map[string]*Param{}
+		}
+		for name, description := range headers {
+			if response.Headers[name] == nil {
+				response.Headers[name] = &Param{
+					Description: description,
+					Schema:      registry.Schema(reflect.TypeOf(""), true, ""),
+				}
+			}
+		}
+	}
+
+	return ob
+}
+
+// SunsetOperation is an operation with an `x-sunset` extension.
+type SunsetOperation struct {
+	Path        string
+	Method      string
+	OperationID string
+	Date        string
+	Link        string
+}
+
+// DeprecationReport lists the operations with an `x-sunset` extension, sorted
+// by sunset date, so the ones to remove first come first. Operations with the
+// same date are sorted by path and method.
+func (o *OpenAPI) DeprecationReport() []*SunsetOperation {
+	var report []*SunsetOperation
+	o.WalkOperations(func(path, method string, op *Operation) {
+		var sunset SunsetPolicy
+		if !extensionAs(op.Extensions["x-sunset"], &sunset) {
+			return
+		}
+
+		report = append(report, &SunsetOperation{
+			Path:        path,
+			Method:      method,
+			OperationID: op.OperationID,
+			Date:        sunset.Date,
+			Link:        sunset.Link,
+		})
+	})
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].Date < report[j].Date
+	})
+
+	return report
+}
+
+// FeatureFlag documents the feature flag gating the operation as the
+// `x-feature-flag` extension. See Builder.FeatureFlags to only include the
+// operation in the built document where the flag is on.
+func (ob *OperationBuilder) FeatureFlag(name string) *OperationBuilder {
+	if name == "" {
+		panic("feature flag must be specified")
+	}
+
+	return ob.Extension("x-feature-flag", name)
+}
+
+// Audiences of operations, from the most to the least privileged. See
+// OperationBuilder.Audience.
+const (
+	AudienceInternal = "internal"
+	AudiencePartner  = "partner"
+	AudiencePublic   = "public"
+)
+
+// Audience documents who may see the operation as the `x-audience`
+// extension, e.g. AudiencePartner, or the name of a tenant. Operations
+// without an audience are public. See OpenAPI.ForAudience.
+func (ob *OperationBuilder) Audience(audience string) *OperationBuilder {
+	if audience == "" {
+		panic("audience must be specified")
+	}
+
+	return ob.Extension("x-audience", audience)
+}
+
+// Concurrency constraints of operations. See OperationBuilder.Concurrency.
+const (
+	// ConcurrencyParallel operations are safe to call in parallel.
+	ConcurrencyParallel = "parallel"
+
+	// ConcurrencySequential operations must not be called while another call
+	// is in progress, e.g. because they modify shared state.
+	ConcurrencySequential = "sequential"
+)
+
+// Concurrency documents whether the operation is safe to call in parallel as
+// the `x-concurrency` extension, e.g. ConcurrencySequential, for load-testing
+// and SDK tooling. See Operation.Concurrency.
+func (ob *OperationBuilder) Concurrency(concurrency string) *OperationBuilder {
+	if concurrency == "" {
+		panic("concurrency must be specified")
+	}
+
+	return ob.Extension("x-concurrency", concurrency)
+}
+
+// Concurrency returns the `x-concurrency` extension of the operation, or an
+// empty string if it has none.
+func (o *Operation) Concurrency() string {
+	var concurrency string
+	extensionAs(o.Extensions["x-concurrency"], &concurrency)
+	return concurrency
+}
+
+// SLA is the `x-sla` extension written by OperationBuilder.SLA.
+type SLA struct {
+	// Tier is the service tier, e.g. "gold".
+	Tier string `json:"tier"`
+
+	// LatencyTargetMs is the latency commitment in milliseconds.
+	LatencyTargetMs int `json:"latencyTargetMs"`
+}
+
+// SLA documents the service tier and latency commitment of the operation as
+// the `x-sla` extension. See OpenAPI.SLAReport.
+func (ob *OperationBuilder) SLA(tier string, latencyTargetMs int) *OperationBuilder {
+	if tier == "" {
+		panic("tier must be specified")
+	}
+
+	return ob.Extension("x-sla", &SLA{
+		Tier:            tier,
+		LatencyTargetMs: latencyTargetMs,
+	})
+}
+
+// SLATier groups the operations of a service tier.
+type SLATier struct {
+	Tier       string
+	Operations []*SLAOperation
+}
+
+// SLAOperation is an operation with an `x-sla` extension.
+type SLAOperation struct {
+	Path            string
+	Method          string
+	OperationID     string
+	LatencyTargetMs int
+}
+
+// SLAReport groups the operations with an `x-sla` extension by tier. Tiers are
+// sorted by name and operations by path and method.
+func (o *OpenAPI) SLAReport() []*SLATier {
+	tiers := map[string]*SLATier{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		var sla SLA
+		if !extensionAs(op.Extensions["x-sla"], &sla) {
+			return
+		}
+
+		tier := tiers[sla.Tier]
+		if tier == nil {
+			tier = &SLATier{Tier: sla.Tier}
+			tiers[sla.Tier] = tier
+		}
+		tier.Operations = append(tier.Operations, &SLAOperation{
+			Path:            path,
+			Method:          method,
+			OperationID:     op.OperationID,
+			LatencyTargetMs: sla.LatencyTargetMs,
+		})
+	})
+
+	report := make([]*SLATier, 0, len(tiers))
+	for _, tier := range tiers {
+		report = append(report, tier)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Tier < report[j].Tier
+	})
+
+	return report
+}
+
+// Owner documents the team owning the operation as the `x-owner` extension.
+// See OpenAPI.OwnershipReport.
+func (ob *OperationBuilder) Owner(team string) *OperationBuilder {
+	if team == "" {
+		panic("team must be specified")
+	}
+
+	return ob.Extension("x-owner", team)
+}
+
+// Team is the contact information of a team owning operations, see
+// OpenAPI.OwnershipReport.
+type Team struct {
+	// Handle is the handle mentioned in review requests, e.g.
+	// `@acme/payments`. Defaults to `@` followed by the team name.
+	Handle string
+
+	Email string
+	Slack string
+}
+
+// OwnerGroup groups the operations owned by a team.
+type OwnerGroup struct {
+	// Owner is the name of the team, empty for operations without owner.
+	Owner string
+
+	// Team is the contact information of the owner, nil if the team is not
+	// in the registry passed to OwnershipReport.
+	Team *Team
+
+	Operations []*OwnedOperation
+}
+
+// OwnedOperation is an operation in an OwnerGroup.
+type OwnedOperation struct {
+	Path        string
+	Method      string
+	OperationID string
+}
+
+// OwnershipReport is the result of OpenAPI.OwnershipReport.
+type OwnershipReport []*OwnerGroup
+
+// OwnershipReport groups the operations by their `x-owner` extension, with
+// the contact information of the owners looked up in teams, e.g. to route
+// API review requests. Groups are sorted by owner, with the operations
+// without owner first, and operations by path and method.
+func (o *OpenAPI) OwnershipReport(teams map[string]*Team) OwnershipReport {
+	groups := map[string]*OwnerGroup{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		var owner string
+		extensionAs(op.Extensions["x-owner"], &owner)
+
+		group := groups[owner]
+		if group == nil {
+			group = &OwnerGroup{Owner: owner}
+			if owner != "" {
+				group.Team = teams[owner]
+			}
+			groups[owner] = group
+		}
+		group.Operations = append(group.Operations, &OwnedOperation{
+			Path:        path,
+			Method:      method,
+			OperationID: op.OperationID,
+		})
+	})
+
+	report := make(OwnershipReport, 0, len(groups))
+	for _, group := range groups {
+		report = append(report, group)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Owner < report[j].Owner
+	})
+
+	return report
+}
+
+// String formats the report like a CODEOWNERS file, with one line per
+// operation followed by the handle of its owner and a comment with the
+// contact information of every team.
+//
+//	# payments: payments@example.com, #payments
+//	POST /payments @acme/payments
+func (r OwnershipReport) String() string {
+	var sb strings.Builder
+	for i, group := range r {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		handle := ""
+		if group.Owner == "" {
+			sb.WriteString("# no owner\n")
+		} else {
+			handle = " @" + group.Owner
+			contact := []string{}
+			if group.Team != nil {
+				if group.Team.Handle != "" {
+					handle = " " + group.Team.Handle
+				}
+				for _, c := range []string{group.Team.Email, group.Team.Slack} {
+					if c != "" {
+						contact = append(contact, c)
+					}
+				}
+			}
+			sb.WriteString("# " + group.Owner)
+			if len(contact) > 0 {
+				sb.WriteString(": " + strings.Join(contact, ", "))
+			}
+			sb.WriteString("\n")
+		}
+
+		for _, op := range group.Operations {
+			sb.WriteString(op.Method + " " + op.Path + handle + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// extensionAs decodes an extension value into target, which must be a pointer.
+// Extensions set by the builder hold typed values while those of a loaded
+// document are decoded JSON, so both are converted through JSON.
+func extensionAs(ext any, target any) bool {
+	if ext == nil {
+		return false
+	}
+
+	b, err := json.Marshal(ext)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, target) == nil
+}
+
+// Projection is the `x-projectable` extension written by
+// OperationBuilder.Projectable.
+type Projection struct {
+	// Parameter is the name of the query parameter selecting the fields.
+	Parameter string `json:"parameter"`
+
+	// Fields are the JSON fields which can be requested.
+	Fields []string `json:"fields"`
+}
+
+// Projectable documents a sparse fieldset query parameter, e.g.
+// `?fields=id,name`, whose values are the JSON fields of v, typically the
+// response body. Slices are unwrapped, so the body of a list endpoint can be
+// passed as is. The fields are also recorded as the `x-projectable`
+// extension.
+func (ob *OperationBuilder) Projectable(name string, v any) *ParamBuilder {
+	if name == "" {
+		panic("projection parameter name must be specified")
+	}
+
+	registry := ob.openAPI.Components.Schemas
+	schema := registry.Schema(reflect.TypeOf(v), true, "")
+	for schema != nil && (schema.Ref != "" || schema.Items != nil) {
+		if schema.Ref != "" {
+			schema = registry.SchemaFromRef(schema.Ref)
+		} else {
+			schema = schema.Items
+		}
+	}
+	if schema == nil || len(schema.Properties) == 0 {
+		panic("projectable type " + reflect.TypeOf(v).String() + " has no fields")
+	}
+
+	fields := append([]string(nil), schema.propertyNames...)
+	if len(fields) == 0 {
+		for field := range schema.Properties {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+	}
+	enum := make([]any, len(fields))
+	for i, field := range fields {
+		enum[i] = field
+	}
+
+	paramSchema := &Schema{
+		Type:  TypeArray,
+		Items: &Schema{Type: TypeString, Enum: enum},
+	}
+	paramSchema.PrecomputeMessages()
+
+	explode := false
+	param := &Param{
+		Name:        name,
+		In:          "query",
+		Description: "Comma separated fields to include in the response, e.g. `" + fields[0] + "`",
+		Schema:      paramSchema,
+		Style:       "form",
+		Explode:     &explode,
+	}
+	ob.op.Parameters = append(ob.op.Parameters, param)
+	ob.Extension("x-projectable", &Projection{
+		Parameter: name,
+		Fields:    fields,
+	})
+
+	return &ParamBuilder{
+		param: param,
+	}
+}
@@ -0,0 +1,340 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+func TestRateLimit(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "search",
+		Method:      http.MethodGet,
+		Path:        "/search",
+	}).RateLimit(100, time.Minute).Scope("user").Document()
+
+	op := builder.OpenAPI().Paths["/search"].Get
+	policy := op.Extensions["x-ratelimit"].(*openapi.RateLimitPolicy)
+	if policy.Limit != 100 || policy.WindowSeconds != 60 || policy.Scope != "user" {
+		t.Errorf("unexpected policy %v", policy)
+	}
+
+	resp := op.Responses["429"]
+	if resp == nil || resp.Headers["Retry-After"] == nil {
+		t.Errorf("expected 429 response with Retry-After, got %v", resp)
+	}
+}
+
+func TestSLAReport(t *testing.T) {
+	builder := openapi.New("title", "version")
+	for _, op := range []struct {
+		id, path, tier string
+		latency        int
+	}{
+		{"getUser", "/users/{id}", "gold", 100},
+		{"listUsers", "/users", "gold", 250},
+		{"export", "/export", "bronze", 5000},
+	} {
+		builder.Register(&openapi.Operation{
+			OperationID: op.id,
+			Method:      http.MethodGet,
+			Path:        op.path,
+		}).SLA(op.tier, op.latency)
+	}
+
+	report := builder.OpenAPI().SLAReport()
+	if len(report) != 2 || report[0].Tier != "bronze" || report[1].Tier != "gold" {
+		t.Fatalf("unexpected report %v", report)
+	}
+	gold := report[1].Operations
+	if len(gold) != 2 || gold[0].OperationID != "listUsers" || gold[1].LatencyTargetMs != 100 {
+		t.Errorf("unexpected gold operations %v", gold)
+	}
+}
+
+func TestGatewayExtensions(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).
+		AWSIntegration(&openapi.AWSIntegration{
+			Type:       "http_proxy",
+			URI:        "https://users.internal/users",
+			HTTPMethod: http.MethodGet,
+		}).
+		GoogleBackend(&openapi.GoogleBackend{Address: "https://users.run.app"}).
+		KongPlugin(&openapi.KongPlugin{Name: "rate-limiting", Config: map[string]any{"minute": 20}})
+
+	b, err := json.Marshal(builder.OpenAPI().Paths["/users"].Get.Extensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"x-amazon-apigateway-integration":{"type":"http_proxy","uri":"https://users.internal/users","httpMethod":"GET"},"x-google-backend":{"address":"https://users.run.app"},"x-kong-plugin-rate-limiting":{"name":"rate-limiting","config":{"minute":20}}}`
+	if string(b) != expected {
+		t.Errorf("unexpected extensions %s", b)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for integration without uri")
+		}
+	}()
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/user",
+	}).AWSIntegration(&openapi.AWSIntegration{Type: "http_proxy"})
+}
+
+func TestOwnershipReport(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "createPayment",
+		Method:      http.MethodPost,
+		Path:        "/payments",
+	}).Owner("payments")
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Owner("identity")
+	builder.Register(&openapi.Operation{
+		OperationID: "health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+	})
+
+	report := builder.OpenAPI().OwnershipReport(map[string]*openapi.Team{
+		"payments": {Handle: "@acme/payments", Email: "payments@example.com", Slack: "#payments"},
+	})
+	if len(report) != 3 || report[0].Owner != "" || report[1].Owner != "identity" || report[1].Team != nil || report[2].Team == nil {
+		t.Fatalf("unexpected report %v", report)
+	}
+
+	expected := `# no owner
+GET /health
+
+# identity
+GET /users @identity
+
+# payments: payments@example.com, #payments
+POST /payments @acme/payments
+`
+	if s := report.String(); s != expected {
+		t.Errorf("unexpected report:\n%s", s)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "upload",
+		Method:      http.MethodPost,
+		Path:        "/upload",
+		Description: "Uploads a file.",
+	}).MaxBodySize(1 << 20)
+
+	op := builder.OpenAPI().Paths["/upload"].Post
+	if op.Extensions["x-max-body-size"] != int64(1<<20) {
+		t.Errorf("unexpected extension %v", op.Extensions)
+	}
+	if op.Description != "Uploads a file.\n\nThe request body must not exceed 1 MiB." {
+		t.Errorf("unexpected description %q", op.Description)
+	}
+	if resp := op.Responses["413"]; resp == nil || resp.Description != "The request body exceeds 1 MiB" {
+		t.Errorf("expected 413 response, got %v", op.Responses)
+	}
+
+	builder.Register(&openapi.Operation{
+		OperationID: "replace",
+		Method:      http.MethodPut,
+		Path:        "/upload",
+		Description: "Replaces a file.",
+	}).MaxBodySize(1 << 20).MaxBodySize(2 << 20)
+
+	op = builder.OpenAPI().Paths["/upload"].Put
+	if op.Description != "Replaces a file.\n\nThe request body must not exceed 2 MiB." {
+		t.Errorf("expected the size to be replaced, got %q", op.Description)
+	}
+	if resp := op.Responses["413"]; resp.Description != "The request body exceeds 2 MiB" {
+		t.Errorf("expected the 413 response to be updated, got %q", resp.Description)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/user",
+	}).Timeout(3 * time.Second).Retryable(true)
+	builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/user",
+	}).Retryable(false)
+
+	item := builder.OpenAPI().Paths["/user"]
+	if timeout := item.Get.Extensions["x-timeout"].(*openapi.TimeoutPolicy); timeout.TimeoutMs != 3000 {
+		t.Errorf("unexpected timeout %v", timeout)
+	}
+	if retry := item.Get.Extensions["x-retry"].(*openapi.RetryPolicy); !retry.Idempotent || len(retry.Statuses) != 5 {
+		t.Errorf("unexpected retry policy %v", retry)
+	}
+	if retry := item.Post.Extensions["x-retry"].(*openapi.RetryPolicy); retry.Idempotent || len(retry.Statuses) != 2 {
+		t.Errorf("unexpected retry policy %v", retry)
+	}
+	if resp := item.Post.Responses["503"]; resp == nil || resp.Headers["Retry-After"] == nil {
+		t.Errorf("expected 503 response with Retry-After, got %v", resp)
+	}
+}
+
+func TestDeprecationReport(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/user",
+	})
+	getUser.Response(http.StatusOK).Description("OK")
+	getUser.Sunset(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), "https://example.com/migrate")
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	}).Sunset(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), "")
+	builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+
+	op := builder.OpenAPI().Paths["/user"].Get
+	if !op.Deprecated {
+		t.Errorf("expected operation to be deprecated")
+	}
+	headers := op.Responses["200"].Headers
+	if headers["Sunset"] == nil || headers["Deprecation"] == nil || headers["Link"] == nil {
+		t.Errorf("expected sunset headers, got %v", headers)
+	}
+
+	report := builder.OpenAPI().DeprecationReport()
+	if len(report) != 2 || report[0].OperationID != "listUsers" || report[1].Date != "2025-12-31" || report[1].Link != "https://example.com/migrate" {
+		t.Errorf("unexpected report %v", report)
+	}
+}
+
+func TestFeatureFlag(t *testing.T) {
+	type Beta struct {
+		Name string `json:"name"`
+	}
+
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	beta := builder.Register(&openapi.Operation{
+		OperationID: "getBeta",
+		Method:      http.MethodGet,
+		Path:        "/beta",
+	}).FeatureFlag("beta")
+	beta.Response(http.StatusOK).Description("OK").Body(&Beta{})
+
+	for _, enabled := range []bool{true, false} {
+		o, err := builder.FeatureFlags(func(flag string) bool {
+			return flag == "beta" && enabled
+		}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := o.Paths["/beta"]; ok != enabled {
+			t.Errorf("expected /beta to be included %v, got %v", enabled, o.Paths)
+		}
+		if _, ok := o.Components.Schemas.Map()["Beta"]; ok != enabled {
+			t.Errorf("expected Beta schema to be included %v", enabled)
+		}
+		if o.Paths["/users"] == nil {
+			t.Errorf("expected unflagged operation to be kept")
+		}
+	}
+
+	if builder.OpenAPI().Paths["/beta"] == nil || builder.OpenAPI().Components.Schemas.Map()["Beta"] == nil {
+		t.Errorf("expected the builder's document to be left untouched")
+	}
+}
+
+func TestProjectable(t *testing.T) {
+	type ProjectedUser struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Secret string `json:"-"`
+	}
+
+	builder := openapi.New("title", "version")
+	list := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	list.Projectable("fields", []ProjectedUser{}).Description("Fields of the users")
+	list.Response(http.StatusOK).Description("OK").Body([]ProjectedUser{})
+
+	op := builder.OpenAPI().Paths["/users"].Get
+	param := op.Parameters[0]
+	if param.Name != "fields" || param.In != "query" || param.Description != "Fields of the users" {
+		t.Errorf("unexpected param %v", param)
+	}
+	if enum := param.Schema.Items.Enum; !reflect.DeepEqual(enum, []any{"id", "name"}) {
+		t.Errorf("unexpected enum %v", enum)
+	}
+
+	b, err := json.Marshal(op.Extensions["x-projectable"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"parameter":"fields","fields":["id","name"]}` {
+		t.Errorf("unexpected extension %s", b)
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("unexpected validation errors %v", errs)
+	}
+}
+
+func TestProjectableValidation(t *testing.T) {
+	type ProjectedUser struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	builder := openapi.New("title", "version")
+	list := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	list.Projectable("fields", []ProjectedUser{})
+	list.Response(http.StatusOK).Description("OK").Body([]ProjectedUser{})
+
+	handler := openapi.Middleware(builder.OpenAPI())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?fields=id,bogus", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"message":"expected value to be one of \"id, name\""`) {
+		t.Errorf("expected the enum message, got %s", body)
+	}
+}
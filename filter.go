@@ -0,0 +1,226 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OperationFilter reports whether an operation is kept by Filter.
+type OperationFilter func(path, method string, op *Operation) bool
+
+// Filter returns a copy of the document which only contains the operations
+// for which keep returns true. Paths without operations, tags only used by
+// removed operations and components which are no longer referenced are
+// removed as well, so the copy doesn't leak the shape of hidden operations.
+// Security schemes are always kept.
+func (o *OpenAPI) Filter(keep OperationFilter) *OpenAPI {
+	filtered := o.Clone()
+
+	removedTags := map[string]bool{}
+	for path, item := range filtered.Paths {
+		item.WalkOperations(func(method string, op *Operation) {
+			if keep(path, method, op) {
+				return
+			}
+			for _, tag := range op.Tags {
+				removedTags[tag] = true
+			}
+			item.RemoveOperation(method)
+		})
+
+		if item.Ref == "" && !hasOperations(item) {
+			delete(filtered.Paths, path)
+		}
+	}
+
+	filtered.WalkOperations(func(path, method string, op *Operation) {
+		for _, tag := range op.Tags {
+			delete(removedTags, tag)
+		}
+	})
+	if len(removedTags) > 0 {
+		tags := filtered.Tags[:0]
+		for _, tag := range filtered.Tags {
+			if !removedTags[tag.Name] {
+				tags = append(tags, tag)
+			}
+		}
+		filtered.Tags = tags
+	}
+
+	filtered.pruneComponents()
+
+	return filtered
+}
+
+func hasOperations(item *PathItem) bool {
+	found := false
+	item.WalkOperations(func(method string, op *Operation) {
+		found = true
+	})
+	return found
+}
+
+// pruneComponents removes components, including registry schemas, which are
+// not referenced from the paths or webhooks, directly or through other
+// components.
+func (o *OpenAPI) pruneComponents() {
+	c := o.Components
+	if c == nil {
+		return
+	}
+
+	kinds := map[string]reflect.Value{
+		"responses":     reflect.ValueOf(c.Responses),
+		"parameters":    reflect.ValueOf(c.Parameters),
+		"examples":      reflect.ValueOf(c.Examples),
+		"requestBodies": reflect.ValueOf(c.RequestBodies),
+		"headers":       reflect.ValueOf(c.Headers),
+		"links":         reflect.ValueOf(c.Links),
+		"callbacks":     reflect.ValueOf(c.Callbacks),
+		"pathItems":     reflect.ValueOf(c.PathItems),
+	}
+	registry, _ := c.Schemas.(*mapRegistry)
+	if registry != nil {
+		kinds["schemas"] = reflect.ValueOf(registry.schemas)
+	}
+
+	// split returns the component map and name a ref points to.
+	split := func(ref string) (string, string) {
+		if registry != nil && strings.HasPrefix(ref, registry.prefix) {
+			return "schemas", ref[len(registry.prefix):]
+		}
+		parts := strings.SplitN(strings.TrimPrefix(ref, "#/components/"), "/", 2)
+		if len(parts) != 2 || !strings.HasPrefix(ref, "#/components/") {
+			return "", ""
+		}
+		return parts[0], parts[1]
+	}
+
+	used := map[string]map[string]bool{}
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		walkStructs(v, func(v reflect.Value) {
+			field := v.FieldByName("Ref")
+			if !field.IsValid() || field.Kind() != reflect.String || field.String() == "" {
+				return
+			}
+
+			kind, name := split(field.String())
+			components, ok := kinds[kind]
+			if !ok || used[kind][name] {
+				return
+			}
+			if used[kind] == nil {
+				used[kind] = map[string]bool{}
+			}
+			used[kind][name] = true
+
+			if target := components.MapIndex(reflect.ValueOf(name)); target.IsValid() {
+				visit(target)
+			}
+		})
+	}
+	visit(reflect.ValueOf(o.Paths))
+	visit(reflect.ValueOf(o.Webhooks))
+
+	for kind, components := range kinds {
+		if components.IsNil() {
+			continue
+		}
+		for _, name := range components.MapKeys() {
+			if !used[kind][name.String()] {
+				components.SetMapIndex(name, reflect.Value{})
+			}
+		}
+	}
+
+	if registry != nil {
+		for name, t := range registry.types {
+			if !used["schemas"][name] {
+				delete(registry.types, name)
+				delete(registry.seen, t)
+			}
+		}
+	}
+}
+
+// ForAudience returns a copy of the document, filtered like Filter, with the
+// operations the audience may see according to OperationBuilder.Audience.
+// The built-in audiences are nested: AudienceInternal sees every operation,
+// AudiencePartner the partner and public operations and AudiencePublic only
+// public ones. Other audiences, e.g. tenants, see the public operations and
+// their own.
+func (o *OpenAPI) ForAudience(audience string) *OpenAPI {
+	return o.Filter(func(path, method string, op *Operation) bool {
+		var opAudience string
+		if !extensionAs(op.Extensions["x-audience"], &opAudience) || opAudience == AudiencePublic {
+			return true
+		}
+
+		switch audience {
+		case AudienceInternal:
+			return true
+		case AudiencePartner:
+			return opAudience == AudiencePartner
+		default:
+			return opAudience == audience
+		}
+	})
+}
+
+// ForEnvironment returns a copy of the document for the docs served in the
+// deployment environment, with the servers of the environment, see
+// ServerBuilder.Environment, moved to the front. Scalar and Swagger UI
+// select the first server by default, so the try-it console of the staging
+// docs targets the staging servers rather than production.
+func (o *OpenAPI) ForEnvironment(environment string) *OpenAPI {
+	c := o.Clone()
+
+	sort.SliceStable(c.Servers, func(i, j int) bool {
+		return serverEnvironment(c.Servers[i]) == environment && serverEnvironment(c.Servers[j]) != environment
+	})
+
+	return c
+}
+
+func serverEnvironment(s *Server) string {
+	var environment string
+	extensionAs(s.Extensions["x-environment"], &environment)
+	return environment
+}
+
+// SplitByTag returns a self-contained document per tag used by operations,
+// each containing only the operations with that tag and the components they
+// need, so teams can publish and version their slice of a shared spec.
+// Operations with several tags appear in each of their documents, untagged
+// operations in none.
+func (o *OpenAPI) SplitByTag() map[string]*OpenAPI {
+	tags := map[string]bool{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+	})
+
+	documents := make(map[string]*OpenAPI, len(tags))
+	for tag := range tags {
+		tag := tag
+		documents[tag] = o.Filter(func(path, method string, op *Operation) bool {
+			for _, t := range op.Tags {
+				if t == tag {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return documents
+}
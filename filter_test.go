@@ -0,0 +1,221 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type PartnerOrder struct {
+	ID string `json:"id"`
+}
+
+type InternalAudit struct {
+	Entries []InternalAuditEntry `json:"entries"`
+}
+
+type InternalAuditEntry struct {
+	Message string `json:"message"`
+}
+
+func filterBuilder() *openapi.Builder {
+	builder := openapi.New("title", "version")
+	builder.OpenAPI().Tags = []*openapi.Tag{{Name: "partner"}, {Name: "internal"}}
+
+	orders := builder.Register(&openapi.Operation{
+		OperationID: "listOrders",
+		Method:      http.MethodGet,
+		Path:        "/orders",
+		Tags:        []string{"partner"},
+	})
+	orders.Response(http.StatusOK).Description("Orders").Body([]PartnerOrder{})
+
+	audit := builder.Register(&openapi.Operation{
+		OperationID: "getAudit",
+		Method:      http.MethodGet,
+		Path:        "/audit",
+		Tags:        []string{"internal"},
+	})
+	audit.Response(http.StatusOK).Description("Audit").Body(InternalAudit{})
+
+	return builder
+}
+
+func partnerOnly(path, method string, op *openapi.Operation) bool {
+	for _, tag := range op.Tags {
+		if tag == "partner" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFilter(t *testing.T) {
+	o := filterBuilder().OpenAPI()
+	filtered := o.Filter(partnerOnly)
+
+	if filtered.Paths["/audit"] != nil || filtered.Paths["/orders"] == nil {
+		t.Errorf("unexpected paths %v", filtered.Paths)
+	}
+	if len(filtered.Tags) != 1 || filtered.Tags[0].Name != "partner" {
+		t.Errorf("unexpected tags %v", filtered.Tags)
+	}
+	schemas := filtered.Components.Schemas.Map()
+	if len(schemas) != 1 || schemas["PartnerOrder"] == nil {
+		t.Errorf("expected only the PartnerOrder schema, got %v", schemas)
+	}
+	if o.Paths["/audit"] == nil || len(o.Components.Schemas.Map()) != 3 {
+		t.Errorf("expected the original document to be untouched")
+	}
+}
+
+func TestFilteredSpecHandler(t *testing.T) {
+	o := filterBuilder().OpenAPI()
+	handler := openapi.FilteredSpecHandler(o, func(r *http.Request) (string, openapi.OperationFilter) {
+		if r.Header.Get("X-Partner") == "" {
+			return "", nil
+		}
+		return "partner", partnerOnly
+	})
+
+	paths := func(header string) map[string]any {
+		r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		if header != "" {
+			r.Header.Set("X-Partner", header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		var doc struct {
+			Paths map[string]any `json:"paths"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc.Paths
+	}
+
+	if p := paths(""); len(p) != 2 {
+		t.Errorf("expected the full spec, got %v", p)
+	}
+	for i := 0; i < 2; i++ {
+		if p := paths("acme"); len(p) != 1 || p["/orders"] == nil {
+			t.Errorf("expected the partner spec, got %v", p)
+		}
+	}
+}
+
+func TestFilteredSpecHandlerEviction(t *testing.T) {
+	o := filterBuilder().OpenAPI()
+	filtered := map[string]int{}
+	handler := openapi.FilteredSpecHandler(o, func(r *http.Request) (string, openapi.OperationFilter) {
+		key := r.Header.Get("X-Partner")
+		return key, func(path, method string, op *openapi.Operation) bool {
+			if path == "/orders" {
+				filtered[key]++
+			}
+			return partnerOnly(path, method, op)
+		}
+	})
+
+	serve := func(key string) {
+		r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		r.Header.Set("X-Partner", key)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve("first")
+	serve("first")
+	if filtered["first"] != 1 {
+		t.Fatalf("expected the variant to be cached, filtered %d times", filtered["first"])
+	}
+	for i := 0; i < 64; i++ {
+		serve(fmt.Sprintf("partner%d", i))
+	}
+	serve("first")
+	if filtered["first"] != 2 {
+		t.Errorf("expected the oldest variant to be evicted, filtered %d times", filtered["first"])
+	}
+}
+
+func TestSplitByTag(t *testing.T) {
+	documents := filterBuilder().OpenAPI().SplitByTag()
+	if len(documents) != 2 {
+		t.Fatalf("expected a document per tag, got %v", documents)
+	}
+
+	internal := documents["internal"]
+	if internal.Paths["/audit"] == nil || internal.Paths["/orders"] != nil {
+		t.Errorf("unexpected internal paths %v", internal.Paths)
+	}
+	schemas := internal.Components.Schemas.Map()
+	if len(schemas) != 2 || schemas["InternalAudit"] == nil || schemas["InternalAuditEntry"] == nil {
+		t.Errorf("expected only the audit schemas, got %v", schemas)
+	}
+}
+
+func TestForAudience(t *testing.T) {
+	builder := filterBuilder()
+	o := builder.OpenAPI()
+	o.Paths["/orders"].Get.Extensions = map[string]any{"x-audience": openapi.AudiencePartner}
+	o.Paths["/audit"].Get.Extensions = map[string]any{"x-audience": openapi.AudienceInternal}
+	builder.Register(&openapi.Operation{
+		OperationID: "health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+	})
+	builder.Register(&openapi.Operation{
+		OperationID: "acmeReport",
+		Method:      http.MethodGet,
+		Path:        "/acme/report",
+	}).Audience("acme")
+
+	for audience, expected := range map[string][]string{
+		openapi.AudienceInternal: {"/acme/report", "/audit", "/health", "/orders"},
+		openapi.AudiencePartner:  {"/health", "/orders"},
+		openapi.AudiencePublic:   {"/health"},
+		"acme":                   {"/acme/report", "/health"},
+	} {
+		variant := o.ForAudience(audience)
+		if len(variant.Paths) != len(expected) {
+			t.Errorf("%s: expected paths %v, got %v", audience, expected, variant.Paths)
+		}
+		for _, path := range expected {
+			if variant.Paths[path] == nil {
+				t.Errorf("%s: expected path %s", audience, path)
+			}
+		}
+	}
+
+	if schemas := o.ForAudience(openapi.AudiencePartner).Components.Schemas.Map(); schemas["InternalAudit"] != nil {
+		t.Errorf("expected internal schemas to be pruned, got %v", schemas)
+	}
+}
+
+func TestForEnvironment(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Server().URL("https://api.example.com").Environment("production")
+	builder.Server().URL("https://staging.example.com").Environment("staging")
+	builder.Server().URL("http://localhost:8080")
+
+	o := builder.OpenAPI().ForEnvironment("staging")
+	var urls []string
+	for _, server := range o.Servers {
+		urls = append(urls, server.URL)
+	}
+	if len(urls) != 3 || urls[0] != "https://staging.example.com" || urls[1] != "https://api.example.com" {
+		t.Errorf("expected the staging server first, got %v", urls)
+	}
+	if builder.OpenAPI().Servers[0].URL != "https://api.example.com" {
+		t.Errorf("expected the document to be left untouched")
+	}
+
+	b, _ := json.Marshal(o.Servers[0])
+	if string(b) != `{"url":"https://staging.example.com","x-environment":"staging"}` {
+		t.Errorf("unexpected server %s", b)
+	}
+}
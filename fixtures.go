@@ -0,0 +1,367 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateFixtures writes Go source declaring a fixture builder for types, and
+// for every struct type they reference, to cut the boilerplate of building
+// request and response bodies in handler and contract tests. For a `User`
+// type it declares `NewUserBuilder()`, which starts from valid defaults, a
+// `With<Field>` method per field to override them and `Build()` returning the
+// User. Defaults are taken from the field's example, default or first enum
+// value, and required fields without one get a fake value matching their
+// type, format and constraints. Like GenerateSchemas the source belongs to
+// package pkg, which must be the package declaring the types:
+//
+//	//go:generate go run ./cmd/fixtures -o fixtures_gen_test.go
+//	openapi.GenerateFixtures(w, "api", api.User{}, api.Order{})
+//
+//	user := api.NewUserBuilder().WithName("alice").Build()
+func GenerateFixtures(w io.Writer, pkg string, types ...any) error {
+	registry := NewMapRegistry("#/components/schemas/", DefaultSchemaNamer).(*mapRegistry)
+	pkgPath := ""
+	for _, v := range types {
+		t := deref(reflect.TypeOf(v))
+		if pkgPath == "" {
+			pkgPath = t.PkgPath()
+		}
+		registry.Schema(t, true, "")
+	}
+
+	g := &fixtureGenerator{
+		pkgPath: pkgPath,
+		structs: map[reflect.Type]bool{},
+		imports: map[string]bool{},
+	}
+	var names []string
+	for name, t := range registry.types {
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+		if t.PkgPath() != pkgPath || t.Name() == "" || strings.ContainsAny(t.Name(), "[.") {
+			return fmt.Errorf("type %s must be a named, non-generic type of package %s", t, pkgPath)
+		}
+		g.structs[t] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	body := &bytes.Buffer{}
+	for _, name := range names {
+		g.writeBuilder(body, registry.types[name], registry.schemas[name])
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by openapi.GenerateFixtures. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		buf.WriteString("import (\n")
+		var std, other []string
+		for _, path := range sortedKeys(g.imports) {
+			if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+				other = append(other, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+		for _, path := range std {
+			buf.WriteString(strconv.Quote(path) + "\n")
+		}
+		if len(std) > 0 && len(other) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, path := range other {
+			buf.WriteString(strconv.Quote(path) + "\n")
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// fixtureGenerator keeps track of the builders and imports of the source
+// written by GenerateFixtures.
+type fixtureGenerator struct {
+	pkgPath string
+	structs map[reflect.Type]bool
+	imports map[string]bool
+}
+
+// writeBuilder writes the fixture builder of the struct t with the schema s.
+func (g *fixtureGenerator) writeBuilder(buf *bytes.Buffer, t reflect.Type, s *Schema) {
+	name := t.Name()
+	builder := name + "Builder"
+
+	type field struct {
+		name     string
+		typ      reflect.Type
+		property string
+	}
+	var fields []field
+	seen := map[string]bool{}
+	for _, info := range getFields(t, map[reflect.Type]struct{}{}) {
+		f := info.Field
+		if seen[f.Name] || !g.settable(t, f.Name) {
+			continue
+		}
+		seen[f.Name] = true
+
+		property := f.Name
+		if j := f.Tag.Get("json"); j != "" {
+			if n, _, _ := strings.Cut(j, ","); n != "" {
+				property = n
+			}
+		}
+		fields = append(fields, field{f.Name, f.Type, property})
+	}
+
+	fmt.Fprintf(buf, "// %s builds %s fixtures.\ntype %s struct {\nv %s\n}\n\n", builder, name, builder, name)
+
+	fmt.Fprintf(buf, "// New%s returns a %s starting from a valid %s.\n", builder, builder, name)
+	fmt.Fprintf(buf, "func New%s() *%s {\nreturn &%s{v: %s{\n", builder, builder, builder, name)
+	required := map[string]bool{}
+	if s != nil {
+		for _, r := range s.Required {
+			required[r] = true
+		}
+	}
+	for _, f := range fields {
+		if s == nil || s.Properties[f.property] == nil || s.Properties[f.property].ReadOnly {
+			continue
+		}
+		if literal, ok := g.fixtureLiteral(f.typ, s.Properties[f.property], required[f.property]); ok {
+			fmt.Fprintf(buf, "%s: %s,\n", f.name, literal)
+		}
+	}
+	buf.WriteString("}}\n}\n\n")
+
+	for _, f := range fields {
+		typ, ok := g.typeExpr(f.typ)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "// With%s sets %s.\n", f.name, f.name)
+		fmt.Fprintf(buf, "func (b *%s) With%s(v %s) *%s {\nb.v.%s = v\nreturn b\n}\n\n", builder, f.name, typ, builder, f.name)
+	}
+
+	fmt.Fprintf(buf, "// Build returns the %s.\nfunc (b *%s) Build() %s {\nreturn b.v\n}\n\n", name, builder, name)
+}
+
+// settable returns whether the field can be set without allocating, i.e. it is
+// not promoted through an embedded pointer.
+func (g *fixtureGenerator) settable(t reflect.Type, name string) bool {
+	f, ok := t.FieldByName(name)
+	if !ok {
+		return false
+	}
+	for _, i := range f.Index[:len(f.Index)-1] {
+		if t = t.Field(i).Type; t.Kind() == reflect.Ptr {
+			return false
+		}
+	}
+	return true
+}
+
+// typeExpr returns the Go source of the type t, or false if it cannot be
+// expressed, e.g. for anonymous structs.
+func (g *fixtureGenerator) typeExpr(t reflect.Type) (string, bool) {
+	if t.Name() != "" {
+		switch {
+		case strings.Contains(t.Name(), "["):
+			return "", false
+		case t.PkgPath() == "":
+			return t.Name(), true
+		case t.PkgPath() == g.pkgPath:
+			return t.Name(), true
+		}
+		g.imports[t.PkgPath()] = true
+		return t.String(), true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		elem, ok := g.typeExpr(t.Elem())
+		if t.Kind() == reflect.Ptr {
+			return "*" + elem, ok
+		}
+		return "[]" + elem, ok
+	case reflect.Array:
+		elem, ok := g.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, ok
+	case reflect.Map:
+		key, ok := g.typeExpr(t.Key())
+		elem, elemOK := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, ok && elemOK
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", true
+		}
+	}
+	return "", false
+}
+
+// fixtureLiteral returns the Go source of the default value of a field of type
+// t with the schema s, or false if the zero value is used.
+func (g *fixtureGenerator) fixtureLiteral(t reflect.Type, s *Schema, required bool) (string, bool) {
+	if t.Kind() == reflect.Ptr {
+		literal, ok := g.fixtureLiteral(t.Elem(), s, required)
+		if !ok {
+			return "", false
+		}
+		elem, ok := g.typeExpr(t.Elem())
+		if !ok {
+			return "", false
+		}
+		g.imports["github.com/restk/openapi"] = true
+		return "openapi.Ptr[" + elem + "](" + literal + ")", true
+	}
+
+	if g.structs[t] {
+		if !required {
+			return "", false
+		}
+		return "New" + t.Name() + "Builder().Build()", true
+	}
+
+	var value any
+	switch {
+	case len(s.Examples) > 0:
+		value = s.Examples[0]
+	case s.Default != nil:
+		value = s.Default
+	case len(s.Enum) > 0:
+		value = s.Enum[0]
+	case required:
+		value = fakeValue(s)
+	}
+	if value == nil {
+		return "", false
+	}
+
+	return g.valueLiteral(t, value)
+}
+
+// valueLiteral returns the Go source of value for the type t.
+func (g *fixtureGenerator) valueLiteral(t reflect.Type, value any) (string, bool) {
+	if t == timeType {
+		s, _ := value.(string)
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", false
+		}
+		g.imports["time"] = true
+		v = v.UTC()
+		return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)", v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond()), true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if v, ok := value.(string); ok {
+			return strconv.Quote(v), true
+		}
+	case reflect.Bool:
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v), true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := value.(type) {
+		case int:
+			return strconv.Itoa(v), true
+		case float64:
+			if v == float64(int64(v)) {
+				return strconv.FormatInt(int64(v), 10), true
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case int:
+			return strconv.Itoa(v), true
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), true
+		}
+	case reflect.Slice:
+		values, ok := value.([]any)
+		if !ok {
+			return "", false
+		}
+		typ, ok := g.typeExpr(t)
+		if !ok {
+			return "", false
+		}
+		out := typ + "{"
+		for _, v := range values {
+			item, ok := g.valueLiteral(t.Elem(), v)
+			if !ok {
+				return "", false
+			}
+			out += item + ", "
+		}
+		return out + "}", true
+	}
+	return "", false
+}
+
+// fakeValue returns a fake value matching the type, format and constraints
+// of s, or nil if the zero value is valid.
+func fakeValue(s *Schema) any {
+	switch s.Type {
+	case TypeString:
+		var v string
+		switch s.Format {
+		case "email":
+			v = "user@example.com"
+		case "uuid":
+			v = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			v = "https://example.com"
+		case "date":
+			v = "2024-01-01"
+		case "date-time":
+			v = "2024-01-01T00:00:00Z"
+		case "time":
+			v = "00:00:00"
+		default:
+			v = "string"
+		}
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			v += strings.Repeat("x", *s.MinLength-len(v))
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			v = v[:*s.MaxLength]
+		}
+		return v
+	case TypeInteger, TypeNumber:
+		switch {
+		case s.Minimum != nil && *s.Minimum > 0:
+			return *s.Minimum
+		case s.ExclusiveMinimum != nil && *s.ExclusiveMinimum >= 0:
+			return float64(int64(*s.ExclusiveMinimum) + 1)
+		case s.Maximum != nil && *s.Maximum < 0:
+			return *s.Maximum
+		case s.ExclusiveMaximum != nil && *s.ExclusiveMaximum <= 0:
+			return float64(int64(*s.ExclusiveMaximum) - 1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Code generated by openapi.GenerateFixtures. DO NOT EDIT.
+
+package openapi_test
+
+import (
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+// FixtureAddressBuilder builds FixtureAddress fixtures.
+type FixtureAddressBuilder struct {
+	v FixtureAddress
+}
+
+// NewFixtureAddressBuilder returns a FixtureAddressBuilder starting from a valid FixtureAddress.
+func NewFixtureAddressBuilder() *FixtureAddressBuilder {
+	return &FixtureAddressBuilder{v: FixtureAddress{
+		City: "Berlin",
+	}}
+}
+
+// WithCity sets City.
+func (b *FixtureAddressBuilder) WithCity(v string) *FixtureAddressBuilder {
+	b.v.City = v
+	return b
+}
+
+// Build returns the FixtureAddress.
+func (b *FixtureAddressBuilder) Build() FixtureAddress {
+	return b.v
+}
+
+// FixtureUserBuilder builds FixtureUser fixtures.
+type FixtureUserBuilder struct {
+	v FixtureUser
+}
+
+// NewFixtureUserBuilder returns a FixtureUserBuilder starting from a valid FixtureUser.
+func NewFixtureUserBuilder() *FixtureUserBuilder {
+	return &FixtureUserBuilder{v: FixtureUser{
+		Email:     "user@example.com",
+		Name:      "stringxx",
+		Role:      "member",
+		Age:       openapi.Ptr[int](30),
+		Score:     1,
+		Address:   NewFixtureAddressBuilder().Build(),
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}
+}
+
+// WithID sets ID.
+func (b *FixtureUserBuilder) WithID(v int) *FixtureUserBuilder {
+	b.v.ID = v
+	return b
+}
+
+// WithEmail sets Email.
+func (b *FixtureUserBuilder) WithEmail(v string) *FixtureUserBuilder {
+	b.v.Email = v
+	return b
+}
+
+// WithName sets Name.
+func (b *FixtureUserBuilder) WithName(v string) *FixtureUserBuilder {
+	b.v.Name = v
+	return b
+}
+
+// WithRole sets Role.
+func (b *FixtureUserBuilder) WithRole(v string) *FixtureUserBuilder {
+	b.v.Role = v
+	return b
+}
+
+// WithAge sets Age.
+func (b *FixtureUserBuilder) WithAge(v *int) *FixtureUserBuilder {
+	b.v.Age = v
+	return b
+}
+
+// WithScore sets Score.
+func (b *FixtureUserBuilder) WithScore(v float64) *FixtureUserBuilder {
+	b.v.Score = v
+	return b
+}
+
+// WithTags sets Tags.
+func (b *FixtureUserBuilder) WithTags(v []string) *FixtureUserBuilder {
+	b.v.Tags = v
+	return b
+}
+
+// WithAddress sets Address.
+func (b *FixtureUserBuilder) WithAddress(v FixtureAddress) *FixtureUserBuilder {
+	b.v.Address = v
+	return b
+}
+
+// WithCreatedAt sets CreatedAt.
+func (b *FixtureUserBuilder) WithCreatedAt(v time.Time) *FixtureUserBuilder {
+	b.v.CreatedAt = v
+	return b
+}
+
+// Build returns the FixtureUser.
+func (b *FixtureUserBuilder) Build() FixtureUser {
+	return b.v
+}
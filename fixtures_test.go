@@ -0,0 +1,77 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+type FixtureUser struct {
+	ID        int            `json:"id" readOnly:"true"`
+	Email     string         `json:"email" format:"email"`
+	Name      string         `json:"name" minLength:"8"`
+	Role      string         `json:"role" enum:"member,admin"`
+	Age       *int           `json:"age,omitempty" example:"30"`
+	Score     float64        `json:"score" minimum:"1"`
+	Tags      []string       `json:"tags,omitempty"`
+	Address   FixtureAddress `json:"address"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type FixtureAddress struct {
+	City string `json:"city" example:"Berlin"`
+}
+
+func TestGenerateFixtures(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := openapi.GenerateFixtures(buf, "openapi_test", FixtureUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := os.ReadFile("fixtures_gen_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(generated) {
+		t.Errorf("fixtures_gen_test.go is out of date, got\n%s", buf.String())
+	}
+}
+
+func TestFixtureBuilders(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	s := registry.Schema(reflect.TypeOf(FixtureUser{}), false, "")
+
+	user := NewFixtureUserBuilder().Build()
+	res := &openapi.ValidateResult{}
+	openapi.Validate(registry, s, &openapi.PathBuffer{}, openapi.ModeWriteToServer, jsonRoundTrip(t, user), res)
+	if len(res.Errors) != 0 {
+		t.Errorf("expected a valid default user, got %v", res.Errors)
+	}
+	if user.Address.City != "Berlin" || user.Age == nil || *user.Age != 30 {
+		t.Errorf("expected defaults from examples, got %+v", user)
+	}
+
+	user = NewFixtureUserBuilder().WithName("alice").WithAge(nil).Build()
+	if user.Name != "alice" || user.Age != nil || user.Email != "user@example.com" {
+		t.Errorf("expected overridden fields, got %+v", user)
+	}
+}
+
+func jsonRoundTrip(t *testing.T, v any) any {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
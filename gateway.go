@@ -0,0 +1,127 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+// AWSIntegration is the `x-amazon-apigateway-integration` extension written
+// by OperationBuilder.AWSIntegration, which tells AWS API Gateway where to
+// route the operation.
+type AWSIntegration struct {
+	// Type is the integration type: "http_proxy", "aws_proxy", "http",
+	// "aws" or "mock".
+	Type string `json:"type"`
+
+	// URI is the endpoint of the backend, e.g. `https://api.example.com/users`
+	// or the ARN of a Lambda function. Not needed for mock integrations.
+	URI string `json:"uri,omitempty"`
+
+	// HTTPMethod is the method used to call the backend. Lambda proxy
+	// integrations always use POST.
+	HTTPMethod string `json:"httpMethod,omitempty"`
+
+	// PassthroughBehavior is "when_no_match", "when_no_templates" or "never".
+	PassthroughBehavior string `json:"passthroughBehavior,omitempty"`
+
+	// TimeoutInMillis is the integration timeout, between 50 and 29000.
+	TimeoutInMillis int `json:"timeoutInMillis,omitempty"`
+
+	// ConnectionType is "INTERNET" or "VPC_LINK", with ConnectionID set to
+	// the ID of the VPC link.
+	ConnectionType string `json:"connectionType,omitempty"`
+	ConnectionID   string `json:"connectionId,omitempty"`
+
+	// Credentials is the ARN of the IAM role API Gateway assumes.
+	Credentials string `json:"credentials,omitempty"`
+
+	// RequestParameters maps backend parameters to request parameters, e.g.
+	// `"integration.request.path.id": "method.request.path.id"`.
+	RequestParameters map[string]string `json:"requestParameters,omitempty"`
+
+	// RequestTemplates maps content types to mapping templates, e.g. the
+	// `{"statusCode": 200}` body of a mock integration.
+	RequestTemplates map[string]string `json:"requestTemplates,omitempty"`
+
+	// Responses maps backend status patterns to method responses. The
+	// "default" key matches every response.
+	Responses map[string]*AWSIntegrationResponse `json:"responses,omitempty"`
+}
+
+// AWSIntegrationResponse maps a backend response of an AWSIntegration to a
+// method response.
+type AWSIntegrationResponse struct {
+	// StatusCode is the status code of the method response, e.g. "200".
+	StatusCode string `json:"statusCode"`
+
+	// ResponseTemplates maps content types to mapping templates.
+	ResponseTemplates map[string]string `json:"responseTemplates,omitempty"`
+}
+
+// AWSIntegration routes the operation in AWS API Gateway, written as the
+// `x-amazon-apigateway-integration` extension.
+func (ob *OperationBuilder) AWSIntegration(integration *AWSIntegration) *OperationBuilder {
+	if integration.Type == "" || (integration.URI == "" && integration.Type != "mock") {
+		panic("aws integration needs a type and, unless it is a mock, an uri")
+	}
+
+	return ob.Extension("x-amazon-apigateway-integration", integration)
+}
+
+// GoogleBackend is the `x-google-backend` extension written by
+// OperationBuilder.GoogleBackend, which tells GCP API Gateway and Cloud
+// Endpoints where to route the operation.
+type GoogleBackend struct {
+	// Address is the URL of the backend, e.g. a Cloud Run service.
+	Address string `json:"address"`
+
+	// PathTranslation is "APPEND_PATH_TO_ADDRESS" or "CONSTANT_ADDRESS".
+	PathTranslation string `json:"path_translation,omitempty"`
+
+	// JWTAudience is the audience of the ID token sent to the backend.
+	JWTAudience string `json:"jwt_audience,omitempty"`
+
+	// DisableAuth disables sending an ID token to the backend.
+	DisableAuth bool `json:"disable_auth,omitempty"`
+
+	// Deadline is the timeout of a backend request in seconds.
+	Deadline float64 `json:"deadline,omitempty"`
+
+	// Protocol is "http/1.1" or "h2".
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// GoogleBackend routes the operation in GCP API Gateway, written as the
+// `x-google-backend` extension.
+func (ob *OperationBuilder) GoogleBackend(backend *GoogleBackend) *OperationBuilder {
+	if backend.Address == "" {
+		panic("google backend address must be specified")
+	}
+
+	return ob.Extension("x-google-backend", backend)
+}
+
+// KongPlugin is a `x-kong-plugin-<name>` extension written by
+// OperationBuilder.KongPlugin, which enables a Kong plugin on the route of
+// the operation when importing the spec with decK.
+type KongPlugin struct {
+	// Name of the plugin, e.g. "rate-limiting".
+	Name string `json:"name"`
+
+	// Enabled disables the plugin if false. Plugins are enabled by default.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Config is the configuration of the plugin.
+	Config map[string]any `json:"config,omitempty"`
+}
+
+// KongPlugin enables a Kong plugin on the operation, written as the
+// `x-kong-plugin-<name>` extension. It may be called once per plugin.
+func (ob *OperationBuilder) KongPlugin(plugin *KongPlugin) *OperationBuilder {
+	if plugin.Name == "" {
+		panic("kong plugin name must be specified")
+	}
+
+	return ob.Extension("x-kong-plugin-"+plugin.Name, plugin)
+}
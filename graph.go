@@ -0,0 +1,177 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DependencyGraph describes which component schemas reference which other
+// schemas and which operations use them, e.g. to see the blast radius of a
+// change to a shared model before making it. Operations are identified by
+// their operation ID, or by `METHOD /path` if they have none.
+type DependencyGraph struct {
+	// References maps every schema name to the sorted names of the schemas
+	// it references directly.
+	References map[string][]string
+
+	// Operations maps schema names to the sorted operations referencing them
+	// directly from parameters, request bodies or responses.
+	Operations map[string][]string
+}
+
+// DependencyGraph returns the dependency graph of the component schemas.
+func (o *OpenAPI) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{
+		References: map[string][]string{},
+		Operations: map[string][]string{},
+	}
+
+	if o.Components != nil && o.Components.Schemas != nil {
+		for name, s := range o.Components.Schemas.Map() {
+			g.References[name] = schemaRefs(reflect.ValueOf(s))
+		}
+	}
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		id := op.OperationID
+		if id == "" {
+			id = method + " " + path
+		}
+		for _, name := range schemaRefs(reflect.ValueOf(op)) {
+			g.Operations[name] = append(g.Operations[name], id)
+		}
+	})
+	for _, ids := range g.Operations {
+		sort.Strings(ids)
+	}
+
+	return g
+}
+
+// schemaRefs returns the sorted names of the component schemas referenced
+// by v. References are not followed.
+func schemaRefs(v reflect.Value) []string {
+	seen := map[string]bool{}
+	walkStructs(v, func(v reflect.Value) {
+		if v.Type() != schemaType {
+			return
+		}
+		if ref := v.Addr().Interface().(*Schema).Ref; strings.HasPrefix(ref, "#/components/schemas/") {
+			seen[strings.TrimPrefix(ref, "#/components/schemas/")] = true
+		}
+	})
+
+	return sortedKeys(seen)
+}
+
+// Dependents returns the sorted names of the schemas which reference the
+// schema directly or through other schemas.
+func (g *DependencyGraph) Dependents(name string) []string {
+	dependents := g.dependents(name)
+	delete(dependents, name)
+	return sortedKeys(dependents)
+}
+
+// UsedBy returns the sorted operations which use the schema directly or
+// through other schemas.
+func (g *DependencyGraph) UsedBy(name string) []string {
+	used := map[string]bool{}
+	for dependent := range g.dependents(name) {
+		for _, id := range g.Operations[dependent] {
+			used[id] = true
+		}
+	}
+	return sortedKeys(used)
+}
+
+// dependents returns the schema and every schema referencing it.
+func (g *DependencyGraph) dependents(name string) map[string]bool {
+	found := map[string]bool{name: true}
+	for changed := true; changed; {
+		changed = false
+		for schema, refs := range g.References {
+			if found[schema] {
+				continue
+			}
+			for _, ref := range refs {
+				if found[ref] {
+					found[schema] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return found
+}
+
+// DOT returns the graph in the Graphviz DOT language. Schemas are drawn as
+// boxes and operations as ellipses, with edges pointing from the user to the
+// schema it references.
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n")
+	for _, name := range sortedKeys(g.References) {
+		sb.WriteString("  " + strconv.Quote(name) + " [shape=box];\n")
+	}
+	g.edges(func(from, to string, operation bool) {
+		sb.WriteString("  " + strconv.Quote(from) + " -> " + strconv.Quote(to) + ";\n")
+	})
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, which renders in GitHub
+// and GitLab markdown. Operations are drawn with rounded edges.
+func (g *DependencyGraph) Mermaid() string {
+	ids := map[string]string{}
+	id := func(node string, operation bool) string {
+		if ids[node] == "" {
+			ids[node] = "n" + strconv.Itoa(len(ids))
+		}
+		label := `["` + strings.ReplaceAll(node, `"`, "#quot;") + `"]`
+		if operation {
+			label = `("` + strings.ReplaceAll(node, `"`, "#quot;") + `")`
+		}
+		return ids[node] + label
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	g.edges(func(from, to string, operation bool) {
+		sb.WriteString("  " + id(from, operation) + " --> " + id(to, false) + "\n")
+	})
+	return sb.String()
+}
+
+// edges calls fn for every edge of the graph in a stable order, first the
+// references between schemas and then the operations.
+func (g *DependencyGraph) edges(fn func(from, to string, operation bool)) {
+	for _, name := range sortedKeys(g.References) {
+		for _, ref := range g.References[name] {
+			fn(name, ref, false)
+		}
+	}
+
+	byOperation := map[string][]string{}
+	for name, ids := range g.Operations {
+		for _, id := range ids {
+			byOperation[id] = append(byOperation[id], name)
+		}
+	}
+	for _, id := range sortedKeys(byOperation) {
+		names := byOperation[id]
+		sort.Strings(names)
+		for _, name := range names {
+			fn(id, name, true)
+		}
+	}
+}
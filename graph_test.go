@@ -0,0 +1,58 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type graphAddress struct {
+	City string `json:"city"`
+}
+
+type graphUser struct {
+	Name    string       `json:"name"`
+	Address graphAddress `json:"address"`
+}
+
+type graphOrder struct {
+	Buyer    graphUser      `json:"buyer"`
+	Shipping []graphAddress `json:"shipping"`
+}
+
+func TestDependencyGraph(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/user",
+	}).Response(http.StatusOK).Description("OK").Body(&graphUser{})
+	builder.Register(&openapi.Operation{
+		Method: http.MethodPost,
+		Path:   "/orders",
+	}).Request().Body(&graphOrder{})
+
+	g := builder.OpenAPI().DependencyGraph()
+	if refs := g.References["GraphOrder"]; !reflect.DeepEqual(refs, []string{"GraphAddress", "GraphUser"}) {
+		t.Errorf("unexpected references %v", refs)
+	}
+	if dependents := g.Dependents("GraphAddress"); !reflect.DeepEqual(dependents, []string{"GraphOrder", "GraphUser"}) {
+		t.Errorf("unexpected dependents %v", dependents)
+	}
+	if used := g.UsedBy("GraphAddress"); !reflect.DeepEqual(used, []string{"POST /orders", "getUser"}) {
+		t.Errorf("unexpected operations %v", used)
+	}
+	if used := g.UsedBy("GraphOrder"); !reflect.DeepEqual(used, []string{"POST /orders"}) {
+		t.Errorf("unexpected operations %v", used)
+	}
+
+	if dot := g.DOT(); !strings.Contains(dot, `"GraphUser" -> "GraphAddress";`) || !strings.Contains(dot, `"getUser" -> "GraphUser";`) {
+		t.Errorf("unexpected DOT output %s", dot)
+	}
+	if mermaid := g.Mermaid(); !strings.HasPrefix(mermaid, "flowchart LR\n") || !strings.Contains(mermaid, `("getUser") --> `) {
+		t.Errorf("unexpected Mermaid output %s", mermaid)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GraphQLRequest is the body of a GraphQL-over-HTTP request.
+type GraphQLRequest struct {
+	Query         string         `json:"query" doc:"GraphQL document to execute"`
+	OperationName string         `json:"operationName,omitempty" doc:"Name of the operation to execute if the document contains several"`
+	Variables     map[string]any `json:"variables,omitempty" doc:"Values of the variables of the operation"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse is the body of a GraphQL-over-HTTP response.
+type GraphQLResponse struct {
+	Data       any            `json:"data,omitempty" doc:"Result of the operation"`
+	Errors     []GraphQLError `json:"errors,omitempty" doc:"Errors raised while executing the operation"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLError is an error in a GraphQLResponse.
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty" doc:"Path of the field which raised the error"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is a location in the GraphQL document of a GraphQLError.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQL documents a GraphQL-over-HTTP endpoint at path, typically
+// `/graphql`, so services serving both REST and GraphQL have a single docs
+// page. The endpoint accepts a POST with a GraphQLRequest and responds with
+// a GraphQLResponse, which carries the errors of invalid requests with a 400
+// status. The operation IDs are derived from the path, e.g. `adminGraphql`
+// and `adminGraphqlQuery` for `/admin/graphql`, so several endpoints can be
+// documented.
+func (b *Builder) GraphQL(path string) *GraphQLBuilder {
+	post := b.Register(&Operation{
+		OperationID: graphQLOperationID(path),
+		Method:      http.MethodPost,
+		Path:        path,
+		Summary:     "Execute a GraphQL operation",
+		Tags:        []string{"GraphQL"},
+	})
+	post.Request().Body(GraphQLRequest{})
+	graphQLResponses(post)
+
+	return &GraphQLBuilder{
+		builder: b,
+		path:    path,
+		post:    post,
+	}
+}
+
+// GraphQLBuilder helps document a GraphQL endpoint.
+type GraphQLBuilder struct {
+	builder *Builder
+	path    string
+	post    *OperationBuilder
+	get     *OperationBuilder
+
+	description  string
+	externalDocs *ExternalDocs
+}
+
+// Get also documents GET requests, which pass the request as the query,
+// operationName and variables query params and may only execute queries.
+func (gb *GraphQLBuilder) Get() *GraphQLBuilder {
+	if gb.get != nil {
+		return gb
+	}
+
+	gb.get = gb.builder.Register(&Operation{
+		OperationID: graphQLOperationID(gb.path) + "Query",
+		Method:      http.MethodGet,
+		Path:        gb.path,
+		Summary:     "Execute a GraphQL query",
+		Tags:        []string{"GraphQL"},
+	})
+	request := gb.get.Request()
+	request.QueryParam("query", StringType).Required(true).Description("GraphQL document to execute")
+	request.QueryParam("operationName", StringType).Description("Name of the operation to execute if the document contains several")
+	request.QueryParam("variables", StringType).Description("JSON encoded values of the variables of the operation")
+	graphQLResponses(gb.get)
+	gb.apply()
+
+	return gb
+}
+
+// SDL embeds the schema of the GraphQL API, in the schema definition
+// language, in the description of the endpoint.
+func (gb *GraphQLBuilder) SDL(sdl string) *GraphQLBuilder {
+	gb.description = "```graphql\n" + sdl + "\n```"
+	gb.apply()
+
+	return gb
+}
+
+// SchemaURL links the schema of the GraphQL API, e.g. a `schema.graphql`
+// served next to the endpoint, as the external docs of the endpoint.
+func (gb *GraphQLBuilder) SchemaURL(url string) *GraphQLBuilder {
+	gb.externalDocs = &ExternalDocs{
+		Description: "GraphQL schema",
+		URL:         url,
+	}
+	gb.apply()
+
+	return gb
+}
+
+// Operation returns the builder of the POST operation, e.g. to add security.
+func (gb *GraphQLBuilder) Operation() *OperationBuilder {
+	return gb.post
+}
+
+// QueryOperation returns the builder of the GET operation, or nil if Get was
+// not called.
+func (gb *GraphQLBuilder) QueryOperation() *OperationBuilder {
+	return gb.get
+}
+
+// graphQLOperationID returns the ID of the POST operation of the endpoint at
+// path, e.g. `graphql` for `/graphql` and `adminGraphql` for
+// `/admin/graphql`.
+func graphQLOperationID(path string) string {
+	id := camelCase(strings.NewReplacer("/", " ", "{", "", "}", "").Replace(path))
+	if !strings.HasSuffix(strings.ToLower(id), "graphql") {
+		id += "Graphql"
+	}
+	r, size := utf8.DecodeRuneInString(id)
+	return string(unicode.ToLower(r)) + id[size:]
+}
+
+// apply sets the schema documentation on the operations.
+func (gb *GraphQLBuilder) apply() {
+	for _, ob := range []*OperationBuilder{gb.post, gb.get} {
+		if ob != nil {
+			ob.op.Description = gb.description
+			ob.op.ExternalDocs = gb.externalDocs
+		}
+	}
+}
+
+func graphQLResponses(ob *OperationBuilder) {
+	ob.Response(http.StatusOK).
+		Description("Result of the operation, including field errors").
+		Body(GraphQLResponse{})
+	ob.Response(http.StatusBadRequest).
+		Description("The request is not a valid GraphQL request").
+		Body(GraphQLResponse{})
+}
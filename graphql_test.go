@@ -0,0 +1,61 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestGraphQL(t *testing.T) {
+	builder := openapi.New("title", "version").BearerAuth()
+	builder.GraphQL("/graphql").
+		SDL("type Query { user(id: ID!): User }").
+		Get().
+		SchemaURL("https://api.example.com/schema.graphql").
+		Operation().Security("BearerAuth", nil)
+
+	item := builder.OpenAPI().Paths["/graphql"]
+	if item.Post == nil || item.Get == nil {
+		t.Fatalf("expected POST and GET operations, got %v", item)
+	}
+	if body := item.Post.RequestBody.Content["application/json"]; body == nil || body.Schema.Ref != "#/components/schemas/GraphQLRequest" {
+		t.Errorf("unexpected request body %v", item.Post.RequestBody)
+	}
+	if resp := item.Post.Responses["400"]; resp == nil || resp.Content["application/json"].Schema.Ref != "#/components/schemas/GraphQLResponse" {
+		t.Errorf("unexpected responses %v", item.Post.Responses)
+	}
+	if len(item.Get.Parameters) != 3 || item.Get.Parameters[0].Name != "query" || !item.Get.Parameters[0].Required {
+		t.Errorf("unexpected GET params %v", item.Get.Parameters)
+	}
+	for _, op := range []*openapi.Operation{item.Post, item.Get} {
+		if op.Description != "```graphql\ntype Query { user(id: ID!): User }\n```" || op.ExternalDocs.URL != "https://api.example.com/schema.graphql" {
+			t.Errorf("unexpected schema docs %q %v", op.Description, op.ExternalDocs)
+		}
+	}
+	if len(item.Post.Security) != 1 || item.Get.Security != nil {
+		t.Errorf("expected security on the POST operation only")
+	}
+
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("expected a valid document, got %v", errs)
+	}
+}
+
+func TestGraphQLOperationIDs(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.GraphQL("/graphql").Get()
+	builder.GraphQL("/admin/graphql").Get()
+
+	o := builder.OpenAPI()
+	for path, expected := range map[string][2]string{
+		"/graphql":       {"graphql", "graphqlQuery"},
+		"/admin/graphql": {"adminGraphql", "adminGraphqlQuery"},
+	} {
+		if post, get := o.Paths[path].Post.OperationID, o.Paths[path].Get.OperationID; post != expected[0] || get != expected[1] {
+			t.Errorf("unexpected operation IDs for %s: %s %s", path, post, get)
+		}
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("expected a valid document, got %v", errs)
+	}
+}
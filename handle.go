@@ -0,0 +1,280 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+var paramLocations = []string{"path", "query", "header", "cookie"}
+
+// boundField is a field of an input or output struct of Handle bound to a
+// parameter or header.
+type boundField struct {
+	index  []int
+	in     string
+	name   string
+	schema *Schema
+}
+
+// Handle registers op on the builder and returns a handler which binds it to
+// fn. The request parameters and body are documented from the fields of the
+// input struct I and the response from the fields of the output struct O,
+// so the spec and the implementation can't drift apart.
+//
+// Fields of I tagged with `path`, `query`, `header` or `cookie` are
+// parameters, named by the tag, and a field named Body is the JSON request
+// body. Parameters use the tags of the schema generator, e.g. `doc` or
+// `minimum`, and query, header and cookie parameters are optional unless
+// tagged `required:"true"`.
+//
+// Fields of O tagged with `header` are response headers and a field named
+// Body is the JSON response body. An int field named Status sets the
+// response status, which defaults to the `default` tag of the field, or to
+// 200 if O has a body and 204 otherwise. A nil O responds with that status
+// and no body.
+//
+//	type GetUserInput struct {
+//		ID string `path:"id"`
+//	}
+//
+//	type GetUserOutput struct {
+//		ETag string `header:"ETag"`
+//		Body User
+//	}
+//
+//	mux.Handle("/users/", openapi.Handle(builder, &openapi.Operation{
+//		OperationID: "getUser",
+//		Method:      http.MethodGet,
+//		Path:        "/users/{id}",
+//	}, func(ctx context.Context, in *GetUserInput) (*GetUserOutput, error) {
+//		// ...
+//	}))
+//
+// Requests are validated like Middleware does before fn is called. The
+// handler matches the request path against the path of op to read path
+// parameters, so mount it at the same path. Fields of the body tagged
+// `readOnly:"true"` are ignored. Errors returned by fn are turned
+// into a response by Builder.MapError, e.g. return a StatusError for a 404.
+func Handle[I, O any](b *Builder, op *Operation, fn func(ctx context.Context, in *I) (*O, error)) http.Handler {
+	inType := reflect.TypeOf((*I)(nil)).Elem()
+	outType := reflect.TypeOf((*O)(nil)).Elem()
+	if inType.Kind() != reflect.Struct || outType.Kind() != reflect.Struct {
+		panic("input and output of " + op.OperationID + " must be structs")
+	}
+
+	ob := b.Register(op)
+	registry := b.openAPI.Components.Schemas
+
+	var params []boundField
+	inBody, hasInBody := inType.FieldByName("Body")
+	for i := 0; i < inType.NumField(); i++ {
+		f := inType.Field(i)
+		for _, in := range paramLocations {
+			name := f.Tag.Get(in)
+			if name == "" {
+				continue
+			}
+
+			schema := SchemaFromField(registry, f, op.OperationID+name)
+			op.Parameters = append(op.Parameters, &Param{
+				Name:        name,
+				In:          in,
+				Description: f.Tag.Get("doc"),
+				Required:    in == "path" || boolTag(f, "required"),
+				Schema:      schema,
+			})
+			params = append(params, boundField{index: f.Index, in: in, name: name, schema: schema})
+		}
+	}
+	if hasInBody {
+		ob.Request().Body(reflect.Zero(inBody.Type).Interface())
+	}
+
+	var headers []boundField
+	outBody, hasOutBody := outType.FieldByName("Body")
+	status := http.StatusNoContent
+	if hasOutBody {
+		status = http.StatusOK
+	}
+	statusField, hasStatus := outType.FieldByName("Status")
+	if hasStatus {
+		if statusField.Type.Kind() != reflect.Int {
+			panic("status of " + op.OperationID + " must be an int")
+		}
+		if v := statusField.Tag.Get("default"); v != "" {
+			var err error
+			if status, err = strconv.Atoi(v); err != nil {
+				panic("invalid default status " + v + " of " + op.OperationID)
+			}
+		}
+	}
+
+	rb := ob.Response(status).Description(http.StatusText(status))
+	if hasOutBody {
+		rb.Body(reflect.Zero(outBody.Type).Interface())
+	}
+	for i := 0; i < outType.NumField(); i++ {
+		f := outType.Field(i)
+		if name := f.Tag.Get("header"); name != "" {
+			rb.Header(name, reflect.Zero(f.Type).Interface()).Description(f.Tag.Get("doc"))
+			headers = append(headers, boundField{index: f.Index, in: "header", name: name})
+		}
+	}
+
+	item := &PathItem{}
+	item.SetOperation(op)
+	matcher := NewMatcher(&OpenAPI{Paths: map[string]*PathItem{op.Path: item}})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pathParams := matcher.Match(r.Method, r.URL.Path)
+
+		res := &ValidateResult{}
+		b.openAPI.validateParams(r, op, pathParams, res)
+		errStatus := b.openAPI.validateBody(r, op, res)
+		if len(res.Errors) > 0 {
+			writeValidationErrors(w, errStatus, res.Errors)
+			return
+		}
+
+		input := new(I)
+		v := reflect.ValueOf(input).Elem()
+		query := r.URL.Query()
+		for _, p := range params {
+			values := requestParamValues(r, query, pathParams, p.in, p.name)
+			if len(values) == 0 {
+				continue
+			}
+			schema := p.schema
+			for schema.Ref != "" {
+				schema = registry.SchemaFromRef(schema.Ref)
+			}
+			value, _ := paramValue(schema, values, registry)
+			if err := assign(v.FieldByIndex(p.index), value); err != nil {
+				pb := NewPathBuffer([]byte{}, 0)
+				pb.Push(p.in)
+				pb.Push(p.name)
+				res.Add(pb, values, err.Error())
+			}
+		}
+		if hasInBody && r.Body != nil && isJSON(mediaType(r.Header.Get("Content-Type"))) {
+			err := json.NewDecoder(r.Body).Decode(v.FieldByIndex(inBody.Index).Addr().Interface())
+			if err != nil && err != io.EOF {
+				pb := NewPathBuffer([]byte{}, 0)
+				pb.Push("body")
+				res.Add(pb, nil, err.Error())
+			}
+			clearReadOnly(v.FieldByIndex(inBody.Index))
+		}
+		if len(res.Errors) > 0 {
+			writeValidationErrors(w, http.StatusUnprocessableEntity, res.Errors)
+			return
+		}
+
+		mapError := b.mapError
+		if mapError == nil {
+			mapError = MapError
+		}
+
+		output, err := fn(r.Context(), input)
+		if err != nil {
+			errStatus, body := mapError(err)
+			writeError(w, errStatus, body)
+			return
+		}
+		if output == nil {
+			// A nil output has no headers or body.
+			w.WriteHeader(status)
+			return
+		}
+
+		out := reflect.ValueOf(output).Elem()
+		for _, h := range headers {
+			if field := out.FieldByIndex(h.index); !field.IsZero() {
+				w.Header().Set(h.name, headerValue(field))
+			}
+		}
+
+		responseStatus := status
+		if hasStatus {
+			if s := int(out.FieldByIndex(statusField.Index).Int()); s != 0 {
+				responseStatus = s
+			}
+		}
+
+		if !hasOutBody {
+			w.WriteHeader(responseStatus)
+			return
+		}
+		// Encode before writing the header, so encoding errors can still be
+		// reported.
+		body, err := json.Marshal(out.FieldByIndex(outBody.Index).Interface())
+		if err != nil {
+			errStatus, errBody := mapError(err)
+			writeError(w, errStatus, errBody)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseStatus)
+		w.Write(append(body, '\n'))
+	})
+}
+
+// clearReadOnly zeroes the fields tagged `readOnly:"true"`, including those
+// of nested structs, so values sent back by clients are ignored.
+func clearReadOnly(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			clearReadOnly(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			clearReadOnly(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if boolTag(f, "readOnly") {
+				v.Field(i).Set(reflect.Zero(f.Type))
+			} else {
+				clearReadOnly(v.Field(i))
+			}
+		}
+	}
+}
+
+// assign sets the field to a parameter value converted by paramValue.
+// Values are converted through JSON so named types, pointers and types
+// such as time.Time work as they do for bodies.
+func assign(field reflect.Value, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, field.Addr().Interface())
+}
+
+// headerValue formats the value of a response header field.
+func headerValue(field reflect.Value) string {
+	if field.Kind() == reflect.String {
+		return field.String()
+	}
+	b, _ := json.Marshal(field.Interface())
+	if s, err := strconv.Unquote(string(b)); err == nil {
+		return s
+	}
+	return string(b)
+}
@@ -0,0 +1,235 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type UpdateUserRequest struct {
+	Name string `json:"name" minLength:"1"`
+}
+
+type UpdateUserResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type UpdateUserInput struct {
+	ID     int    `path:"id"`
+	DryRun bool   `query:"dryRun"`
+	Trace  string `header:"X-Trace-Id"`
+	Body   UpdateUserRequest
+}
+
+type UpdateUserOutput struct {
+	Status int    `default:"200"`
+	ETag   string `header:"ETag"`
+	Body   UpdateUserResponse
+}
+
+func TestHandle(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	var got *UpdateUserInput
+	handler := openapi.Handle(builder, &openapi.Operation{
+		OperationID: "updateUser",
+		Method:      http.MethodPut,
+		Path:        "/users/{id}",
+	}, func(ctx context.Context, in *UpdateUserInput) (*UpdateUserOutput, error) {
+		got = in
+		if in.Body.Name == "fail" {
+			return nil, errors.New("secret")
+		}
+
+		out := &UpdateUserOutput{ETag: "v1"}
+		out.Body.ID = in.ID
+		out.Body.Name = in.Body.Name
+		return out, nil
+	})
+
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Fatalf("expected a valid spec, got %v", errs)
+	}
+
+	op := builder.OpenAPI().Paths["/users/{id}"].Put
+	if len(op.Parameters) != 3 || !op.Parameters[0].Required || op.Parameters[1].Required {
+		t.Errorf("unexpected parameters %v", op.Parameters)
+	}
+	if op.RequestBody == nil || op.Responses["200"] == nil || op.Responses["200"].Headers["ETag"] == nil {
+		t.Errorf("unexpected operation %v", op)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/users/42?dryRun=true", strings.NewReader(`{"name":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trace-Id", "abc")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != "v1" {
+		t.Fatalf("unexpected response %d %v: %s", rec.Code, rec.Header(), rec.Body)
+	}
+	if got.ID != 42 || !got.DryRun || got.Trace != "abc" {
+		t.Errorf("unexpected input %+v", got)
+	}
+	var body map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["id"] != float64(42) || body["name"] != "alice" {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/users/abc", strings.NewReader(`{"name":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for an invalid request, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name":"fail"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("expected 500 without the error message, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+type GetUserInput struct {
+	ID int `path:"id"`
+}
+
+type GetUserOutput struct {
+	Body UpdateUserResponse
+}
+
+func TestHandleErrors(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	errNotFound := errors.New("no rows")
+	handler := openapi.Handle(builder, &openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	}, func(ctx context.Context, in *GetUserInput) (*GetUserOutput, error) {
+		switch in.ID {
+		case 1:
+			err := openapi.NewError(http.StatusNotFound, "user not found", &openapi.ErrorDetail{Location: "path.id", Value: in.ID})
+			err.Err = errNotFound
+			return nil, fmt.Errorf("get user: %w", err)
+		case 2:
+			return nil, fmt.Errorf("get user: %w", &openapi.ErrorDetail{Message: "user is archived"})
+		}
+		return nil, errNotFound
+	})
+
+	for id, expected := range map[string]string{
+		"1": `{"status":404,"title":"Not Found","detail":"user not found","errors":[{"location":"path.id","value":1}]}`,
+		"2": `{"status":422,"title":"Unprocessable Entity","errors":[{"message":"user is archived"}]}`,
+		"3": `{"status":500,"title":"Internal Server Error"}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/"+id, nil))
+		if body := strings.TrimSpace(rec.Body.String()); body != expected {
+			t.Errorf("unexpected body for user %s: %s", id, body)
+		}
+	}
+
+	var statusErr *openapi.StatusError
+	if err := fmt.Errorf("wrapped: %w", &openapi.StatusError{Status: http.StatusConflict, Err: errNotFound}); !errors.As(err, &statusErr) || !errors.Is(err, errNotFound) || err.Error() != "wrapped: Conflict: no rows" {
+		t.Errorf("unexpected wrapping of %v", err)
+	}
+
+	builder.MapError(func(err error) (int, any) {
+		if errors.Is(err, errNotFound) {
+			return http.StatusNotFound, map[string]string{"error": "missing"}
+		}
+		return openapi.MapError(err)
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/3", nil))
+	if rec.Code != http.StatusNotFound || strings.TrimSpace(rec.Body.String()) != `{"error":"missing"}` {
+		t.Errorf("unexpected custom mapping %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandleNilOutput(t *testing.T) {
+	builder := openapi.New("title", "version")
+	handler := openapi.Handle(builder, &openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	}, func(ctx context.Context, in *GetUserInput) (*GetUserOutput, error) {
+		return nil, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("expected the default status without a body, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+type UnencodableOutput struct {
+	Body map[string]any
+}
+
+func TestHandleEncodingError(t *testing.T) {
+	builder := openapi.New("title", "version")
+	handler := openapi.Handle(builder, &openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	}, func(ctx context.Context, in *GetUserInput) (*UnencodableOutput, error) {
+		return &UnencodableOutput{Body: map[string]any{"callback": func() {}}}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for an unencodable body, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+type CreateNoteRequest struct {
+	ID   int    `json:"id" readOnly:"true"`
+	Text string `json:"text"`
+}
+
+type CreateNoteInput struct {
+	Body CreateNoteRequest
+}
+
+type CreateNoteOutput struct {
+	Status int `default:"201"`
+	Body   CreateNoteRequest
+}
+
+func TestHandleReadOnly(t *testing.T) {
+	builder := openapi.New("title", "version")
+	handler := openapi.Handle(builder, &openapi.Operation{
+		OperationID: "createNote",
+		Method:      http.MethodPost,
+		Path:        "/notes",
+	}, func(ctx context.Context, in *CreateNoteInput) (*CreateNoteOutput, error) {
+		if in.Body.ID != 0 {
+			return nil, openapi.NewError(http.StatusBadRequest, "read only id was bound")
+		}
+		return &CreateNoteOutput{Body: CreateNoteRequest{ID: 7, Text: in.Body.Text}}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader(`{"id":3,"text":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || strings.TrimSpace(rec.Body.String()) != `{"id":7,"text":"hi"}` {
+		t.Errorf("expected the read only id to be ignored, got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "reflect"
+
+// responseHeader is a header added to every response by Build, see
+// Builder.GlobalResponseHeader and OperationBuilder.ResponseHeader.
+type responseHeader struct {
+	// location is the operation the header applies to, or empty for all.
+	location    string
+	name        string
+	schema      *Schema
+	description string
+}
+
+// GlobalResponseHeader adds a header to every response of the document when
+// calling Build, e.g. `X-Request-ID` or `traceparent`. Responses which
+// already define the header, or which reference a component response, are
+// left alone; component responses get the header themselves.
+func (b *Builder) GlobalResponseHeader(name string, f any, description string) *Builder {
+	b.responseHeaders = append(b.responseHeaders, b.responseHeader("", name, f, description))
+
+	return b
+}
+
+// ResponseHeader adds a header to every response of the operation when
+// calling Build, including responses added after this call.
+func (ob *OperationBuilder) ResponseHeader(name string, f any, description string) *OperationBuilder {
+	location := operationLocation(ob.op.Path, ob.op.Method)
+	ob.builder.responseHeaders = append(ob.builder.responseHeaders, ob.builder.responseHeader(location, name, f, description))
+
+	return ob
+}
+
+func (b *Builder) responseHeader(location, name string, f any, description string) *responseHeader {
+	if name == "" {
+		panic("response header name must be specified")
+	}
+
+	return &responseHeader{
+		location:    location,
+		name:        name,
+		schema:      b.openAPI.Components.Schemas.Schema(reflect.TypeOf(f), true, ""),
+		description: description,
+	}
+}
+
+// addResponseHeaders adds the response headers to the responses of o.
+func addResponseHeaders(o *OpenAPI, headers []*responseHeader) {
+	add := func(response *Response, location string) {
+		if response == nil || response.Ref != "" {
+			return
+		}
+		for _, header := range headers {
+			if header.location != "" && header.location != location {
+				continue
+			}
+			if _, ok := response.Headers[header.name]; ok {
+				continue
+			}
+			if response.Headers == nil {
+				response.Headers = map[string]*Param{}
+			}
+			response.Headers[header.name] = &Param{
+				Description: header.description,
+				Schema:      header.schema,
+			}
+		}
+	}
+
+	o.WalkOperations(func(path, method string, op *Operation) {
+		location := operationLocation(path, method)
+		for _, response := range op.Responses {
+			add(response, location)
+		}
+	})
+
+	if o.Components == nil {
+		return
+	}
+	for _, response := range o.Components.Responses {
+		add(response, "")
+	}
+}
@@ -0,0 +1,45 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.GlobalResponseHeader("X-Request-ID", openapi.StringType, "Request ID")
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	getUser.ResponseHeader("X-RateLimit-Remaining", openapi.IntType, "Remaining requests")
+	getUser.Response(http.StatusOK).Description("OK").Header("X-Request-ID", openapi.StringType).Description("Custom")
+
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	createUser.Response(http.StatusCreated).Description("Created")
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := o.Paths["/users"].Get.Responses["200"].Headers
+	if headers["X-Request-ID"].Description != "Custom" || headers["X-RateLimit-Remaining"] == nil {
+		t.Errorf("unexpected getUser headers %v", headers)
+	}
+	headers = o.Paths["/users"].Post.Responses["201"].Headers
+	if headers["X-Request-ID"].Description != "Request ID" || headers["X-RateLimit-Remaining"] != nil {
+		t.Errorf("unexpected createUser headers %v", headers)
+	}
+	if builder.OpenAPI().Paths["/users"].Post.Responses["201"].Headers != nil {
+		t.Errorf("expected the builder's document to be left untouched")
+	}
+}
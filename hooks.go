@@ -0,0 +1,75 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Hooks are notified of what the spec machinery is doing, so services can
+// wire them to their logger or tracer. Every hook is optional.
+//
+//	builder.Hooks(&openapi.Hooks{
+//		SpecServed: func(r *http.Request, format string, size int) {
+//			slog.Info("spec served", "format", format, "bytes", size)
+//		},
+//	})
+type Hooks struct {
+	// OperationRegistered is called when an operation is added to the
+	// document, see OpenAPI.AddOperation.
+	OperationRegistered func(op *Operation)
+
+	// SchemaGenerated is called when the registry generates the schema of a
+	// named type. Only the first use of a type generates its schema.
+	SchemaGenerated func(name string, t reflect.Type, schema *Schema)
+
+	// SpecServed is called when SpecHandler responds with the document in the
+	// given format, `json` or `yaml`, of size bytes.
+	SpecServed func(r *http.Request, format string, size int)
+
+	// ValidationFailed is called when Builder.Validate or Builder.Build find
+	// errors in the document.
+	ValidationFailed func(errs []error)
+}
+
+// Hooks sets the hooks of the document and its registry. Hooks only see
+// events which happen afterwards, so call this before registering
+// operations.
+func (b *Builder) Hooks(hooks *Hooks) *Builder {
+	b.openAPI.Hooks = hooks
+
+	if r, ok := b.openAPI.Components.Schemas.(*mapRegistry); ok {
+		r.hooks = hooks
+	}
+
+	return b
+}
+
+func (h *Hooks) operationRegistered(op *Operation) {
+	if h != nil && h.OperationRegistered != nil {
+		h.OperationRegistered(op)
+	}
+}
+
+func (h *Hooks) schemaGenerated(name string, t reflect.Type, schema *Schema) {
+	if h != nil && h.SchemaGenerated != nil {
+		h.SchemaGenerated(name, t, schema)
+	}
+}
+
+func (h *Hooks) specServed(r *http.Request, format string, size int) {
+	if h != nil && h.SpecServed != nil {
+		h.SpecServed(r, format, size)
+	}
+}
+
+func (h *Hooks) validationFailed(errs []error) {
+	if h != nil && h.ValidationFailed != nil && len(errs) > 0 {
+		h.ValidationFailed(errs)
+	}
+}
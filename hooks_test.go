@@ -0,0 +1,54 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type HookedUser struct {
+	Name string `json:"name"`
+}
+
+func TestHooks(t *testing.T) {
+	var events []string
+	builder := openapi.New("title", "version").Hooks(&openapi.Hooks{
+		OperationRegistered: func(op *openapi.Operation) {
+			events = append(events, "operation "+op.OperationID)
+		},
+		SchemaGenerated: func(name string, t reflect.Type, schema *openapi.Schema) {
+			events = append(events, "schema "+name)
+		},
+		SpecServed: func(r *http.Request, format string, size int) {
+			events = append(events, "served "+format)
+		},
+		ValidationFailed: func(errs []error) {
+			events = append(events, "validation failed")
+		},
+	})
+
+	op := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	})
+	op.Response(http.StatusOK).Body(HookedUser{})
+	op.Response(http.StatusCreated).Body(HookedUser{})
+
+	builder.Validate()
+
+	handler := openapi.SpecHandler(builder.OpenAPI())
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi?format=yaml", nil))
+	if w.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+
+	expected := []string{"operation getUser", "schema HookedUser", "validation failed", "served yaml"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+}
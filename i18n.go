@@ -0,0 +1,84 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Catalog holds translated messages by locale and then by key, e.g.
+// `catalog["de"]["Creates a user"]`. Keys are either the untranslated text
+// itself or the value of a `docKey` struct tag.
+type Catalog map[string]map[string]string
+
+// Lookup returns the message for key in the given locale. If the locale has a
+// region, e.g. `de-AT`, the base language `de` is used as a fallback.
+func (c Catalog) Lookup(locale, key string) (string, bool) {
+	for locale != "" {
+		if msg, ok := c[locale][key]; ok {
+			return msg, true
+		}
+
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+
+	return "", false
+}
+
+// Translations adds messages for a locale to the catalog used by
+// `OpenAPI.Localize`. Messages are keyed by the untranslated text or by the
+// `docKey` struct tag of a field.
+func (b *Builder) Translations(locale string, messages map[string]string) *Builder {
+	if b.openAPI.Catalog == nil {
+		b.openAPI.Catalog = Catalog{}
+	}
+
+	if b.openAPI.Catalog[locale] == nil {
+		b.openAPI.Catalog[locale] = map[string]string{}
+	}
+
+	for key, msg := range messages {
+		b.openAPI.Catalog[locale][key] = msg
+	}
+
+	return b
+}
+
+// Localize returns a copy of the OpenAPI with every title, summary and
+// description translated into locale using the catalog. Text without a
+// translation is left as is.
+func (o *OpenAPI) Localize(locale string) *OpenAPI {
+	localized := o.Clone()
+
+	walkStructs(reflect.ValueOf(localized), func(v reflect.Value) {
+		for _, name := range []string{"Title", "Summary", "Description"} {
+			f := v.FieldByName(name)
+			if f.Kind() != reflect.String {
+				continue
+			}
+
+			key := f.String()
+			if s, ok := v.Addr().Interface().(*Schema); ok && name == "Description" && s.DescriptionKey != "" {
+				key = s.DescriptionKey
+			}
+			if key == "" {
+				continue
+			}
+
+			if msg, ok := o.Catalog.Lookup(locale, key); ok {
+				f.SetString(msg)
+			}
+		}
+	})
+
+	return localized
+}
@@ -0,0 +1,51 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type LocalizedUser struct {
+	Name string `json:"name" doc:"The name of the user"`
+	Bio  string `json:"bio" docKey:"user.bio"`
+}
+
+func TestLocalize(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+		Summary:     "Creates a user",
+	}).Request().Body(LocalizedUser{})
+
+	builder.Translations("de", map[string]string{
+		"Creates a user":       "Erstellt einen Benutzer",
+		"The name of the user": "Der Name des Benutzers",
+		"user.bio":             "Die Biografie des Benutzers",
+	})
+
+	o := builder.OpenAPI()
+	de := o.Localize("de-AT")
+
+	if summary := de.Paths["/users"].Post.Summary; summary != "Erstellt einen Benutzer" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+
+	user := de.Components.Schemas.Map()["LocalizedUser"]
+	if desc := user.Properties["name"].Description; desc != "Der Name des Benutzers" {
+		t.Errorf("unexpected name description %q", desc)
+	}
+	if desc := user.Properties["bio"].Description; desc != "Die Biografie des Benutzers" {
+		t.Errorf("unexpected bio description %q", desc)
+	}
+
+	if summary := o.Paths["/users"].Post.Summary; summary != "Creates a user" {
+		t.Errorf("expected original to be untouched, got %q", summary)
+	}
+	if desc := o.Components.Schemas.Map()["LocalizedUser"].Properties["name"].Description; desc != "The name of the user" {
+		t.Errorf("expected original schema to be untouched, got %q", desc)
+	}
+}
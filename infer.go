@@ -0,0 +1,321 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var idSegmentRe = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// undocumentedOperation returns the placeholder operation used to collect
+// samples for a route which is not in the document, along with the key to
+// pass to addUndocumented. Segments which look like IDs are turned into path
+// parameters, e.g. `/users/123` becomes `/users/{userId}`.
+func (rec *Recorder) undocumentedOperation(method, path string) (*Operation, map[string]string, string) {
+	params := map[string]string{}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if i == 0 || !idSegmentRe.MatchString(segment) {
+			continue
+		}
+
+		name := "id"
+		if i > 1 && !strings.HasPrefix(segments[i-1], "{") {
+			name = strings.TrimSuffix(segments[i-1], "s") + "Id"
+		}
+		for n := 2; params[name] != ""; n++ {
+			name = strings.TrimRight(name, "0123456789") + strconv.Itoa(n)
+		}
+
+		params[name] = segment
+		segments[i] = "{" + name + "}"
+	}
+
+	template := strings.Join(segments, "/")
+	key := method + " " + template
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	op := rec.undocumented[key]
+	if op == nil {
+		op = &Operation{Method: method, Path: template}
+	}
+
+	return op, params, key
+}
+
+// addUndocumented records the placeholder operation of an undocumented route
+// once it has been served, returning the operation recorded for the key or
+// nil if too many routes are recorded already.
+func (rec *Recorder) addUndocumented(key string, op *Operation) *Operation {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if existing := rec.undocumented[key]; existing != nil {
+		return existing
+	}
+	if len(rec.undocumented) >= maxUndocumentedRoutes {
+		return nil
+	}
+	rec.undocumented[key] = op
+	return op
+}
+
+// Draft returns a new document with an operation for every undocumented route
+// which was recorded while Infer was enabled. Parameters and JSON schemas are
+// inferred from the recorded samples and are meant as a starting point to be
+// reviewed, not as a finished spec.
+func (rec *Recorder) Draft(title, version string) *OpenAPI {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	keys := make([]string, 0, len(rec.undocumented))
+	for key := range rec.undocumented {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	draft := New(title, version).OpenAPI()
+	for _, key := range keys {
+		placeholder := rec.undocumented[key]
+		samples := rec.samples[placeholder]
+		if len(samples) == 0 {
+			continue
+		}
+
+		op := &Operation{
+			OperationID: inferOperationID(placeholder.Method, placeholder.Path),
+			Method:      placeholder.Method,
+			Path:        placeholder.Path,
+			Summary:     "Inferred from " + strconv.Itoa(len(samples)) + " requests",
+			Responses:   map[string]*Response{},
+		}
+
+		for _, match := range pathTemplateRe.FindAllStringSubmatch(op.Path, -1) {
+			var values []string
+			for _, s := range samples {
+				values = append(values, s.PathParams[match[1]])
+			}
+			op.Parameters = append(op.Parameters, &Param{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   inferParamSchema(values),
+			})
+		}
+
+		query := map[string][]string{}
+		for _, s := range samples {
+			for name, values := range s.Query {
+				query[name] = append(query[name], values...)
+			}
+		}
+		names := make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			op.Parameters = append(op.Parameters, &Param{
+				Name:   name,
+				In:     "query",
+				Schema: inferParamSchema(query[name]),
+			})
+		}
+
+		for _, s := range samples {
+			if s.RequestBody != nil {
+				if op.RequestBody == nil {
+					op.RequestBody = &RequestBody{Content: map[string]*MediaType{}}
+				}
+				mt := op.RequestBody.Content[s.RequestContentType]
+				if mt == nil {
+					mt = &MediaType{}
+					op.RequestBody.Content[s.RequestContentType] = mt
+				}
+				mt.Schema = mergeSchemas(mt.Schema, inferSchema(s.RequestBody))
+			}
+
+			status := strconv.Itoa(s.Status)
+			resp := op.Responses[status]
+			if resp == nil {
+				resp = &Response{Description: http.StatusText(s.Status)}
+				op.Responses[status] = resp
+			}
+			if s.ResponseBody != nil {
+				if resp.Content == nil {
+					resp.Content = map[string]*MediaType{}
+				}
+				mt := resp.Content[s.ResponseContentType]
+				if mt == nil {
+					mt = &MediaType{}
+					resp.Content[s.ResponseContentType] = mt
+				}
+				mt.Schema = mergeSchemas(mt.Schema, inferSchema(s.ResponseBody))
+			}
+		}
+
+		draft.AddOperation(op)
+	}
+
+	return draft
+}
+
+// inferOperationID returns an operation ID such as `get-users-by-userId`.
+func inferOperationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		switch {
+		case segment == "":
+		case strings.HasPrefix(segment, "{"):
+			parts = append(parts, "by", strings.Trim(segment, "{}"))
+		default:
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// inferParamSchema returns the narrowest schema matching all of the values.
+func inferParamSchema(values []string) *Schema {
+	if len(values) == 0 {
+		return &Schema{Type: TypeString}
+	}
+
+	// Every value is checked against each type, from the narrowest one.
+	types := []struct {
+		t     string
+		parse func(v string) error
+	}{
+		{TypeInteger, func(v string) error {
+			_, err := strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+		{TypeNumber, func(v string) error {
+			_, err := strconv.ParseFloat(v, 64)
+			return err
+		}},
+		{TypeBoolean, func(v string) error {
+			_, err := strconv.ParseBool(v)
+			return err
+		}},
+	}
+	for _, candidate := range types {
+		matches := true
+		for _, v := range values {
+			if candidate.parse(v) != nil {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return &Schema{Type: candidate.t}
+		}
+	}
+	return &Schema{Type: TypeString}
+}
+
+// inferSchema returns a schema for a decoded JSON value.
+func inferSchema(v any) *Schema {
+	switch value := v.(type) {
+	case nil:
+		return &Schema{Type: "null"}
+	case bool:
+		return &Schema{Type: TypeBoolean}
+	case float64:
+		if value == float64(int64(value)) {
+			return &Schema{Type: TypeInteger}
+		}
+		return &Schema{Type: TypeNumber}
+	case string:
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return &Schema{Type: TypeString, Format: "date-time"}
+		}
+		return &Schema{Type: TypeString}
+	case []any:
+		s := &Schema{Type: TypeArray}
+		for _, item := range value {
+			s.Items = mergeSchemas(s.Items, inferSchema(item))
+		}
+		if s.Items == nil {
+			s.Items = &Schema{}
+		}
+		return s
+	case map[string]any:
+		s := &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+		for name, prop := range value {
+			s.Properties[name] = inferSchema(prop)
+			s.Required = append(s.Required, name)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+
+	return &Schema{}
+}
+
+// mergeSchemas combines two inferred schemas so that both values validate.
+// Object properties are merged and only those present in both are required.
+func mergeSchemas(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+
+	if a.Type == "null" {
+		a, b = b, a
+	}
+	if b.Type == "null" {
+		merged := *a
+		merged.Nullable = a.Type != "null" && a.Type != ""
+		return &merged
+	}
+
+	nullable := a.Nullable || b.Nullable
+	if a.Type != b.Type {
+		if (a.Type == TypeInteger || a.Type == TypeNumber) && (b.Type == TypeInteger || b.Type == TypeNumber) {
+			return &Schema{Type: TypeNumber, Nullable: nullable}
+		}
+		return &Schema{}
+	}
+
+	merged := &Schema{Type: a.Type, Nullable: nullable}
+	if a.Format == b.Format {
+		merged.Format = a.Format
+	}
+
+	switch a.Type {
+	case TypeArray:
+		merged.Items = mergeSchemas(a.Items, b.Items)
+	case TypeObject:
+		merged.Properties = map[string]*Schema{}
+		for name, prop := range a.Properties {
+			merged.Properties[name] = prop
+		}
+		for name, prop := range b.Properties {
+			merged.Properties[name] = mergeSchemas(merged.Properties[name], prop)
+		}
+
+		required := map[string]bool{}
+		for _, name := range a.Required {
+			required[name] = true
+		}
+		for _, name := range b.Required {
+			if required[name] {
+				merged.Required = append(merged.Required, name)
+			}
+		}
+	}
+
+	return merged
+}
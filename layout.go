@@ -0,0 +1,279 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Order is the order in which paths or component schemas are emitted.
+type Order int
+
+const (
+	// OrderLexical sorts by path or schema name. This is the default.
+	OrderLexical Order = iota
+
+	// OrderRegistration keeps the order in which paths were added or schemas
+	// were generated.
+	OrderRegistration
+
+	// OrderTag groups paths by the first tag of their first operation, in the
+	// order the tags are declared in the document's `tags`. Undeclared tags
+	// follow alphabetically and untagged paths come last. Within a group
+	// paths are sorted lexically. It only applies to paths.
+	OrderTag
+)
+
+// Layout controls the order of the emitted JSON and YAML, as different
+// reviewers and diff tools prefer different layouts. Orders other than
+// OrderLexical don't survive DowngradeYAML.
+type Layout struct {
+	// Paths is the order of the paths and webhooks.
+	Paths Order
+
+	// Schemas is the order of the component schemas. OrderTag is treated as
+	// OrderLexical.
+	Schemas Order
+
+	// Responses is the order of the responses of operations, by status code
+	// for OrderLexical. OrderTag is treated as OrderLexical.
+	Responses Order
+
+	// SecuritySchemes is the order of the component security schemes.
+	// OrderTag is treated as OrderLexical.
+	SecuritySchemes Order
+
+	// Properties is the order of the properties of schemas generated from
+	// structs, where OrderRegistration keeps the order of the struct fields.
+	// OrderTag is treated as OrderLexical.
+	Properties Order
+}
+
+// Layout sets the order of the emitted paths, component schemas, responses,
+// security schemes and schema properties.
+func (b *Builder) Layout(layout Layout) *Builder {
+	b.openAPI.Layout = layout
+	b.openAPI.Invalidate()
+
+	if r, ok := b.openAPI.Components.Schemas.(*mapRegistry); ok {
+		r.mu.Lock()
+		r.schemaOrder = layout.Schemas
+		r.propertyOrder = layout.Properties
+		r.mu.Unlock()
+	}
+
+	// Update the schemas generated so far. Shared element schemas have no
+	// properties and are left alone.
+	walkStructs(reflect.ValueOf(b.openAPI), func(v reflect.Value) {
+		if v.Type() == schemaType {
+			if s := v.Addr().Interface().(*Schema); !s.shared {
+				s.orderProperties = layout.Properties == OrderRegistration
+			}
+		}
+	})
+
+	return b
+}
+
+// orderedMap marshals the values of a map in the order of keys.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap[V any](values map[string]V, keys []string) orderedMap {
+	m := orderedMap{keys: keys, values: make(map[string]any, len(values))}
+	for k, v := range values {
+		m.values[k] = v
+	}
+	return m
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// setJSONKey returns the JSON object with key set to value. The other members
+// are copied as is, in their order, and key keeps its position if it is
+// already set.
+func setJSONKey(object []byte, key string, value any) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := json.Marshal(key)
+
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if t, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	set := false
+	for decoder.More() {
+		k, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var member json.RawMessage
+		if err := decoder.Decode(&member); err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		b, _ := json.Marshal(k)
+		buf.Write(b)
+		buf.WriteByte(':')
+		if k == key {
+			member = encoded
+			set = true
+		}
+		buf.Write(member)
+	}
+	if !set {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// registrationOrder returns the keys of values in the order of registered,
+// followed by the unregistered keys sorted.
+func registrationOrder[V any](values map[string]V, registered []string) []string {
+	keys := make([]string, 0, len(values))
+	seen := map[string]bool{}
+	for _, k := range registered {
+		if _, ok := values[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+
+	var rest []string
+	for k := range values {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// orderPaths returns paths in the order of the layout, or paths itself for
+// OrderLexical, which encoding/json applies to maps.
+func (o *OpenAPI) orderPaths(paths map[string]*PathItem) any {
+	if len(paths) == 0 {
+		return paths
+	}
+
+	if o.Layout.Responses == OrderRegistration {
+		paths = withResponseOrder(paths)
+	}
+
+	var keys []string
+	switch o.Layout.Paths {
+	case OrderRegistration:
+		keys = registrationOrder(paths, o.pathOrder)
+	case OrderTag:
+		keys = sortedKeys(paths)
+		declared := map[string]int{}
+		for i, tag := range o.Tags {
+			declared[tag.Name] = i
+		}
+		group := func(path string) (int, string) {
+			tag := ""
+			paths[path].WalkOperations(func(method string, op *Operation) {
+				if tag == "" && len(op.Tags) > 0 {
+					tag = op.Tags[0]
+				}
+			})
+			if i, ok := declared[tag]; ok {
+				return i, ""
+			}
+			if tag == "" {
+				return len(declared) + 1, ""
+			}
+			return len(declared), tag
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			gi, ti := group(keys[i])
+			gj, tj := group(keys[j])
+			if gi != gj {
+				return gi < gj
+			}
+			return ti < tj
+		})
+	default:
+		return paths
+	}
+
+	return newOrderedMap(paths, keys)
+}
+
+// withResponseOrder returns shallow copies of the path items whose
+// operations marshal their responses in registration order, leaving the
+// document untouched.
+func withResponseOrder(paths map[string]*PathItem) map[string]*PathItem {
+	copied := make(map[string]*PathItem, len(paths))
+	for path, item := range paths {
+		c := *item
+		c.AdditionalOperations = nil
+		item.WalkOperations(func(method string, op *Operation) {
+			opCopy := *op
+			opCopy.Method = method
+			opCopy.orderResponses = true
+			c.SetOperation(&opCopy)
+		})
+		copied[path] = &c
+	}
+	return copied
+}
+
+// orderComponents returns the components, or a shallow copy marshalling the
+// security schemes in registration order.
+func (o *OpenAPI) orderComponents() *Components {
+	if o.Components == nil || o.Layout.SecuritySchemes != OrderRegistration {
+		return o.Components
+	}
+
+	c := *o.Components
+	c.orderSecuritySchemes = true
+	return &c
+}
@@ -0,0 +1,181 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type LayoutZebra struct {
+	Name string `json:"name"`
+}
+
+type LayoutApple struct {
+	Name string `json:"name"`
+}
+
+// keyOrder returns the keys of the object at the given JSON path in the order
+// they appear in data.
+func keyOrder(t *testing.T, data []byte, path ...string) []string {
+	var raw json.RawMessage = data
+	for _, p := range path {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &m); err != nil {
+			t.Fatal(err)
+		}
+		raw = m[p]
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.Token()
+	var keys []string
+	for dec.More() {
+		key, _ := dec.Token()
+		keys = append(keys, key.(string))
+		var skip json.RawMessage
+		dec.Decode(&skip)
+	}
+	return keys
+}
+
+func TestLayout(t *testing.T) {
+	build := func(layout openapi.Layout) []byte {
+		builder := openapi.New("title", "version").Layout(layout)
+		builder.OpenAPI().Tags = []*openapi.Tag{{Name: "zoo"}, {Name: "fruit"}}
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/zebras", Tags: []string{"zoo"}}).
+			Response(http.StatusOK).Body(LayoutZebra{})
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/apples", Tags: []string{"fruit"}}).
+			Response(http.StatusOK).Body(LayoutApple{})
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/health"})
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/lions", Tags: []string{"zoo"}})
+
+		data, err := builder.OpenAPI().JSONBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	for _, test := range []struct {
+		layout  openapi.Layout
+		paths   []string
+		schemas []string
+	}{
+		{openapi.Layout{}, []string{"/apples", "/health", "/lions", "/zebras"}, []string{"LayoutApple", "LayoutZebra"}},
+		{openapi.Layout{Paths: openapi.OrderRegistration, Schemas: openapi.OrderRegistration}, []string{"/zebras", "/apples", "/health", "/lions"}, []string{"LayoutZebra", "LayoutApple"}},
+		{openapi.Layout{Paths: openapi.OrderTag}, []string{"/lions", "/zebras", "/apples", "/health"}, []string{"LayoutApple", "LayoutZebra"}},
+	} {
+		data := build(test.layout)
+		if paths := keyOrder(t, data, "paths"); !reflect.DeepEqual(paths, test.paths) {
+			t.Errorf("%+v: expected paths %v, got %v", test.layout, test.paths, paths)
+		}
+		if schemas := keyOrder(t, data, "components", "schemas"); !reflect.DeepEqual(schemas, test.schemas) {
+			t.Errorf("%+v: expected schemas %v, got %v", test.layout, test.schemas, schemas)
+		}
+	}
+}
+
+func TestLayoutResponsesAndSecuritySchemes(t *testing.T) {
+	build := func(layout openapi.Layout) []byte {
+		builder := openapi.New("title", "version").Layout(layout).BearerAuth().BasicAuth()
+		op := builder.Register(&openapi.Operation{Method: http.MethodPost, Path: "/users"})
+		op.Response(http.StatusCreated).Description("Created")
+		op.Response(http.StatusBadRequest).Description("Bad Request")
+		op.Response(http.StatusAccepted).Description("Accepted")
+
+		data, err := builder.OpenAPI().JSONBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	for _, test := range []struct {
+		layout          openapi.Layout
+		responses       []string
+		securitySchemes []string
+	}{
+		{openapi.Layout{}, []string{"201", "202", "400"}, []string{"BasicAuth", "BearerAuth"}},
+		{openapi.Layout{Responses: openapi.OrderRegistration, SecuritySchemes: openapi.OrderRegistration}, []string{"201", "400", "202"}, []string{"BearerAuth", "BasicAuth"}},
+	} {
+		for i := 0; i < 10; i++ {
+			data := build(test.layout)
+			if !bytes.Equal(data, build(test.layout)) {
+				t.Fatalf("%+v: expected stable output", test.layout)
+			}
+
+			var doc struct {
+				Paths map[string]map[string]json.RawMessage
+			}
+			json.Unmarshal(data, &doc)
+			if responses := keyOrder(t, doc.Paths["/users"]["post"], "responses"); !reflect.DeepEqual(responses, test.responses) {
+				t.Errorf("%+v: expected responses %v, got %v", test.layout, test.responses, responses)
+			}
+			if schemes := keyOrder(t, data, "components", "securitySchemes"); !reflect.DeepEqual(schemes, test.securitySchemes) {
+				t.Errorf("%+v: expected security schemes %v, got %v", test.layout, test.securitySchemes, schemes)
+			}
+		}
+	}
+}
+
+type LayoutAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type LayoutUser struct {
+	Name    string        `json:"name"`
+	Email   string        `json:"email"`
+	Address LayoutAddress `json:"address"`
+}
+
+func TestLayoutProperties(t *testing.T) {
+	for _, test := range []struct {
+		layout     openapi.Layout
+		properties []string
+		address    []string
+	}{
+		{openapi.Layout{}, []string{"address", "email", "name"}, []string{"city", "street"}},
+		{openapi.Layout{Properties: openapi.OrderRegistration}, []string{"name", "email", "address"}, []string{"street", "city"}},
+	} {
+		builder := openapi.New("title", "version")
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/user"}).
+			Response(http.StatusOK).Body(LayoutUser{})
+		// The layout also applies to schemas generated before it was set.
+		builder.Layout(test.layout)
+
+		data, err := builder.OpenAPI().JSONBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if properties := keyOrder(t, data, "components", "schemas", "LayoutUser", "properties"); !reflect.DeepEqual(properties, test.properties) {
+			t.Errorf("%+v: expected properties %v, got %v", test.layout, test.properties, properties)
+		}
+		if address := keyOrder(t, data, "components", "schemas", "LayoutAddress", "properties"); !reflect.DeepEqual(address, test.address) {
+			t.Errorf("%+v: expected address properties %v, got %v", test.layout, test.address, address)
+		}
+	}
+}
+
+func TestLayoutConcurrentBuilders(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, order := range []openapi.Order{openapi.OrderLexical, openapi.OrderRegistration} {
+		wg.Add(1)
+		go func(order openapi.Order) {
+			defer wg.Done()
+			builder := openapi.New("title", "version")
+			builder.Register(&openapi.Operation{Method: http.MethodGet, Path: "/tags"}).
+				Response(http.StatusOK).Body([]string{})
+			builder.Layout(openapi.Layout{Properties: order})
+			if _, err := builder.Build(); err != nil {
+				t.Error(err)
+			}
+		}(order)
+	}
+	wg.Wait()
+}
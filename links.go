@@ -0,0 +1,168 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LinkSuggestion is a link proposed by SuggestLinks which has not been added
+// to the document yet.
+type LinkSuggestion struct {
+	// Path, Method and Status identify the response the link belongs to.
+	Path   string
+	Method string
+	Status string
+
+	// Name is the name of the link in the response, which is the operation ID
+	// of the target operation.
+	Name string
+
+	// Link is the proposed link.
+	Link *Link
+
+	response *Response
+	openAPI  *OpenAPI
+}
+
+// Accept adds the link to its response.
+func (s *LinkSuggestion) Accept() {
+	if s.response.Links == nil {
+		s.response.Links = map[string]*Link{}
+	}
+	s.response.Links[s.Name] = s.Link
+	s.openAPI.Invalidate()
+}
+
+// SuggestLinks proposes links between operations. A link is proposed from a
+// successful response to a GET operation when the response body has fields
+// for all of the operation's path parameters, either by the same name or as
+// the `id` of the matching schema, e.g. `User.id` → `GET /users/{userId}`.
+// Responses which already have a link by the same name are skipped.
+func (o *OpenAPI) SuggestLinks() []*LinkSuggestion {
+	type target struct {
+		op     *Operation
+		params []string
+	}
+
+	var targets []target
+	o.WalkOperations(func(path, method string, op *Operation) {
+		if method != http.MethodGet || op.OperationID == "" {
+			return
+		}
+		var params []string
+		for _, match := range pathTemplateRe.FindAllStringSubmatch(path, -1) {
+			params = append(params, match[1])
+		}
+		if len(params) > 0 {
+			targets = append(targets, target{op, params})
+		}
+	})
+
+	var suggestions []*LinkSuggestion
+	o.WalkOperations(func(path, method string, op *Operation) {
+		statuses := make([]string, 0, len(op.Responses))
+		for status := range op.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		for _, status := range statuses {
+			if code, err := strconv.Atoi(status); err != nil || code < 200 || code >= 300 {
+				continue
+			}
+
+			response := op.Responses[status]
+			name, body := o.responseBodySchema(response)
+			if body == nil {
+				continue
+			}
+
+			for _, t := range targets {
+				if t.op == op || response.Links[t.op.OperationID] != nil {
+					continue
+				}
+
+				parameters := map[string]any{}
+				for _, param := range t.params {
+					if field := linkField(name, body, param); field != "" {
+						parameters[param] = "$response.body#/" + field
+					}
+				}
+				if len(parameters) != len(t.params) {
+					continue
+				}
+
+				suggestions = append(suggestions, &LinkSuggestion{
+					Path:   path,
+					Method: method,
+					Status: status,
+					Name:   t.op.OperationID,
+					Link: &Link{
+						OperationID: t.op.OperationID,
+						Parameters:  parameters,
+					},
+					response: response,
+					openAPI:  o,
+				})
+			}
+		}
+	})
+
+	return suggestions
+}
+
+// responseBodySchema returns the name and resolved object schema of the JSON
+// body of the response, if any.
+func (o *OpenAPI) responseBodySchema(response *Response) (string, *Schema) {
+	if response == nil || o.Components == nil || o.Components.Schemas == nil {
+		return "", nil
+	}
+
+	contentTypes := make([]string, 0, len(response.Content))
+	for contentType := range response.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	for _, contentType := range contentTypes {
+		mediaType := response.Content[contentType]
+		if !strings.Contains(contentType, "json") || mediaType.Schema == nil || mediaType.Schema.Ref == "" {
+			continue
+		}
+
+		s := o.Components.Schemas.SchemaFromRef(mediaType.Schema.Ref)
+		if s == nil || s.Type != TypeObject {
+			continue
+		}
+
+		return mediaType.Schema.Ref[strings.LastIndex(mediaType.Schema.Ref, "/")+1:], s
+	}
+
+	return "", nil
+}
+
+// linkField returns the property of the schema which provides the path
+// parameter, or an empty string.
+func linkField(name string, s *Schema, param string) string {
+	if s.Properties[param] != nil {
+		return param
+	}
+
+	if s.Properties["id"] != nil {
+		p := strings.ToLower(param)
+		n := strings.ToLower(name)
+		if p == n+"id" || p == n+"_id" {
+			return "id"
+		}
+	}
+
+	return ""
+}
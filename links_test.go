@@ -0,0 +1,69 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type LinkUser struct {
+	ID    int `json:"id"`
+	OrgID int `json:"orgId"`
+}
+
+func TestSuggestLinks(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	createUser.Response(http.StatusCreated).Body(LinkUser{})
+	createUser.Response(http.StatusBadRequest).Body(LinkUser{})
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{linkUserId}",
+	})
+	getUser.Request().PathParam("linkUserId", openapi.IntType)
+	getUser.Response(http.StatusOK).Body(LinkUser{})
+
+	getOrg := builder.Register(&openapi.Operation{
+		OperationID: "getOrg",
+		Method:      http.MethodGet,
+		Path:        "/orgs/{orgId}",
+	})
+	getOrg.Request().PathParam("orgId", openapi.IntType)
+
+	suggestions := builder.OpenAPI().SuggestLinks()
+
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.Method+" "+s.Path+" "+s.Status+" "+s.Name)
+	}
+	expected := []string{
+		"POST /users 201 getOrg",
+		"POST /users 201 getUser",
+		"GET /users/{linkUserId} 200 getOrg",
+	}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], names[i])
+		}
+	}
+
+	builder.AutoLinks()
+	link := builder.OpenAPI().Paths["/users"].Post.Responses["201"].Links["getUser"]
+	if link == nil || link.Parameters["linkUserId"] != "$response.body#/id" {
+		t.Errorf("unexpected link %v", link)
+	}
+	if len(builder.OpenAPI().SuggestLinks()) != 0 {
+		t.Errorf("expected accepted links not to be suggested again")
+	}
+}
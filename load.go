@@ -0,0 +1,332 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/restk/openapi/yaml"
+)
+
+var registryType = reflect.TypeOf((*Registry)(nil)).Elem()
+
+// Load reads an OpenAPI 3.x document as JSON or YAML. The schemas of
+// `components.schemas` are loaded into a map registry, so the document can be
+// extended with FromOpenAPI and registered Go types; registering a type whose
+// schema name was loaded panics as a duplicate name. Unknown keys, such as
+// JSON Schema keywords this package doesn't model, are kept as extensions so
+// they survive marshalling the document again. OpenAPI 3.0 documents are
+// converted to 3.1, including their version.
+func Load(r io.Reader) (*OpenAPI, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &doc)
+	} else {
+		doc, err = yaml.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o := &OpenAPI{}
+	if err := decodeValue("", doc, reflect.ValueOf(o).Elem()); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(o.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", o.OpenAPI)
+	}
+	if strings.HasPrefix(o.OpenAPI, "3.0") {
+		// The schemas were converted to 3.1, so the document is one now.
+		o.OpenAPI = "3.1.0"
+	}
+
+	if o.Info == nil {
+		o.Info = &Info{}
+	}
+	if o.Components == nil {
+		o.Components = &Components{}
+	}
+	if o.Components.Schemas == nil {
+		o.Components.Schemas = NewMapRegistry("#/components/schemas/", DefaultSchemaNamer)
+	}
+	if o.Components.SecuritySchemes == nil {
+		o.Components.SecuritySchemes = map[string]*SecurityScheme{}
+	}
+
+	paths := make([]string, 0, len(o.Paths))
+	for path := range o.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		o.pathOrder = append(o.pathOrder, path)
+		o.Paths[path].WalkOperations(func(method string, op *Operation) {
+			op.Method = method
+			op.Path = path
+		})
+	}
+
+	walkStructs(reflect.ValueOf(o), func(v reflect.Value) {
+		if s, ok := v.Addr().Interface().(*Schema); ok {
+			s.PrecomputeMessages()
+		}
+	})
+
+	return o, nil
+}
+
+// LoadFile reads an OpenAPI 3.x document from a JSON or YAML file, see Load.
+func LoadFile(path string) (*OpenAPI, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// FromOpenAPI returns a builder which continues building o, e.g. a document
+// read with Load.
+func FromOpenAPI(o *OpenAPI) *Builder {
+	return &Builder{
+		openAPI: o,
+	}
+}
+
+// decodeValue decodes a value decoded from JSON or YAML into dst, using the
+// yaml tags of the structs of this package as the names of their fields.
+// location is used in errors, e.g. `paths./users.get`.
+func decodeValue(location string, v any, dst reflect.Value) error {
+	if v == nil {
+		return nil
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("%s: cannot decode %T into %s", strings.TrimPrefix(location, "."), v, dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		n := reflect.New(dst.Type().Elem())
+		if err := decodeValue(location, v, n.Elem()); err != nil {
+			return err
+		}
+		dst.Set(n)
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		if s, ok := dst.Addr().Interface().(*Schema); ok {
+			return decodeSchema(location, m, s)
+		}
+		return decodeStruct(location, m, dst)
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+		for k, value := range m {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := decodeValue(location+"."+k, value, elem); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+	case reflect.Slice:
+		s, ok := v.([]any)
+		if !ok {
+			return mismatch()
+		}
+		dst.Set(reflect.MakeSlice(dst.Type(), len(s), len(s)))
+		for i, value := range s {
+			if err := decodeValue(fmt.Sprintf("%s[%d]", location, i), value, dst.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Interface:
+		if dst.Type() == registryType {
+			return decodeRegistry(location, v, dst)
+		}
+		dst.Set(reflect.ValueOf(v))
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetBool(b)
+	case reflect.Int:
+		f, ok := v.(float64)
+		if !ok || f != float64(int(f)) {
+			return mismatch()
+		}
+		dst.SetInt(int64(f))
+	case reflect.Float64:
+		f, ok := v.(float64)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetFloat(f)
+	default:
+		return mismatch()
+	}
+
+	return nil
+}
+
+// decodeStruct decodes m into the fields of dst named by their yaml tags.
+// Keys without a field are put into the inline extensions, if any.
+func decodeStruct(location string, m map[string]any, dst reflect.Value) error {
+	fields := map[string]int{}
+	inline := -1
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		switch {
+		case name == "-":
+		case opts == "inline":
+			inline = i
+		case name != "":
+			fields[name] = i
+		}
+	}
+
+	for k, v := range m {
+		if i, ok := fields[k]; ok {
+			if err := decodeValue(location+"."+k, v, dst.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if inline >= 0 {
+			ext := dst.Field(inline)
+			if ext.IsNil() {
+				ext.Set(reflect.ValueOf(map[string]any{}))
+			}
+			ext.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(&v).Elem())
+		}
+	}
+
+	return nil
+}
+
+// decodeSchema decodes a schema, converting OpenAPI 3.0 keywords such as
+// `nullable` and `example` to their 3.1 equivalents.
+func decodeSchema(location string, m map[string]any, s *Schema) error {
+	rest := make(map[string]any, len(m))
+	for k, v := range m {
+		rest[k] = v
+	}
+
+	switch typ := rest["type"].(type) {
+	case []any:
+		for _, t := range typ {
+			if t == "null" {
+				s.Nullable = true
+			} else if name, ok := t.(string); ok && s.Type == "" {
+				s.Type = name
+			}
+		}
+		delete(rest, "type")
+	}
+	if nullable, ok := rest["nullable"].(bool); ok {
+		s.Nullable = nullable
+		delete(rest, "nullable")
+	}
+	if example, ok := rest["example"]; ok {
+		if _, ok := rest["examples"]; !ok {
+			rest["examples"] = []any{example}
+			delete(rest, "example")
+		}
+	}
+
+	// In OpenAPI 3.0 the exclusive bounds are flags on minimum and maximum.
+	exclusiveMinimum, _ := rest["exclusiveMinimum"].(bool)
+	exclusiveMaximum, _ := rest["exclusiveMaximum"].(bool)
+	for _, k := range []string{"exclusiveMinimum", "exclusiveMaximum"} {
+		if _, ok := rest[k].(bool); ok {
+			delete(rest, k)
+		}
+	}
+
+	switch additional := rest["additionalProperties"].(type) {
+	case bool:
+		s.AdditionalProperties = additional
+		delete(rest, "additionalProperties")
+	case map[string]any:
+		sub := &Schema{}
+		if err := decodeSchema(location+".additionalProperties", additional, sub); err != nil {
+			return err
+		}
+		s.AdditionalProperties = sub
+		delete(rest, "additionalProperties")
+	}
+
+	if err := decodeStruct(location, rest, reflect.ValueOf(s).Elem()); err != nil {
+		return err
+	}
+
+	if exclusiveMinimum {
+		s.ExclusiveMinimum, s.Minimum = s.Minimum, nil
+	}
+	if exclusiveMaximum {
+		s.ExclusiveMaximum, s.Maximum = s.Maximum, nil
+	}
+
+	return nil
+}
+
+// decodeRegistry decodes `components.schemas` into a map registry.
+func decodeRegistry(location string, v any, dst reflect.Value) error {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: cannot decode %T into schemas", strings.TrimPrefix(location, "."), v)
+	}
+
+	r := NewMapRegistry("#/components/schemas/", DefaultSchemaNamer).(*mapRegistry)
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := &Schema{}
+		if err := decodeValue(location+"."+name, m[name], reflect.ValueOf(s).Elem()); err != nil {
+			return err
+		}
+		r.schemas[name] = s
+		r.order = append(r.order, name)
+	}
+
+	dst.Set(reflect.ValueOf(r))
+	return nil
+}
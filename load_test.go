@@ -0,0 +1,175 @@
+package openapi_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type LoadedAddress struct {
+	Street string  `json:"street" minLength:"1"`
+	Zip    *string `json:"zip,omitempty" pattern:"^[0-9]{5}$"`
+}
+
+type LoadedUser struct {
+	ID      int            `json:"id" minimum:"1" doc:"ID of the user"`
+	Tags    []string       `json:"tags" uniqueItems:"true"`
+	Address *LoadedAddress `json:"address,omitempty"`
+	Meta    map[string]int `json:"meta,omitempty"`
+}
+
+func loadBuilder() *openapi.Builder {
+	builder := openapi.New("Users", "1.0.0")
+	builder.Description("Manages users")
+	builder.Server().URL("https://api.example.com").Description("Production")
+	builder.BearerAuth()
+	builder.Security("BearerAuth", []string{})
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+		Tags:        []string{"users"},
+	})
+	getUser.Request().PathParam("id", openapi.IntType).Example("1")
+	getUser.Request().QueryParam("expand", []string{})
+	getUser.Response(http.StatusOK).Description("The user").Body(LoadedUser{})
+	getUser.Response(http.StatusNotFound).Description("Not found").ContentType("text/plain").Body(openapi.StringType)
+
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+		Tags:        []string{"users"},
+	})
+	createUser.Extension("x-internal", true)
+	createUser.Request().Body(LoadedUser{}).Description("The new user")
+	createUser.Response(http.StatusCreated).Description("Created").Body(LoadedUser{})
+
+	return builder
+}
+
+func TestLoad(t *testing.T) {
+	o := loadBuilder().OpenAPI()
+	want, err := o.JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	yamlDoc, err := o.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, src := range map[string][]byte{"json": want, "yaml": yamlDoc} {
+		loaded, err := openapi.Load(strings.NewReader(string(src)))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := loaded.JSONBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s: round trip changed the document\n got %s\nwant %s", name, got, want)
+		}
+		if op := loaded.Paths["/users/{id}"].Get; op.Method != http.MethodGet || op.Path != "/users/{id}" {
+			t.Errorf("%s: expected method and path to be set, got %s %s", name, op.Method, op.Path)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	err := os.WriteFile(path, []byte(`
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      x-owner: pets-team
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          nullable: true
+          example: Rex
+        age:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+          const: 3
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := openapi.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pet := o.Components.Schemas.SchemaFromRef("#/components/schemas/Pet")
+	if pet == nil {
+		t.Fatalf("expected Pet in the registry")
+	}
+	name, age := pet.Properties["name"], pet.Properties["age"]
+	if !name.Nullable || len(name.Examples) != 1 || name.Examples[0] != "Rex" {
+		t.Errorf("unexpected name schema %v", name)
+	}
+	if age.Minimum != nil || age.ExclusiveMinimum == nil || *age.ExclusiveMinimum != 0 || age.Extensions["const"] != float64(3) {
+		t.Errorf("unexpected age schema %v", age)
+	}
+	if op := o.Paths["/pets"].Get; op.Extensions["x-owner"] != "pets-team" {
+		t.Errorf("unexpected extensions %v", op.Extensions)
+	}
+
+	// The converted document is marshalled as 3.1.
+	spec, err := o.JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"openapi":"3.1.0"`, `"type":["string","null"]`} {
+		if !strings.Contains(string(spec), expected) {
+			t.Errorf("expected %s in the loaded document, got %s", expected, spec)
+		}
+	}
+
+	// The loaded document can be extended with the builder.
+	builder := openapi.FromOpenAPI(o)
+	builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/pets",
+	}).Response(http.StatusCreated).Description("Created").Body(LoadedAddress{})
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if o.Paths["/pets"].Post == nil || o.Components.Schemas.SchemaFromRef("#/components/schemas/LoadedAddress") == nil {
+		t.Errorf("expected createPet and LoadedAddress to be added")
+	}
+
+	if _, err := openapi.Load(strings.NewReader(`{"swagger": "2.0"}`)); err == nil {
+		t.Errorf("expected error for swagger 2.0")
+	}
+}
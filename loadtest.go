@@ -0,0 +1,419 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// loadTestRequest is the request of the load test scenario of an operation.
+type loadTestRequest struct {
+	name   string
+	method string
+	path   string
+	header http.Header
+	body   []byte
+	status int
+
+	// sequential is set for ConcurrencySequential operations.
+	sequential bool
+}
+
+// k6Sequential is the name of the k6 scenario calling the
+// ConcurrencySequential operations one after another.
+const k6Sequential = "sequential"
+
+// ExportK6 returns a k6 script with a scenario per operation, so performance
+// tests stay in sync with the contract. ConcurrencySequential operations share
+// a single scenario with one virtual user, which calls them in turn. Requests
+// are filled in with the examples of the params and request bodies, falling
+// back to values generated from the schemas of required params and bodies
+// without examples, and credentials are read from
+// environment variables named after the security schemes, e.g.
+// `__ENV.BEARER_AUTH` for `bearerAuth`, as is the base URL from `BASE_URL`,
+// which defaults to the first server:
+//
+//	k6 run -e BASE_URL=https://staging.example.com -e BEARER_AUTH=token script.js
+func (o *OpenAPI) ExportK6() ([]byte, error) {
+	requests, err := o.loadTestRequests()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, _ := json.Marshal(o.loadTestBaseURL())
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("import http from 'k6/http';\nimport { check } from 'k6';\n\n")
+	fmt.Fprintf(buf, "const BASE_URL = __ENV.BASE_URL || %s;\n\n", baseURL)
+
+	var sequential []string
+	buf.WriteString("export const options = {\n  scenarios: {\n")
+	for _, r := range requests {
+		if r.sequential {
+			sequential = append(sequential, r.name)
+			continue
+		}
+		fmt.Fprintf(buf, "    %s: { executor: 'constant-vus', vus: 1, duration: '30s', exec: '%s' },\n", r.name, r.name)
+	}
+	if len(sequential) > 0 {
+		fmt.Fprintf(buf, "    %s: { executor: 'constant-vus', vus: 1, duration: '30s', exec: '%s' },\n", k6Sequential, k6Sequential)
+	}
+	buf.WriteString("  },\n};\n")
+
+	for _, r := range requests {
+		fmt.Fprintf(buf, "\nexport function %s() {\n", r.name)
+
+		body := "null"
+		if r.body != nil {
+			body = "JSON.stringify(" + string(r.body) + ")"
+		}
+
+		headers := make([]string, 0, len(r.header))
+		for _, name := range sortedKeys(r.header) {
+			key, _ := json.Marshal(name)
+			headers = append(headers, string(key)+": "+k6Value(r.header.Get(name)))
+		}
+
+		fmt.Fprintf(buf, "  const res = http.request(%s, BASE_URL + %s, %s, {\n    headers: { %s },\n  });\n",
+			strconv.Quote(r.method), k6Value(r.path), body, strings.Join(headers, ", "))
+		if r.status != 0 {
+			fmt.Fprintf(buf, "  check(res, { 'status is %d': (r) => r.status === %d });\n", r.status, r.status)
+		}
+		buf.WriteString("}\n")
+	}
+
+	if len(sequential) > 0 {
+		fmt.Fprintf(buf, "\nexport function %s() {\n", k6Sequential)
+		for _, name := range sequential {
+			fmt.Fprintf(buf, "  %s();\n", name)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// k6Value returns the JavaScript expression of a string which may contain
+// `${NAME}` placeholders, replacing them with the environment variables.
+func k6Value(s string) string {
+	var parts []string
+	literal := func(s string) {
+		if s != "" {
+			quoted, _ := json.Marshal(s)
+			parts = append(parts, string(quoted))
+		}
+	}
+
+	for {
+		before, after, ok := strings.Cut(s, "${")
+		if !ok {
+			break
+		}
+		name, rest, ok := strings.Cut(after, "}")
+		if !ok {
+			break
+		}
+		literal(before)
+		parts = append(parts, "__ENV."+name)
+		s = rest
+	}
+	literal(s)
+
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
+
+// ExportVegeta returns Vegeta targets in its JSON format, one per operation,
+// so performance tests stay in sync with the contract. Requests are filled in
+// with the examples of the params and request bodies. Credentials are left as
+// placeholders named after the security schemes, e.g. `${BEARER_AUTH}` for
+// `bearerAuth`, to be substituted before the attack:
+//
+//	envsubst < targets.json | vegeta attack -format=json -rate=10 -duration=30s
+func (o *OpenAPI) ExportVegeta() ([]byte, error) {
+	requests, err := o.loadTestRequests()
+	if err != nil {
+		return nil, err
+	}
+
+	type target struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Body   []byte      `json:"body,omitempty"`
+		Header http.Header `json:"header,omitempty"`
+	}
+
+	buf := &bytes.Buffer{}
+	baseURL := o.loadTestBaseURL()
+	for _, r := range requests {
+		line, err := json.Marshal(&target{
+			Method: r.method,
+			URL:    baseURL + r.path,
+			Body:   r.body,
+			Header: r.header,
+		})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// loadTestBaseURL returns the URL of the first server without its trailing
+// slash.
+func (o *OpenAPI) loadTestBaseURL() string {
+	if len(o.Servers) == 0 {
+		return "http://localhost"
+	}
+	return strings.TrimSuffix(o.Servers[0].URL, "/")
+}
+
+// loadTestRequests returns the load test request of every operation, sorted
+// by path and method.
+func (o *OpenAPI) loadTestRequests() ([]*loadTestRequest, error) {
+	var requests []*loadTestRequest
+	var err error
+	names := map[string]bool{k6Sequential: true}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		if err != nil {
+			return
+		}
+
+		r := &loadTestRequest{
+			name:   loadTestName(op.OperationID, method, path),
+			method: method,
+			path:   o.examplePath(path, op),
+			header: http.Header{},
+
+			sequential: op.Concurrency() == ConcurrencySequential,
+		}
+		for i := 2; names[r.name]; i++ {
+			r.name = loadTestName(op.OperationID, method, path) + strconv.Itoa(i)
+		}
+		names[r.name] = true
+
+		query := url.Values{}
+		for _, p := range o.operationParams(path, op) {
+			if p.In != "query" && p.In != "header" {
+				continue
+			}
+			value := p.Example
+			if value == nil && p.Required && p.Schema != nil {
+				value = o.schemaExample(p.Schema, 0)
+			}
+			if value == nil {
+				continue
+			}
+			values := []string{fmt.Sprint(value)}
+			if items, ok := value.([]any); ok {
+				values = values[:0]
+				for _, item := range items {
+					values = append(values, fmt.Sprint(item))
+				}
+			}
+			if p.In == "query" {
+				query[p.Name] = values
+			} else {
+				r.header.Set(p.Name, strings.Join(values, ","))
+			}
+		}
+
+		o.loadTestAuth(op, r.header, query)
+		if len(query) > 0 {
+			// The placeholders are kept unescaped, so they can be substituted.
+			r.path += "?" + strings.NewReplacer("%24%7B", "${", "%7D", "}").Replace(query.Encode())
+		}
+
+		if contentType, body, ok := o.requestExample(op); ok {
+			if r.body, err = json.Marshal(body); err != nil {
+				return
+			}
+			r.header.Set("Content-Type", contentType)
+		}
+
+		codes := make([]int, 0, len(op.Responses))
+		for code := range op.Responses {
+			if status, err := strconv.Atoi(code); err == nil && status >= 200 && status < 300 {
+				codes = append(codes, status)
+			}
+		}
+		sort.Ints(codes)
+		if len(codes) > 0 {
+			r.status = codes[0]
+		}
+
+		requests = append(requests, r)
+	})
+
+	return requests, err
+}
+
+// loadTestName returns a JavaScript identifier for the scenario of the
+// operation, named after the ID DefaultOperationID generates if it has none.
+func loadTestName(operationID, method, path string) string {
+	if operationID == "" {
+		operationID = DefaultOperationID(method, path)
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '_'
+	}, operationID)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// loadTestAuth adds the credentials of the first security requirement of the
+// operation as placeholders named after their security schemes.
+func (o *OpenAPI) loadTestAuth(op *Operation, header http.Header, query url.Values) {
+	security := o.Security
+	if op.Security != nil {
+		security = op.Security
+	}
+	if len(security) == 0 || o.Components == nil {
+		return
+	}
+
+	for _, name := range sortedKeys(security[0]) {
+		scheme := o.Components.SecuritySchemes[name]
+		if scheme == nil {
+			continue
+		}
+
+		placeholder := "${" + envName(name) + "}"
+		switch {
+		case scheme.Type == "apiKey" && scheme.In == "query":
+			query.Set(scheme.Name, placeholder)
+		case scheme.Type == "apiKey" && scheme.In == "cookie":
+			header.Add("Cookie", scheme.Name+"="+placeholder)
+		case scheme.Type == "apiKey":
+			header.Set(scheme.Name, placeholder)
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			header.Set("Authorization", "Basic "+placeholder)
+		default:
+			header.Set("Authorization", "Bearer "+placeholder)
+		}
+	}
+}
+
+// envName returns the environment variable name of the security scheme, e.g.
+// `BEARER_AUTH` for `bearerAuth`.
+func envName(name string) string {
+	var sb strings.Builder
+	prev := rune(0)
+	for _, r := range name {
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			sb.WriteByte('_')
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToUpper(r))
+		default:
+			sb.WriteByte('_')
+		}
+		prev = r
+	}
+	return sb.String()
+}
+
+// requestExample returns the JSON request body example of the operation,
+// taken from the media type or its schema.
+func (o *OpenAPI) requestExample(op *Operation) (string, any, bool) {
+	if op.RequestBody == nil {
+		return "", nil, false
+	}
+
+	for _, contentType := range sortedKeys(op.RequestBody.Content) {
+		mt := op.RequestBody.Content[contentType]
+		if !strings.Contains(contentType, "json") || mt == nil {
+			continue
+		}
+
+		switch {
+		case mt.Example != nil:
+			return contentType, exampleValue(mt.Example), true
+		case len(mt.Examples) > 0:
+			for _, name := range sortedKeys(mt.Examples) {
+				if example := mt.Examples[name]; example.Value != nil {
+					return contentType, exampleValue(example.Value), true
+				}
+			}
+		}
+		if mt.Schema != nil {
+			return contentType, o.schemaExample(mt.Schema, 0), true
+		}
+	}
+	return "", nil, false
+}
+
+// schemaExample returns an example of the schema built from the examples,
+// defaults and enums of its properties, falling back to fake values.
+func (o *OpenAPI) schemaExample(s *Schema, depth int) any {
+	for s.Ref != "" {
+		if s = o.Components.Schemas.SchemaFromRef(s.Ref); s == nil {
+			return nil
+		}
+	}
+
+	switch {
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return o.schemaExample(s.OneOf[0], depth)
+	case len(s.AnyOf) > 0:
+		return o.schemaExample(s.AnyOf[0], depth)
+	}
+
+	switch s.Type {
+	case TypeObject:
+		m := map[string]any{}
+		if depth < 5 {
+			for name, p := range s.Properties {
+				if !p.ReadOnly {
+					m[name] = o.schemaExample(p, depth+1)
+				}
+			}
+		}
+		return m
+	case TypeArray:
+		if s.Items == nil || depth >= 5 {
+			return []any{}
+		}
+		return []any{o.schemaExample(s.Items, depth+1)}
+	case TypeBoolean:
+		return false
+	case TypeInteger, TypeNumber:
+		if v := fakeValue(s); v != nil {
+			return v
+		}
+		return 0
+	case TypeString:
+		return fakeValue(s)
+	}
+	return nil
+}
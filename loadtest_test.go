@@ -0,0 +1,105 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type LoadTestUser struct {
+	ID   int    `json:"id" readOnly:"true"`
+	Name string `json:"name" example:"alice"`
+	Role string `json:"role" enum:"member,admin"`
+}
+
+func loadTestAPI() *openapi.Builder {
+	builder := openapi.New("title", "version").BearerAuth()
+	builder.Server().URL("https://api.example.com/")
+	builder.Security("BearerAuth", []string{})
+
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	})
+	getUser.Request().PathParam("id", openapi.IntType).Required(true).Example("42")
+	getUser.Request().QueryParam("expand", openapi.StringType).Example("roles")
+	getUser.Response(http.StatusOK).Body(LoadTestUser{})
+
+	createUser := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+	})
+	createUser.Request().Body(LoadTestUser{})
+	createUser.Response(http.StatusCreated).Body(LoadTestUser{})
+
+	resetUsers := builder.Register(&openapi.Operation{
+		OperationID: "resetUsers",
+		Method:      http.MethodPost,
+		Path:        "/users/reset",
+	}).Concurrency(openapi.ConcurrencySequential)
+	resetUsers.Request().QueryParam("scope", openapi.StringType).Required(true)
+	resetUsers.Request().QueryParam("dryRun", false)
+	resetUsers.Response(http.StatusNoContent)
+
+	return builder
+}
+
+func TestExportK6(t *testing.T) {
+	script, err := loadTestAPI().OpenAPI().ExportK6()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`const BASE_URL = __ENV.BASE_URL || "https://api.example.com";`,
+		`getUser: { executor: 'constant-vus', vus: 1, duration: '30s', exec: 'getUser' },`,
+		`export function createUser() {`,
+		`http.request("POST", BASE_URL + "/users", JSON.stringify({"name":"alice","role":"member"}), {`,
+		`"Authorization": "Bearer " + __ENV.BEARER_AUTH, "Content-Type": "application/json"`,
+		`http.request("GET", BASE_URL + "/users/42?expand=roles", null, {`,
+		`check(res, { 'status is 201': (r) => r.status === 201 });`,
+		`sequential: { executor: 'constant-vus', vus: 1, duration: '30s', exec: 'sequential' },`,
+		"export function sequential() {\n  resetUsers();\n}",
+		`http.request("POST", BASE_URL + "/users/reset?scope=string", null, {`,
+	} {
+		if !strings.Contains(string(script), expected) {
+			t.Errorf("expected %s in\n%s", expected, script)
+		}
+	}
+	if strings.Contains(string(script), "resetUsers: {") {
+		t.Errorf("expected sequential operations to share a scenario\n%s", script)
+	}
+}
+
+func TestExportVegeta(t *testing.T) {
+	targets, err := loadTestAPI().OpenAPI().ExportVegeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(targets)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a target per operation, got\n%s", targets)
+	}
+
+	var target struct {
+		Method string
+		URL    string
+		Body   []byte
+		Header http.Header
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Method != http.MethodPost || target.URL != "https://api.example.com/users" || string(target.Body) != `{"name":"alice","role":"member"}` {
+		t.Errorf("unexpected target %s", lines[0])
+	}
+	if target.Header.Get("Authorization") != "Bearer ${BEARER_AUTH}" {
+		t.Errorf("expected an auth placeholder, got %v", target.Header)
+	}
+}
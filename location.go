@@ -0,0 +1,101 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Location is where an error occurred within a request, as used by
+// ErrorDetail.Location, e.g. `body.items[3].tags` or `path.thing-id`. Use
+// the helpers on Loc rather than formatting them by hand.
+type Location string
+
+// String returns the location, e.g. `body.items[3].tags`.
+func (l Location) String() string {
+	return string(l)
+}
+
+// Field returns the location of the named field of l, e.g. `body.items`.
+func (l Location) Field(name string) Location {
+	if l == "" {
+		return Location(name)
+	}
+	return l + "." + Location(name)
+}
+
+// Index returns the location of the i-th item of l, e.g. `body.items[3]`.
+func (l Location) Index(i int) Location {
+	return l + "[" + Location(strconv.Itoa(i)) + "]"
+}
+
+// Loc provides the roots of locations, matching the ones of the validators.
+//
+//	openapi.Loc.Body().Field("items").Index(3).Field("tags") // body.items[3].tags
+//	openapi.Loc.Path("thing-id")                            // path.thing-id
+//	openapi.Loc.Query("limit")                              // query.limit
+var Loc locations
+
+type locations struct{}
+
+// Body returns `body`, the request body.
+func (locations) Body() Location { return "body" }
+
+// Path returns `path.{name}`, a path parameter.
+func (locations) Path(name string) Location { return Location("path").Field(name) }
+
+// Query returns `query.{name}`, a query parameter.
+func (locations) Query(name string) Location { return Location("query").Field(name) }
+
+// Header returns `header.{name}`, a header.
+func (locations) Header(name string) Location { return Location("header").Field(name) }
+
+// Cookie returns `cookie.{name}`, a cookie.
+func (locations) Cookie(name string) Location { return Location("cookie").Field(name) }
+
+// ParseLocation splits a location such as `body.items[3].tags` into its
+// parts, e.g. "body", "items", 3 and "tags". Fields are strings and indexes
+// are ints. An error is returned if the location is malformed.
+func ParseLocation(location string) ([]any, error) {
+	var parts []any
+	for i := 0; i < len(location); {
+		switch location[i] {
+		case '[':
+			end := strings.IndexByte(location[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated index in location " + location)
+			}
+			index, err := strconv.Atoi(location[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, errors.New("invalid index " + location[i+1:i+end] + " in location " + location)
+			}
+			parts = append(parts, index)
+			i += end + 1
+		case '.':
+			if i == 0 || i == len(location)-1 || location[i+1] == '.' || location[i+1] == '[' {
+				return nil, errors.New("empty field in location " + location)
+			}
+			i++
+		case ']':
+			return nil, errors.New("unexpected ] in location " + location)
+		default:
+			if i > 0 && location[i-1] == ']' {
+				return nil, errors.New("missing separator after index in location " + location)
+			}
+			end := strings.IndexAny(location[i:], ".[]")
+			if end < 0 {
+				end = len(location) - i
+			}
+			parts = append(parts, location[i:i+end])
+			i += end
+		}
+	}
+
+	return parts, nil
+}
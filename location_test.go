@@ -0,0 +1,47 @@
+package openapi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestLocation(t *testing.T) {
+	loc := openapi.Loc.Body().Field("items").Index(3).Field("tags")
+	if loc.String() != "body.items[3].tags" {
+		t.Errorf("unexpected location %s", loc)
+	}
+	if loc := openapi.Loc.Path("thing-id"); loc != "path.thing-id" {
+		t.Errorf("unexpected location %s", loc)
+	}
+	if loc := openapi.Loc.Body().Index(0).Index(1); loc != "body[0][1]" {
+		t.Errorf("unexpected location %s", loc)
+	}
+
+	parts, err := openapi.ParseLocation(loc.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parts, []any{"body", "items", 3, "tags"}) {
+		t.Errorf("unexpected parts %v", parts)
+	}
+
+	for _, invalid := range []string{".body", "body.", "body..items", "body.[1]", "body[x]", "body[1", "body[1]items", "body]"} {
+		if _, err := openapi.ParseLocation(invalid); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestLocationMatchesValidators(t *testing.T) {
+	pb := openapi.NewPathBuffer([]byte{}, 0)
+	pb.Push("body")
+	pb.Push("items")
+	pb.PushIndex(3)
+	pb.Push("tags")
+
+	if loc := openapi.Loc.Body().Field("items").Index(3).Field("tags"); loc.String() != pb.String() {
+		t.Errorf("expected %s to match the validators, got %s", pb, loc)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Matcher maps concrete request paths such as `/users/123` to the operations
+// of a document. Create one with NewMatcher and reuse it, e.g. in middleware.
+// Changes to the document after creating the matcher are not picked up.
+type Matcher struct {
+	routes []*route
+}
+
+type route struct {
+	path    string
+	item    *PathItem
+	re      *regexp.Regexp
+	names   []string
+	literal []bool
+}
+
+// NewMatcher returns a Matcher for the paths of the OpenAPI. Literal path
+// segments take precedence over templated ones, so `/users/me` wins over
+// `/users/{userId}`.
+func NewMatcher(o *OpenAPI) *Matcher {
+	m := &Matcher{}
+
+	for path, item := range o.Paths {
+		r := &route{path: path, item: item}
+
+		var pattern strings.Builder
+		pattern.WriteString("^")
+		for _, segment := range strings.Split(path, "/")[1:] {
+			pattern.WriteString("/")
+
+			last := 0
+			for _, loc := range pathTemplateRe.FindAllStringSubmatchIndex(segment, -1) {
+				pattern.WriteString(regexp.QuoteMeta(segment[last:loc[0]]))
+				pattern.WriteString("([^/]+)")
+				r.names = append(r.names, segment[loc[2]:loc[3]])
+				last = loc[1]
+			}
+			pattern.WriteString(regexp.QuoteMeta(segment[last:]))
+
+			r.literal = append(r.literal, last == 0)
+		}
+		pattern.WriteString("$")
+
+		r.re = regexp.MustCompile(pattern.String())
+		m.routes = append(m.routes, r)
+	}
+
+	sort.Slice(m.routes, func(i, j int) bool {
+		a, b := m.routes[i].literal, m.routes[j].literal
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k]
+			}
+		}
+		return m.routes[i].path < m.routes[j].path
+	})
+
+	return m
+}
+
+// Match returns the operation for the method and path, which must not include
+// the server's base path, along with the (unescaped) values of its path
+// parameters. The returned operation is nil if nothing matches.
+func (m *Matcher) Match(method, path string) (*Operation, map[string]string) {
+	method = strings.ToUpper(method)
+
+	for _, r := range m.routes {
+		op := r.item.Operation(method)
+		if op == nil {
+			continue
+		}
+
+		values := r.re.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+
+		params := make(map[string]string, len(r.names))
+		for i, name := range r.names {
+			value, err := url.PathUnescape(values[i+1])
+			if err != nil {
+				value = values[i+1]
+			}
+			params[name] = value
+		}
+
+		return op, params
+	}
+
+	return nil, nil
+}
+
+// Match is a shorthand for NewMatcher(o).Match(method, path). Use a Matcher
+// when matching many requests against the same document.
+func (o *OpenAPI) Match(method, path string) (*Operation, map[string]string) {
+	return NewMatcher(o).Match(method, path)
+}
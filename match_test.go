@@ -0,0 +1,126 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestMatch(t *testing.T) {
+	builder := openapi.New("title", "version")
+	for _, op := range []*openapi.Operation{
+		{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{userId}"},
+		{OperationID: "deleteUser", Method: http.MethodDelete, Path: "/users/{userId}"},
+		{OperationID: "getMe", Method: http.MethodGet, Path: "/users/me"},
+		{OperationID: "getFile", Method: http.MethodGet, Path: "/files/{name}.{ext}"},
+	} {
+		builder.Register(op)
+	}
+
+	matcher := openapi.NewMatcher(builder.OpenAPI())
+
+	for _, tc := range []struct {
+		method, path, operationID string
+		params                    map[string]string
+	}{
+		{"GET", "/users/123", "getUser", map[string]string{"userId": "123"}},
+		{"GET", "/users/me", "getMe", map[string]string{}},
+		{"delete", "/users/me", "deleteUser", map[string]string{"userId": "me"}},
+		{"GET", "/files/a%20b.json", "getFile", map[string]string{"name": "a b", "ext": "json"}},
+		{"POST", "/users/123", "", nil},
+		{"GET", "/users/123/extra", "", nil},
+	} {
+		op, params := matcher.Match(tc.method, tc.path)
+		if tc.operationID == "" {
+			if op != nil {
+				t.Errorf("%s %s: expected no match, got %s", tc.method, tc.path, op.OperationID)
+			}
+			continue
+		}
+		if op == nil || op.OperationID != tc.operationID {
+			t.Errorf("%s %s: expected %s, got %v", tc.method, tc.path, tc.operationID, op)
+			continue
+		}
+		for k, v := range tc.params {
+			if params[k] != v {
+				t.Errorf("%s %s: expected param %s=%q, got %q", tc.method, tc.path, k, v, params[k])
+			}
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/orgs/{org}/users/{userId}",
+	})
+	getUser.Request().PathParam("org", openapi.StringType)
+	getUser.Request().PathParam("userId", openapi.IntType)
+	getUser.Request().QueryParam("fields", []string{})
+
+	o := builder.OpenAPI()
+
+	u, err := o.URL("getUser", map[string]any{"org": "acme corp", "userId": 123}, map[string]any{
+		"fields": []string{"id", "name"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "/orgs/acme%20corp/users/123?fields=id&fields=name" {
+		t.Errorf("unexpected url %q", u)
+	}
+
+	if _, err := o.URL("getUser", map[string]any{"userId": "abc"}, nil); err == nil {
+		t.Errorf("expected error for missing and invalid path params")
+	}
+
+	if _, err := o.URL("unknown", nil, nil); err == nil {
+		t.Errorf("expected error for unknown operation")
+	}
+}
+
+func TestCustomMethods(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "searchUsers",
+		Method:      openapi.MethodQuery,
+		Path:        "/users",
+	}).Request().Body(map[string]string{})
+	builder.Register(&openapi.Operation{
+		OperationID: "purgeCache",
+		Method:      "PURGE",
+		Path:        "/users",
+	})
+
+	o := builder.OpenAPI()
+	if op, _ := o.Match("QUERY", "/users"); op == nil || op.OperationID != "searchUsers" {
+		t.Errorf("expected QUERY /users to match searchUsers, got %v", op)
+	}
+
+	var methods []string
+	o.WalkOperations(func(path, method string, op *openapi.Operation) {
+		methods = append(methods, method)
+	})
+	if len(methods) != 2 || methods[0] != "PURGE" || methods[1] != "QUERY" {
+		t.Errorf("unexpected methods %v", methods)
+	}
+
+	b, err := o.Paths["/users"].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"x-additionalOperations":{"PURGE":`) {
+		t.Errorf("unexpected path item %s", b)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for lower case method")
+		}
+	}()
+	builder.Register(&openapi.Operation{Method: "get", Path: "/users"})
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestMetrics describes a request handled by MetricsMiddleware, labeled by
+// the identity of its operation in the spec rather than its URL.
+type RequestMetrics struct {
+	// OperationID is the ID of the operation the request matched, or the one
+	// DefaultOperationID generates if it has none, e.g. `getUserById`. It is
+	// empty for requests which do not match any operation. Pass the document
+	// returned by Builder.Build for IDs from Builder.OperationIDs.
+	OperationID string
+
+	// Method and Path are the method and path template of the operation, e.g.
+	// `/users/{id}`.
+	Method string
+	Path   string
+
+	// Status is the response status and StatusClass its class, e.g. `2xx`.
+	Status      int
+	StatusClass string
+
+	// Response is the key of the response documenting the status, e.g. `200`,
+	// `4XX` or `default`, or empty if the status is undocumented.
+	Response string
+
+	// Duration is the time next took to handle the request.
+	Duration time.Duration
+}
+
+// MetricsMiddleware calls record for every request once next has handled it,
+// with the operation the request resolves to through the path matcher. As
+// there is one label value per operation and documented status, they can
+// be used directly as Prometheus or OpenTelemetry labels without the
+// cardinality of URLs, e.g.
+//
+//	openapi.MetricsMiddleware(openAPI, func(m *openapi.RequestMetrics) {
+//		requests.WithLabelValues(m.OperationID, m.StatusClass).Observe(m.Duration.Seconds())
+//	})
+func MetricsMiddleware(o *OpenAPI, record func(m *RequestMetrics)) func(next http.Handler) http.Handler {
+	if record == nil {
+		panic("record must be specified")
+	}
+
+	matcher := NewMatcher(o)
+	paths := map[*Operation]string{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		paths[op] = path
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			m := &RequestMetrics{
+				Status:      sw.status,
+				StatusClass: strconv.Itoa(sw.status/100) + "xx",
+				Duration:    time.Since(start),
+			}
+			if op, _ := matcher.Match(r.Method, r.URL.Path); op != nil {
+				m.Method = r.Method
+				m.Path = paths[op]
+				m.OperationID = op.OperationID
+				if m.OperationID == "" {
+					m.OperationID = DefaultOperationID(m.Method, m.Path)
+				}
+				m.Response = documentedResponse(op, sw.status)
+			}
+
+			record(m)
+		})
+	}
+}
+
+// documentedResponse returns the key of the response of op documenting the
+// status, or an empty string if there is none.
+func documentedResponse(op *Operation, status int) string {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if op.Responses[key] != nil {
+			return key
+		}
+	}
+	return ""
+}
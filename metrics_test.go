@@ -0,0 +1,64 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	})
+	getUser.Request().PathParam("id", openapi.IntType).Required(true)
+	getUser.Response(http.StatusOK).Description("OK")
+	getUser.Response(http.StatusNotFound).Description("Not found")
+	builder.Register(&openapi.Operation{
+		Method: http.MethodDelete,
+		Path:   "/users/{id}",
+	}).Request().PathParam("id", openapi.IntType).Required(true)
+
+	var recorded []*openapi.RequestMetrics
+	handler := openapi.MetricsMiddleware(builder.OpenAPI(), func(m *openapi.RequestMetrics) {
+		recorded = append(recorded, m)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/1":
+			w.Write([]byte("{}"))
+		case "/users/2":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodDelete, "/users/3", nil),
+		httptest.NewRequest(http.MethodGet, "/orders", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := []openapi.RequestMetrics{
+		{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{id}", Status: 200, StatusClass: "2xx", Response: "200"},
+		{OperationID: "getUser", Method: http.MethodGet, Path: "/users/{id}", Status: 404, StatusClass: "4xx", Response: "404"},
+		{OperationID: "deleteUserById", Method: http.MethodDelete, Path: "/users/{id}", Status: 500, StatusClass: "5xx"},
+		{Status: 500, StatusClass: "5xx"},
+	}
+	if len(recorded) != len(expected) {
+		t.Fatalf("expected %d metrics, got %d", len(expected), len(recorded))
+	}
+	for i, m := range recorded {
+		m.Duration = 0
+		if *m != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], *m)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Middleware returns middleware which validates incoming requests against
+// the operations of the OpenAPI, so the contract built with Builder is
+// enforced at runtime. Path, query, header and cookie parameters as well as
+// JSON bodies are checked, and invalid requests get a 422 response listing
+// every ErrorDetail. Requests with an undocumented content type get a 415,
+// and bodies larger than OperationBuilder.MaxBodySize a 413.
+// Requests which don't match an operation are passed through.
+//
+// The document must not change once the middleware is created.
+//
+//	http.ListenAndServe(":8080", openapi.Middleware(builder.OpenAPI())(mux))
+func Middleware(openAPI *OpenAPI) func(next http.Handler) http.Handler {
+	matcher := NewMatcher(openAPI)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, params := matcher.Match(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res := &ValidateResult{}
+			openAPI.validateParams(r, op, params, res)
+			status := openAPI.validateBody(r, op, res)
+			if len(res.Errors) > 0 {
+				writeValidationErrors(w, status, res.Errors)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validateParams validates the parameters of the request against op.
+func (o *OpenAPI) validateParams(r *http.Request, op *Operation, pathParams map[string]string, res *ValidateResult) {
+	registry := o.Components.Schemas
+	query := r.URL.Query()
+
+	for _, p := range o.operationParams(op.Path, op) {
+		values := requestParamValues(r, query, pathParams, p.In, p.Name)
+
+		pb := NewPathBuffer([]byte{}, 0)
+		pb.Push(p.In)
+		pb.Push(p.Name)
+
+		if len(values) == 0 || (len(values) == 1 && values[0] == "" && !p.AllowEmptyValue) {
+			if p.Required {
+				res.Add(pb, nil, "required "+p.In+" parameter is missing")
+			}
+			continue
+		}
+
+		if p.Schema == nil {
+			continue
+		}
+		s := p.Schema
+		for s != nil && s.Ref != "" {
+			s = registry.SchemaFromRef(s.Ref)
+		}
+		if s == nil {
+			// The reference can't be resolved, e.g. Filter pruned its schema.
+			continue
+		}
+
+		v, ok := paramValue(s, values, registry)
+		if !ok {
+			res.Add(pb, strings.Join(values, ","), "expected "+s.Type)
+			continue
+		}
+		Validate(registry, s, pb, ModeWriteToServer, v, res)
+	}
+}
+
+// requestParamValues returns the raw values of a parameter of the request.
+func requestParamValues(r *http.Request, query url.Values, pathParams map[string]string, in, name string) []string {
+	switch in {
+	case "path":
+		if v, ok := pathParams[name]; ok {
+			return []string{v}
+		}
+	case "query":
+		return query[name]
+	case "header":
+		return r.Header.Values(name)
+	case "cookie":
+		if c, err := r.Cookie(name); err == nil {
+			return []string{c.Value}
+		}
+	}
+	return nil
+}
+
+// paramValue converts the raw values of a parameter to the type of its
+// schema. Arrays accept both repeated and comma-separated values.
+func paramValue(s *Schema, values []string, registry Registry) (any, bool) {
+	if s.Type == TypeArray {
+		var raw []string
+		for _, v := range values {
+			raw = append(raw, strings.Split(v, ",")...)
+		}
+
+		items := s.Items
+		for items != nil && items.Ref != "" {
+			items = registry.SchemaFromRef(items.Ref)
+		}
+
+		arr := make([]any, len(raw))
+		for i, v := range raw {
+			if items == nil {
+				arr[i] = v
+				continue
+			}
+			item, ok := scalarValue(items.Type, v)
+			if !ok {
+				return nil, false
+			}
+			arr[i] = item
+		}
+		return arr, true
+	}
+
+	return scalarValue(s.Type, values[0])
+}
+
+// scalarValue converts a raw parameter value to the given schema type.
+func scalarValue(typ, v string) (any, bool) {
+	switch typ {
+	case TypeBoolean:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	case TypeInteger:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	case TypeNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return v, true
+}
+
+// validateBody validates a JSON request body against op. It returns the
+// status to respond with if the body is invalid.
+func (o *OpenAPI) validateBody(r *http.Request, op *Operation, res *ValidateResult) int {
+	status := http.StatusUnprocessableEntity
+
+	body := op.RequestBody
+	if body != nil && body.Ref != "" && o.Components != nil {
+		body = o.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+	}
+	if body == nil || r.Body == nil {
+		return status
+	}
+
+	contentType := mediaType(r.Header.Get("Content-Type"))
+	mt, ok := body.Content[contentType]
+	validated := ok && isJSON(contentType) && mt.Schema != nil
+
+	var limit int64
+	hasLimit := extensionAs(op.Extensions["x-max-body-size"], &limit) && limit > 0
+
+	var data []byte
+	var err error
+	if hasLimit || validated {
+		reader := r.Body
+		if hasLimit {
+			// Read one more byte to tell a body of exactly limit bytes apart.
+			reader = io.NopCloser(io.LimitReader(r.Body, limit+1))
+		}
+		data, err = io.ReadAll(reader)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	} else {
+		// Bodies which aren't validated, e.g. uploads, are not buffered. Only
+		// their first byte is read to tell whether there is one.
+		data = make([]byte, 1)
+		var n int
+		n, err = io.ReadFull(r.Body, data)
+		data = data[:n]
+		if err == io.EOF {
+			err = nil
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	}
+
+	pb := NewPathBuffer([]byte{}, 0)
+	pb.Push("body")
+
+	if err != nil {
+		res.Add(pb, nil, "unable to read request body")
+		return http.StatusBadRequest
+	}
+	if hasLimit && int64(len(data)) > limit {
+		res.Add(pb, nil, "request body exceeds "+formatBytes(limit))
+		return http.StatusRequestEntityTooLarge
+	}
+	if len(data) == 0 {
+		if body.Required {
+			res.Add(pb, nil, "request body is required")
+		}
+		return status
+	}
+
+	if !ok {
+		res.Add(NewPathBuffer([]byte("header.Content-Type"), len("header.Content-Type")), contentType, "unsupported content type")
+		return http.StatusUnsupportedMediaType
+	}
+	if !validated {
+		return status
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		res.Add(pb, nil, "invalid JSON: "+err.Error())
+		return http.StatusBadRequest
+	}
+	Validate(o.Components.Schemas, mt.Schema, pb, ModeWriteToServer, v, res)
+
+	return status
+}
+
+// writeValidationErrors responds with the validation errors as JSON.
+func writeValidationErrors(w http.ResponseWriter, status int, errs []error) {
+	body := &ErrorModel{
+		Status: status,
+		Title:  http.StatusText(status),
+	}
+	if len(errs) > 0 {
+		body.Errors = newErrorList(errs)
+	}
+
+	writeError(w, status, body)
+}
+
+// writeError responds with the error body as JSON.
+func writeError(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,173 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type CreatePet struct {
+	Name string `json:"name" minLength:"2"`
+	Age  int    `json:"age" minimum:"0"`
+}
+
+func TestMiddleware(t *testing.T) {
+	builder := openapi.New("title", "version")
+	createPet := builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/owners/{ownerId}/pets",
+	})
+	createPet.Request().PathParam("ownerId", openapi.IntType)
+	createPet.Request().QueryParam("dryRun", openapi.BoolType)
+	createPet.Request().Param("header", "X-Tenant", openapi.StringType).Required(true)
+	createPet.Request().Body(CreatePet{})
+
+	called := false
+	handler := openapi.Middleware(builder.OpenAPI())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if r.URL.Path == "/unknown" {
+			return
+		}
+		var pet CreatePet
+		if err := json.NewDecoder(r.Body).Decode(&pet); err != nil || pet.Name != "Rex" {
+			t.Errorf("expected the body to be readable by the handler, got %v %v", pet, err)
+		}
+	}))
+
+	serve := func(target, contentType, body string, header bool) *httptest.ResponseRecorder {
+		called = false
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		if header {
+			r.Header.Set("X-Tenant", "acme")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/owners/1/pets?dryRun=true", "application/json", `{"name":"Rex","age":3}`, true); w.Code != http.StatusOK || !called {
+		t.Errorf("expected valid request to pass, got %d %s", w.Code, w.Body.String())
+	}
+
+	w := serve("/owners/abc/pets?dryRun=maybe", "application/json", `{"name":"R","age":-1}`, false)
+	if w.Code != http.StatusUnprocessableEntity || called {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+	var body struct {
+		Errors []*openapi.ErrorDetail `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	locations := map[string]bool{}
+	for _, err := range body.Errors {
+		locations[err.Location] = true
+	}
+	for _, location := range []string{"path.ownerId", "query.dryRun", "header.X-Tenant", "body.name", "body.age"} {
+		if !locations[location] {
+			t.Errorf("expected error at %s, got %v", location, body.Errors)
+		}
+	}
+
+	if w := serve("/owners/1/pets", "text/plain", "Rex", true); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415, got %d", w.Code)
+	}
+	if w := serve("/owners/1/pets", "application/json", "", true); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected missing body to be rejected, got %d", w.Code)
+	}
+	if w := serve("/unknown", "text/plain", "", false); !called || w.Code != http.StatusOK {
+		t.Errorf("expected unknown routes to pass through, got %d", w.Code)
+	}
+}
+
+func TestMiddlewareMaxBodySize(t *testing.T) {
+	builder := openapi.New("title", "version")
+	createPet := builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/pets",
+	}).MaxBodySize(24)
+	createPet.Request().Body(CreatePet{})
+
+	handler := openapi.Middleware(builder.OpenAPI())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if status := serve(`{"name":"Rex","age":3}  `); status != http.StatusOK {
+		t.Errorf("expected a body of exactly the limit to pass, got %d", status)
+	}
+	if status := serve(`{"name":"Rexford","age":3}`); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", status)
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestMiddlewareUploads(t *testing.T) {
+	builder := openapi.New("title", "version")
+	upload := builder.Register(&openapi.Operation{
+		OperationID: "uploadAvatar",
+		Method:      http.MethodPut,
+		Path:        "/avatar",
+	})
+	upload.Request().ContentType("application/octet-stream").Body([]byte{})
+
+	content := strings.Repeat("x", 1<<16)
+	body := &countingReader{r: strings.NewReader(content)}
+	handler := openapi.Middleware(builder.OpenAPI())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body.read > 1 {
+			t.Errorf("expected the upload not to be buffered, %d bytes were read", body.read)
+		}
+		if b, err := io.ReadAll(r.Body); err != nil || string(b) != content {
+			t.Errorf("expected the whole upload to reach the handler, got %d bytes, %v", len(b), err)
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodPut, "/avatar", body)
+	r.Header.Set("Content-Type", "application/octet-stream")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the upload to pass, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddlewareUnresolvedParam(t *testing.T) {
+	builder := openapi.New("title", "version")
+	listPets := builder.Register(&openapi.Operation{
+		OperationID: "listPets",
+		Method:      http.MethodGet,
+		Path:        "/pets",
+	})
+	listPets.Request().QueryParam("kind", openapi.StringType)
+	builder.OpenAPI().Paths["/pets"].Get.Parameters[0].Schema = &openapi.Schema{Ref: "#/components/schemas/Missing"}
+
+	handler := openapi.Middleware(builder.OpenAPI())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets?kind=cat", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected params with an unresolved schema to be skipped, got %d", w.Code)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "reflect"
+
+// MinifyOptions configures what Minify keeps.
+type MinifyOptions struct {
+	// KeepDescriptions keeps descriptions. Response descriptions are always
+	// kept as the spec requires them.
+	KeepDescriptions bool
+
+	// KeepExamples keeps examples, including `components.examples`.
+	KeepExamples bool
+
+	// KeepExtensions lists vendor extensions which are kept, e.g.
+	// `x-ratelimit`. Every other extension is removed.
+	KeepExtensions []string
+}
+
+var responseType = reflect.TypeOf(Response{})
+
+// Minify returns a compact copy of the document for runtime validation or
+// uploading to a gateway, without descriptions, examples and vendor
+// extensions unless opts keeps them. The document itself is left untouched so
+// it can still be served as documentation.
+func (o *OpenAPI) Minify(opts MinifyOptions) *OpenAPI {
+	minified := o.Clone()
+
+	keep := map[string]bool{}
+	for _, name := range opts.KeepExtensions {
+		keep[name] = true
+	}
+
+	walkStructs(reflect.ValueOf(minified), func(v reflect.Value) {
+		if !opts.KeepDescriptions && v.Type() != responseType {
+			for _, name := range []string{"Description", "DescriptionKey"} {
+				if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+					f.SetString("")
+				}
+			}
+		}
+
+		if !opts.KeepExamples {
+			for _, name := range []string{"Example", "Examples"} {
+				if f := v.FieldByName(name); f.IsValid() && f.CanSet() {
+					f.Set(reflect.Zero(f.Type()))
+				}
+			}
+		}
+
+		if f := v.FieldByName("Extensions"); f.IsValid() && f.Len() > 0 {
+			extensions := f.Interface().(map[string]any)
+			for name := range extensions {
+				if !keep[name] {
+					delete(extensions, name)
+				}
+			}
+		}
+	})
+
+	minified.Invalidate()
+	return minified
+}
@@ -0,0 +1,50 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+type MinifiedUser struct {
+	Name string `json:"name" doc:"The name of the user" example:"Alice"`
+}
+
+func TestMinify(t *testing.T) {
+	builder := openapi.New("title", "version")
+	op := builder.Register(&openapi.Operation{
+		OperationID: "createUser",
+		Method:      http.MethodPost,
+		Path:        "/users",
+		Description: "Creates a user",
+	})
+	op.Extension("x-internal", true)
+	op.RateLimit(10, time.Minute)
+	op.Request().Body(MinifiedUser{}).Example(`{"name": "Bob"}`)
+	op.Response(http.StatusCreated).Description("Created")
+
+	o := builder.OpenAPI()
+	minified := o.Minify(openapi.MinifyOptions{KeepExtensions: []string{"x-ratelimit"}})
+
+	data, err := minified.JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, removed := range []string{"Creates a user", "The name of the user", "Alice", "Bob", "x-internal"} {
+		if strings.Contains(string(data), removed) {
+			t.Errorf("expected %q to be removed, got %s", removed, data)
+		}
+	}
+	for _, kept := range []string{"x-ratelimit", `"description":"Created"`} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("expected %q to be kept, got %s", kept, data)
+		}
+	}
+
+	if o.Paths["/users"].Post.Description != "Creates a user" {
+		t.Errorf("expected the document to be untouched")
+	}
+}
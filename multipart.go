@@ -0,0 +1,169 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"io"
+	"mime/multipart"
+	"os"
+	"reflect"
+	"strings"
+)
+
+var (
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	osFileType     = reflect.TypeOf(os.File{})
+	readerType     = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// Multipart sets a `multipart/form-data` body with a part per field of the
+// struct f, e.g. for file uploads. Fields of type `[]byte`,
+// `*multipart.FileHeader`, `multipart.File` and other io.Readers, or slices
+// of them, are `format: binary` parts, and the other fields are documented
+// as usual. Parts are named after their `form` tag, falling back to the
+// `json` tag, and the `contentMediaType` tag sets the content type of the
+// part in its Encoding, e.g.
+//
+//	type Upload struct {
+//		Avatar      *multipart.FileHeader `form:"avatar" contentMediaType:"image/png,image/jpeg"`
+//		Description string                `form:"description,omitempty" maxLength:"200"`
+//	}
+//
+//	op.Request().Multipart(Upload{}).Encoding("avatar").Header("X-Checksum", "")
+func (rb *RequestBuilder) Multipart(f any) *RequestBodyBuilder {
+	registry := rb.openAPI.Components.Schemas
+
+	return rb.formBody(f, "multipart/form-data", func(t reflect.Type, field reflect.StructField) (*Schema, *Encoding) {
+		var fs *Schema
+		switch {
+		case isFilePart(field.Type):
+			fs = &Schema{Type: TypeString, Format: "binary", Description: field.Tag.Get("doc")}
+		case field.Type.Kind() == reflect.Slice && isFilePart(field.Type.Elem()):
+			fs = &Schema{Type: TypeArray, Items: &Schema{Type: TypeString, Format: "binary"}, Description: field.Tag.Get("doc")}
+		default:
+			fs = SchemaFromField(registry, field, t.Name()+field.Name+"Struct")
+		}
+
+		if contentType := field.Tag.Get("contentMediaType"); contentType != "" {
+			return fs, &Encoding{ContentType: contentType}
+		}
+		return fs, nil
+	})
+}
+
+// Form sets an `application/x-www-form-urlencoded` body with a property per
+// field of the struct f, e.g. for OAuth token endpoints. Properties are named
+// after their `form` tag, falling back to the `json` tag, and the `style`,
+// `explode` and `allowReserved` tags set how the property is serialized in
+// its Encoding, e.g.
+//
+//	type TokenRequest struct {
+//		GrantType string   `form:"grant_type" enum:"client_credentials,refresh_token"`
+//		Scope     []string `form:"scope,omitempty" style:"spaceDelimited" explode:"false"`
+//	}
+//
+//	op.Request().Form(TokenRequest{})
+func (rb *RequestBuilder) Form(f any) *RequestBodyBuilder {
+	registry := rb.openAPI.Components.Schemas
+
+	return rb.formBody(f, "application/x-www-form-urlencoded", func(t reflect.Type, field reflect.StructField) (*Schema, *Encoding) {
+		fs := SchemaFromField(registry, field, t.Name()+field.Name+"Struct")
+
+		encoding := &Encoding{
+			Style:         field.Tag.Get("style"),
+			AllowReserved: boolTag(field, "allowReserved"),
+		}
+		if _, ok := field.Tag.Lookup("explode"); ok {
+			explode := boolTag(field, "explode")
+			encoding.Explode = &explode
+		}
+		if encoding.Style == "" && encoding.Explode == nil && !encoding.AllowReserved {
+			return fs, nil
+		}
+		return fs, encoding
+	})
+}
+
+// formBody sets a body of the content type with a property per field of the
+// struct f, whose schema and encoding are returned by part. Fields without a
+// schema are left out.
+func (rb *RequestBuilder) formBody(f any, contentType string, part func(t reflect.Type, field reflect.StructField) (*Schema, *Encoding)) *RequestBodyBuilder {
+	t := deref(reflect.TypeOf(f))
+	if t.Kind() != reflect.Struct {
+		panic(contentType + " body must be a struct, got " + t.String())
+	}
+
+	mode := RequiredUnlessOmitEmpty
+	if mr, ok := rb.openAPI.Components.Schemas.(*mapRegistry); ok {
+		mode = mr.required
+	}
+
+	s := &Schema{
+		Type:       TypeObject,
+		Properties: map[string]*Schema{},
+	}
+	encodings := map[string]*Encoding{}
+	seen := map[string]bool{}
+	for _, info := range getFields(t, map[reflect.Type]struct{}{}) {
+		field := info.Field
+		if seen[field.Name] || boolTag(field, "hidden") {
+			continue
+		}
+		seen[field.Name] = true
+
+		name, tag := field.Name, field.Tag.Get("form")
+		if tag == "" {
+			tag = field.Tag.Get("json")
+		}
+		if n, _, _ := strings.Cut(tag, ","); n != "" {
+			name = n
+		}
+		if name == "-" {
+			continue
+		}
+
+		required := mode == RequiredAll || (mode == RequiredUnlessOmitEmpty && !strings.Contains(tag, "omitempty"))
+		if _, ok := field.Tag.Lookup("required"); ok {
+			required = boolTag(field, "required")
+		}
+
+		fs, encoding := part(t, field)
+		if fs == nil {
+			continue
+		}
+		if encoding != nil {
+			encodings[name] = encoding
+		}
+
+		s.Properties[name] = fs
+		s.propertyNames = append(s.propertyNames, name)
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	s.PrecomputeMessages()
+
+	rb.nextContentType = contentType
+	rbb := rb.body(s)
+	if len(encodings) > 0 {
+		rbb.mediaTypeBuilder.mediaType.Encoding = encodings
+	}
+
+	return rbb
+}
+
+// isFilePart returns whether values of type t are uploaded as binary parts.
+func isFilePart(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return true
+	}
+	if t.Kind() == reflect.Interface {
+		return t.Implements(readerType)
+	}
+	t = deref(t)
+	return t == fileHeaderType || t == osFileType
+}
@@ -0,0 +1,103 @@
+package openapi_test
+
+import (
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type MultipartUpload struct {
+	Avatar      *multipart.FileHeader   `form:"avatar" contentMediaType:"image/png,image/jpeg"`
+	Attachments []*multipart.FileHeader `form:"attachments,omitempty"`
+	Thumbnail   []byte                  `form:"thumbnail,omitempty"`
+	Description string                  `json:"description,omitempty" maxLength:"200"`
+	Metadata    map[string]string       `form:"metadata,omitempty" contentMediaType:"application/json"`
+}
+
+func TestMultipart(t *testing.T) {
+	builder := openapi.New("title", "version")
+	upload := builder.Register(&openapi.Operation{
+		OperationID: "uploadAvatar",
+		Method:      http.MethodPost,
+		Path:        "/avatars",
+	})
+	upload.Request().Multipart(MultipartUpload{}).
+		Encoding("avatar").Header("X-Checksum", "")
+
+	mt := builder.OpenAPI().Paths["/avatars"].Post.RequestBody.Content["multipart/form-data"]
+	if mt == nil {
+		t.Fatalf("expected multipart/form-data content")
+	}
+
+	s := mt.Schema
+	if p := s.Properties["avatar"]; p.Type != openapi.TypeString || p.Format != "binary" {
+		t.Errorf("unexpected avatar part %v", p)
+	}
+	if p := s.Properties["attachments"]; p.Type != openapi.TypeArray || p.Items.Format != "binary" {
+		t.Errorf("unexpected attachments part %v", p)
+	}
+	if p := s.Properties["thumbnail"]; p.Format != "binary" {
+		t.Errorf("unexpected thumbnail part %v", p)
+	}
+	if p := s.Properties["description"]; p.Type != openapi.TypeString || *p.MaxLength != 200 {
+		t.Errorf("unexpected description part %v", p)
+	}
+	if len(s.Required) != 1 || s.Required[0] != "avatar" {
+		t.Errorf("expected only the avatar to be required, got %v", s.Required)
+	}
+
+	if e := mt.Encoding["avatar"]; e.ContentType != "image/png,image/jpeg" || e.Headers["X-Checksum"] == nil {
+		t.Errorf("unexpected avatar encoding %v", e)
+	}
+	if e := mt.Encoding["metadata"]; e.ContentType != "application/json" {
+		t.Errorf("unexpected metadata encoding %v", e)
+	}
+	if _, ok := builder.OpenAPI().Components.Schemas.Map()["FileHeader"]; ok {
+		t.Errorf("expected no schema for multipart.FileHeader")
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("unexpected validation errors %v", errs)
+	}
+}
+
+type FormTokenRequest struct {
+	GrantType   string   `form:"grant_type" enum:"client_credentials,refresh_token"`
+	Scope       []string `form:"scope,omitempty" style:"spaceDelimited" explode:"false"`
+	RedirectURI string   `form:"redirect_uri,omitempty" allowReserved:"true"`
+	Internal    string   `form:"-"`
+}
+
+func TestForm(t *testing.T) {
+	builder := openapi.New("title", "version")
+	token := builder.Register(&openapi.Operation{
+		OperationID: "createToken",
+		Method:      http.MethodPost,
+		Path:        "/oauth/token",
+	})
+	token.Request().Form(FormTokenRequest{}).Description("Token request")
+
+	body := builder.OpenAPI().Paths["/oauth/token"].Post.RequestBody
+	mt := body.Content["application/x-www-form-urlencoded"]
+	if mt == nil || body.Description != "Token request" {
+		t.Fatalf("expected application/x-www-form-urlencoded content, got %v", body)
+	}
+
+	s := mt.Schema
+	if len(s.Properties) != 3 || len(s.Properties["grant_type"].Enum) != 2 || s.Properties["scope"].Type != openapi.TypeArray {
+		t.Errorf("unexpected properties %v", s.Properties)
+	}
+	if len(s.Required) != 1 || s.Required[0] != "grant_type" {
+		t.Errorf("expected only grant_type to be required, got %v", s.Required)
+	}
+	if e := mt.Encoding["scope"]; e.Style != "spaceDelimited" || e.Explode == nil || *e.Explode {
+		t.Errorf("unexpected scope encoding %v", e)
+	}
+	if e := mt.Encoding["redirect_uri"]; !e.AllowReserved {
+		t.Errorf("unexpected redirect_uri encoding %v", e)
+	}
+	if _, ok := mt.Encoding["grant_type"]; ok {
+		t.Errorf("expected no encoding for grant_type")
+	}
+}
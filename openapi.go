@@ -8,13 +8,25 @@ package openapi
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
 
 	"github.com/restk/openapi/yaml"
 )
 
+// MethodQuery is the HTTP QUERY method, a safe and idempotent request with a
+// body. Operations using it are stored in PathItem.AdditionalOperations.
+const MethodQuery = "QUERY"
+
+var methodRe = regexp.MustCompile(`^[A-Z][A-Z0-9_-]*$`)
+
 type omitType int
 
 const (
@@ -607,6 +619,10 @@ type RequestBody struct {
 	// Extensions (user-defined properties), if any. Values in this map will
 	// be marshalled as siblings of the other properties above.
 	Extensions map[string]any `yaml:",inline"`
+
+	// allowOnSafeMethod silences the warning for bodies of GET, HEAD and
+	// DELETE operations, see RequestBodyBuilder.AllowOnSafeMethod.
+	allowOnSafeMethod bool
 }
 
 func (r *RequestBody) MarshalJSON() ([]byte, error) {
@@ -905,9 +921,19 @@ type Operation struct {
 	// Extensions (user-defined properties), if any. Values in this map will
 	// be marshalled as siblings of the other properties above.
 	Extensions map[string]any `yaml:",inline"`
+
+	// responseOrder is the order in which responses were added, and
+	// orderResponses is set on the copies marshalled for OrderRegistration.
+	responseOrder  []string
+	orderResponses bool
 }
 
 func (o *Operation) MarshalJSON() ([]byte, error) {
+	var responses any = o.Responses
+	if o.orderResponses && len(o.Responses) > 0 {
+		responses = newOrderedMap(o.Responses, registrationOrder(o.Responses, o.responseOrder))
+	}
+
 	return marshalJSON([]jsonFieldInfo{
 		{"tags", o.Tags, omitEmpty},
 		{"summary", o.Summary, omitEmpty},
@@ -916,7 +942,7 @@ func (o *Operation) MarshalJSON() ([]byte, error) {
 		{"operationId", o.OperationID, omitEmpty},
 		{"parameters", o.Parameters, omitEmpty},
 		{"requestBody", o.RequestBody, omitEmpty},
-		{"responses", o.Responses, omitEmpty},
+		{"responses", responses, omitEmpty},
 		{"callbacks", o.Callbacks, omitEmpty},
 		{"deprecated", o.Deprecated, omitEmpty},
 		{"security", o.Security, omitEmpty},
@@ -996,6 +1022,12 @@ type PathItem struct {
 	// Trace is a definition of a TRACE operation on this path.
 	Trace *Operation `yaml:"trace,omitempty"`
 
+	// AdditionalOperations holds operations for methods other than the eight
+	// above, keyed by method, such as the QUERY method. OpenAPI 3.1 has no
+	// field for them, so they are marshalled as `x-additionalOperations`,
+	// mirroring the `additionalOperations` field of later versions.
+	AdditionalOperations map[string]*Operation `yaml:"x-additionalOperations,omitempty"`
+
 	// Servers is an alternative server array to service all operations in this
 	// path.
 	Servers []*Server `yaml:"servers,omitempty"`
@@ -1026,11 +1058,118 @@ func (p *PathItem) MarshalJSON() ([]byte, error) {
 		{"head", p.Head, omitEmpty},
 		{"patch", p.Patch, omitEmpty},
 		{"trace", p.Trace, omitEmpty},
+		{"x-additionalOperations", p.AdditionalOperations, omitEmpty},
 		{"servers", p.Servers, omitEmpty},
 		{"parameters", p.Parameters, omitEmpty},
 	}, p.Extensions)
 }
 
+// SetOperation sets the operation for `op.Method` on the path item, replacing
+// any existing operation for that method. Methods other than the standard
+// eight, e.g. MethodQuery, are stored in AdditionalOperations. It panics if
+// the method is not an upper case token.
+func (p *PathItem) SetOperation(op *Operation) {
+	switch op.Method {
+	case http.MethodGet:
+		p.Get = op
+	case http.MethodPost:
+		p.Post = op
+	case http.MethodPut:
+		p.Put = op
+	case http.MethodPatch:
+		p.Patch = op
+	case http.MethodDelete:
+		p.Delete = op
+	case http.MethodHead:
+		p.Head = op
+	case http.MethodOptions:
+		p.Options = op
+	case http.MethodTrace:
+		p.Trace = op
+	default:
+		if !methodRe.MatchString(op.Method) {
+			panic("invalid method " + op.Method + ", methods must be upper case")
+		}
+		if p.AdditionalOperations == nil {
+			p.AdditionalOperations = map[string]*Operation{}
+		}
+		p.AdditionalOperations[op.Method] = op
+	}
+}
+
+// RemoveOperation removes the operation for the given HTTP method, if any.
+func (p *PathItem) RemoveOperation(method string) {
+	switch method {
+	case http.MethodGet:
+		p.Get = nil
+	case http.MethodPost:
+		p.Post = nil
+	case http.MethodPut:
+		p.Put = nil
+	case http.MethodPatch:
+		p.Patch = nil
+	case http.MethodDelete:
+		p.Delete = nil
+	case http.MethodHead:
+		p.Head = nil
+	case http.MethodOptions:
+		p.Options = nil
+	case http.MethodTrace:
+		p.Trace = nil
+	default:
+		delete(p.AdditionalOperations, method)
+		if len(p.AdditionalOperations) == 0 {
+			p.AdditionalOperations = nil
+		}
+	}
+}
+
+// Operation returns the operation for the given HTTP method, or nil if the
+// path item has no operation for it.
+func (p *PathItem) Operation(method string) *Operation {
+	var op *Operation
+	p.WalkOperations(func(m string, o *Operation) {
+		if m == method {
+			op = o
+		}
+	})
+	return op
+}
+
+// WalkOperations calls fn for every operation on the path item in a stable
+// order. The method passed to fn is always the canonical upper case HTTP
+// method, even if `op.Method` was never set.
+func (p *PathItem) WalkOperations(fn func(method string, op *Operation)) {
+	for _, entry := range []struct {
+		method string
+		op     *Operation
+	}{
+		{http.MethodGet, p.Get},
+		{http.MethodPut, p.Put},
+		{http.MethodPost, p.Post},
+		{http.MethodDelete, p.Delete},
+		{http.MethodOptions, p.Options},
+		{http.MethodHead, p.Head},
+		{http.MethodPatch, p.Patch},
+		{http.MethodTrace, p.Trace},
+	} {
+		if entry.op != nil {
+			fn(entry.method, entry.op)
+		}
+	}
+
+	methods := make([]string, 0, len(p.AdditionalOperations))
+	for method := range p.AdditionalOperations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		if op := p.AdditionalOperations[method]; op != nil {
+			fn(method, op)
+		}
+	}
+}
+
 // OAuthFlow stores configuration details for a supported OAuth Flow.
 //
 //	type: oauth2
@@ -1100,6 +1239,25 @@ type OAuthFlows struct {
 	Extensions map[string]any `yaml:",inline"`
 }
 
+// scopes returns the set of scopes declared by any of the flows.
+func (o *OAuthFlows) scopes() map[string]bool {
+	scopes := map[string]bool{}
+	if o == nil {
+		return scopes
+	}
+
+	for _, flow := range []*OAuthFlow{o.Implicit, o.Password, o.ClientCredentials, o.AuthorizationCode} {
+		if flow == nil {
+			continue
+		}
+		for scope := range flow.Scopes {
+			scopes[scope] = true
+		}
+	}
+
+	return scopes
+}
+
 func (o *OAuthFlows) MarshalJSON() ([]byte, error) {
 	return marshalJSON([]jsonFieldInfo{
 		{"implicit", o.Implicit, omitEmpty},
@@ -1282,9 +1440,19 @@ type Components struct {
 	// Extensions (user-defined properties), if any. Values in this map will
 	// be marshalled as siblings of the other properties above.
 	Extensions map[string]any `yaml:",inline"`
+
+	// securitySchemeOrder is the order in which security schemes were added,
+	// and orderSecuritySchemes is set on the copy marshalled for
+	// OrderRegistration.
+	securitySchemeOrder  []string
+	orderSecuritySchemes bool
 }
 
 func (c *Components) MarshalJSON() ([]byte, error) {
+	var securitySchemes any = c.SecuritySchemes
+	if c.orderSecuritySchemes && len(c.SecuritySchemes) > 0 {
+		securitySchemes = newOrderedMap(c.SecuritySchemes, registrationOrder(c.SecuritySchemes, c.securitySchemeOrder))
+	}
 	return marshalJSON([]jsonFieldInfo{
 		{"schemas", c.Schemas, omitEmpty},
 		{"responses", c.Responses, omitEmpty},
@@ -1292,7 +1460,7 @@ func (c *Components) MarshalJSON() ([]byte, error) {
 		{"examples", c.Examples, omitEmpty},
 		{"requestBodies", c.RequestBodies, omitEmpty},
 		{"headers", c.Headers, omitEmpty},
-		{"securitySchemes", c.SecuritySchemes, omitEmpty},
+		{"securitySchemes", securitySchemes, omitEmpty},
 		{"links", c.Links, omitEmpty},
 		{"callbacks", c.Callbacks, omitEmpty},
 		{"pathItems", c.PathItems, omitEmpty},
@@ -1418,6 +1586,29 @@ type OpenAPI struct {
 	// `AddOperation`. You may bypass this by directly writing to the `Paths`
 	// map instead.
 	OnAddOperation []AddOpFunc `yaml:"-"`
+
+	// Catalog holds the translations used by `Localize`.
+	Catalog Catalog `yaml:"-"`
+
+	// Hooks are notified of operations being added and of the document being
+	// served, see Builder.Hooks.
+	Hooks *Hooks `yaml:"-"`
+
+	// Layout controls the order of the emitted paths, schemas, responses and
+	// security schemes, see Builder.Layout.
+	Layout Layout `yaml:"-"`
+
+	// pathOrder is the order in which paths were added, for OrderRegistration.
+	pathOrder []string
+
+	// building is set for the document of a Builder, which is never cached as
+	// the builders returned by its methods keep modifying it.
+	building bool
+
+	// jsonBytes and yamlBytes cache the encodings returned by JSONBytes and
+	// YAMLBytes until Invalidate is called.
+	jsonBytes atomic.Value
+	yamlBytes atomic.Value
 }
 
 // AddOperation adds an operation to the OpenAPI. This is the preferred way to
@@ -1433,49 +1624,158 @@ func (o *OpenAPI) AddOperation(op *Operation) {
 	if item == nil {
 		item = &PathItem{}
 		o.Paths[op.Path] = item
+		o.pathOrder = append(o.pathOrder, op.Path)
 	}
 
-	switch op.Method {
-	case http.MethodGet:
-		item.Get = op
-	case http.MethodPost:
-		item.Post = op
-	case http.MethodPut:
-		item.Put = op
-	case http.MethodPatch:
-		item.Patch = op
-	case http.MethodDelete:
-		item.Delete = op
-	case http.MethodHead:
-		item.Head = op
-	case http.MethodOptions:
-		item.Options = op
-	case http.MethodTrace:
-		item.Trace = op
-	default:
-		panic("unknown method " + op.Method)
-	}
+	item.SetOperation(op)
+	o.Invalidate()
 
+	o.Hooks.operationRegistered(op)
 	for _, f := range o.OnAddOperation {
 		f(o, op)
 	}
 }
 
+// AddPathItem adds a full path item to the OpenAPI, including its summary,
+// description, servers and parameters. If the path already exists, the
+// fields set on item are merged into the existing path item and its
+// operations replace those with the same method. Like AddOperation, this
+// calls any registered OnAddOperation functions for the operations of item.
+func (o *OpenAPI) AddPathItem(path string, item *PathItem) {
+	if o.Paths == nil {
+		o.Paths = map[string]*PathItem{}
+	}
+
+	existing := o.Paths[path]
+	if existing == nil {
+		existing = item
+		o.Paths[path] = item
+		o.pathOrder = append(o.pathOrder, path)
+	} else {
+		if item.Ref != "" {
+			existing.Ref = item.Ref
+		}
+		if item.Summary != "" {
+			existing.Summary = item.Summary
+		}
+		if item.Description != "" {
+			existing.Description = item.Description
+		}
+		existing.Servers = append(existing.Servers, item.Servers...)
+		existing.Parameters = append(existing.Parameters, item.Parameters...)
+		for k, v := range item.Extensions {
+			if existing.Extensions == nil {
+				existing.Extensions = map[string]any{}
+			}
+			existing.Extensions[k] = v
+		}
+	}
+
+	item.WalkOperations(func(method string, op *Operation) {
+		op.Method = method
+		if op.Path == "" {
+			op.Path = path
+		}
+		existing.SetOperation(op)
+		o.Invalidate()
+
+		o.Hooks.operationRegistered(op)
+		for _, f := range o.OnAddOperation {
+			f(o, op)
+		}
+	})
+}
+
+// PathItem returns the path item for path, or nil if there is none.
+func (o *OpenAPI) PathItem(path string) *PathItem {
+	return o.Paths[path]
+}
+
+// WalkOperations calls fn for every operation in `paths`, sorted by path and
+// then by method. Webhooks and callbacks are not included.
+func (o *OpenAPI) WalkOperations(fn func(path, method string, op *Operation)) {
+	paths := make([]string, 0, len(o.Paths))
+	for path := range o.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		o.Paths[path].WalkOperations(func(method string, op *Operation) {
+			fn(path, method, op)
+		})
+	}
+}
+
+// findOperation returns the path and operation with the given operation ID.
+func (o *OpenAPI) findOperation(operationID string) (string, *Operation) {
+	var foundPath string
+	var found *Operation
+	o.WalkOperations(func(path, method string, op *Operation) {
+		if found == nil && op.OperationID == operationID {
+			foundPath, found = path, op
+		}
+	})
+	return foundPath, found
+}
+
+// operationParams returns the parameters of the path item and the operation,
+// with references to components resolved. Operation parameters override path
+// item parameters with the same name and location.
+func (o *OpenAPI) operationParams(path string, op *Operation) []*Param {
+	var params []*Param
+	index := map[[2]string]int{}
+
+	var items []*Param
+	if item := o.Paths[path]; item != nil {
+		items = item.Parameters
+	}
+
+	for _, p := range append(append([]*Param{}, items...), op.Parameters...) {
+		if p.Ref != "" && o.Components != nil {
+			if resolved := o.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; resolved != nil {
+				p = resolved
+			}
+		}
+
+		key := [2]string{p.In, p.Name}
+		if i, ok := index[key]; ok {
+			params[i] = p
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, p)
+	}
+
+	return params
+}
+
 func (o *OpenAPI) MarshalJSON() ([]byte, error) {
 	return marshalJSON([]jsonFieldInfo{
 		{"openapi", o.OpenAPI, omitNever},
 		{"info", o.Info, omitNever},
 		{"jsonSchemaDialect", o.JSONSchemaDialect, omitEmpty},
 		{"servers", o.Servers, omitEmpty},
-		{"paths", o.Paths, omitEmpty},
-		{"webhooks", o.Webhooks, omitEmpty},
-		{"components", o.Components, omitEmpty},
+		{"paths", o.orderPaths(o.Paths), omitEmpty},
+		{"webhooks", o.orderPaths(o.Webhooks), omitEmpty},
+		{"components", o.orderComponents(), omitEmpty},
 		{"security", o.Security, omitEmpty},
 		{"tags", o.Tags, omitEmpty},
 		{"externalDocs", o.ExternalDocs, omitEmpty},
 	}, o.Extensions)
 }
 
+// Hash returns the hex encoded SHA-256 hash of the JSON representation of the
+// OpenAPI. It changes whenever the published contract changes.
+func (o *OpenAPI) Hash() (string, error) {
+	specJSON, err := o.JSONBytes()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(specJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // YAML returns the OpenAPI represented as YAML without needing to include a
 // library to serialize YAML.
 func (o *OpenAPI) YAML() ([]byte, error) {
@@ -1487,6 +1787,54 @@ func (o *OpenAPI) YAML() ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// JSONBytes returns the OpenAPI represented as JSON. Unlike json.Marshal, the
+// encoding is cached so serving a large document doesn't marshal it on every
+// request. AddOperation and AddPathItem invalidate the cache, other changes to
+// the document must be followed by a call to Invalidate. The document of a
+// Builder, see Builder.OpenAPI, is never cached, so serve the document
+// returned by Builder.Build.
+func (o *OpenAPI) JSONBytes() ([]byte, error) {
+	if cached, _ := o.jsonBytes.Load().([]byte); cached != nil {
+		return cached, nil
+	}
+
+	specJSON, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	if !o.building {
+		o.jsonBytes.Store(specJSON)
+	}
+	return specJSON, nil
+}
+
+// YAMLBytes is like YAML but caches the encoding, see JSONBytes.
+func (o *OpenAPI) YAMLBytes() ([]byte, error) {
+	if cached, _ := o.yamlBytes.Load().([]byte); cached != nil {
+		return cached, nil
+	}
+
+	specJSON, err := o.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer([]byte{})
+	if err := yaml.Convert(buf, bytes.NewReader(specJSON)); err != nil {
+		return nil, err
+	}
+	if !o.building {
+		o.yamlBytes.Store(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// Invalidate drops the encodings cached by JSONBytes and YAMLBytes. Call it
+// after changing the document directly, e.g. through its fields.
+func (o *OpenAPI) Invalidate() {
+	o.jsonBytes.Store([]byte(nil))
+	o.yamlBytes.Store([]byte(nil))
+}
+
 func downgradeSpec(input any) {
 	switch value := input.(type) {
 	case map[string]any:
@@ -0,0 +1,382 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapitest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/restk/openapi"
+)
+
+// Seed is a fuzz seed input of an operation, see Seeds.
+type Seed struct {
+	// Name describes the input, e.g. `valid` or `body-name-above-max-length`.
+	Name string
+
+	// Valid is false for inputs which break a single constraint of the spec.
+	Valid bool
+
+	// Target is the request target with the path and query params, e.g.
+	// `/users/42?limit=10`.
+	Target string
+
+	// Body is the request body, if the operation has a JSON one.
+	Body []byte
+}
+
+// Seeds returns the fuzz seed inputs of the operation at method and path: a
+// valid request generated with Gen, followed by boundary-invalid ones which
+// are just past a single constraint of a path or query param or of a top
+// level property of the JSON request body, e.g. a string one character longer
+// than its `maxLength`. Header and cookie params are left out.
+func Seeds(o *openapi.OpenAPI, method, path string, rand *rand.Rand) []*Seed {
+	item := o.Paths[path]
+	if item == nil || item.Operation(method) == nil {
+		panic("operation " + method + " " + path + " does not exist")
+	}
+	op := item.Operation(method)
+	registry := o.Components.Schemas
+
+	var params []*openapi.Param
+	for _, p := range append(append([]*openapi.Param{}, item.Parameters...), op.Parameters...) {
+		if p.Ref != "" {
+			p = o.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+		}
+		if p != nil && p.Schema != nil && (p.In == "path" || p.In == "query") {
+			params = append(params, p)
+		}
+	}
+
+	values := map[*openapi.Param]any{}
+	for _, p := range params {
+		values[p] = Gen(registry, p.Schema).Value(rand)
+	}
+
+	var body *openapi.Schema
+	if op.RequestBody != nil {
+		for _, contentType := range sortedKeys(op.RequestBody.Content) {
+			if strings.Contains(contentType, "json") && op.RequestBody.Content[contentType].Schema != nil {
+				body = op.RequestBody.Content[contentType].Schema
+				break
+			}
+		}
+	}
+	var bodyValue any
+	if body != nil {
+		bodyValue = Gen(registry, body).Value(rand)
+	}
+
+	target := func(values map[*openapi.Param]any) string {
+		target := path
+		query := url.Values{}
+		for _, p := range params {
+			v, ok := values[p]
+			if !ok {
+				continue
+			}
+			if p.In == "path" {
+				target = strings.ReplaceAll(target, "{"+p.Name+"}", url.PathEscape(strings.Join(paramStrings(p, v), ",")))
+				continue
+			}
+			query[p.Name] = paramStrings(p, v)
+		}
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+		return target
+	}
+	encode := func(v any) []byte {
+		if body == nil {
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}
+
+	seeds := []*Seed{{
+		Name:   "valid",
+		Valid:  true,
+		Target: target(values),
+		Body:   encode(bodyValue),
+	}}
+
+	for _, p := range params {
+		with := func(v any, ok bool) map[*openapi.Param]any {
+			invalid := map[*openapi.Param]any{}
+			for k, v := range values {
+				invalid[k] = v
+			}
+			if ok {
+				invalid[p] = v
+			} else {
+				delete(invalid, p)
+			}
+			return invalid
+		}
+
+		if p.Required && p.In == "query" {
+			seeds = append(seeds, &Seed{
+				Name:   p.In + "-" + p.Name + "-missing",
+				Target: target(with(nil, false)),
+				Body:   encode(bodyValue),
+			})
+		}
+		for _, boundary := range boundaries(registry, p.Schema, rand) {
+			seeds = append(seeds, &Seed{
+				Name:   p.In + "-" + p.Name + "-" + boundary.name,
+				Target: target(with(boundary.value, true)),
+				Body:   encode(bodyValue),
+			})
+		}
+	}
+
+	if body != nil {
+		valid := target(values)
+		seeds = append(seeds, &Seed{Name: "body-malformed", Target: valid, Body: []byte("{")})
+		if op.RequestBody.Required {
+			seeds = append(seeds, &Seed{Name: "body-empty", Target: valid, Body: []byte{}})
+		}
+
+		s := resolve(registry, body)
+		m, isObject := bodyValue.(map[string]any)
+		if !isObject {
+			for _, boundary := range boundaries(registry, s, rand) {
+				seeds = append(seeds, &Seed{Name: "body-" + boundary.name, Target: valid, Body: encode(boundary.value)})
+			}
+		}
+
+		with := func(name string, v any, ok bool) map[string]any {
+			invalid := map[string]any{}
+			for k, v := range m {
+				invalid[k] = v
+			}
+			if ok {
+				invalid[name] = v
+			} else {
+				delete(invalid, name)
+			}
+			return invalid
+		}
+		for _, name := range s.Required {
+			if p := s.Properties[name]; isObject && p != nil && !p.ReadOnly {
+				seeds = append(seeds, &Seed{Name: "body-" + name + "-missing", Target: valid, Body: encode(with(name, nil, false))})
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if p := s.Properties[name]; isObject && !p.ReadOnly {
+				for _, boundary := range boundaries(registry, p, rand) {
+					seeds = append(seeds, &Seed{Name: "body-" + name + "-" + boundary.name, Target: valid, Body: encode(with(name, boundary.value, true))})
+				}
+			}
+		}
+	}
+
+	return seeds
+}
+
+// WriteCorpus writes the seeds of every operation with an ID to
+// `dir/Fuzz<OperationID>`, where `go test -fuzz` reads the seed corpus of a
+// fuzz target from when dir is `testdata/fuzz`. The fuzz targets take the
+// request target and body:
+//
+//	openapitest.WriteCorpus("testdata/fuzz", builder.OpenAPI(), 1)
+//
+//	func FuzzCreateUser(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, target string, body []byte) {
+//			req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+//			...
+//		})
+//	}
+func WriteCorpus(dir string, o *openapi.OpenAPI, seed int64) error {
+	rand := rand.New(rand.NewSource(seed))
+
+	var err error
+	var paths []string
+	for path := range o.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		o.Paths[path].WalkOperations(func(method string, op *openapi.Operation) {
+			if err != nil || op.OperationID == "" {
+				return
+			}
+
+			r, size := utf8.DecodeRuneInString(op.OperationID)
+			target := filepath.Join(dir, "Fuzz"+string(unicode.ToUpper(r))+op.OperationID[size:])
+			if err = os.MkdirAll(target, 0o755); err != nil {
+				return
+			}
+			for _, s := range Seeds(o, method, path, rand) {
+				content := "go test fuzz v1\nstring(" + strconv.Quote(s.Target) + ")\n[]byte(" + strconv.Quote(string(s.Body)) + ")\n"
+				if err = os.WriteFile(filepath.Join(target, fileName(s.Name)), []byte(content), 0o644); err != nil {
+					return
+				}
+			}
+		})
+	}
+	return err
+}
+
+// fileName replaces the characters of name which are not safe in file names.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// paramStrings returns the values of the param p for the request target, as
+// the form style does.
+func paramStrings(p *openapi.Param, v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return []string{paramString(v)}
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = paramString(item)
+	}
+	if p.In == "query" && (p.Explode == nil || *p.Explode) && (p.Style == "" || p.Style == "form") {
+		return values
+	}
+	return []string{strings.Join(values, ",")}
+}
+
+func paramString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// boundary is a value just past a constraint of a schema.
+type boundary struct {
+	name  string
+	value any
+}
+
+// boundaries returns the values just past every constraint of s, and a value
+// of the wrong type.
+func boundaries(r openapi.Registry, s *openapi.Schema, rand *rand.Rand) []boundary {
+	s = resolve(r, s)
+
+	var b []boundary
+	switch s.Type {
+	case openapi.TypeString:
+		b = append(b, boundary{"wrong-type", 0.0})
+		if s.MinLength != nil && *s.MinLength > 0 {
+			b = append(b, boundary{"below-min-length", strings.Repeat("a", *s.MinLength-1)})
+		}
+		if s.MaxLength != nil {
+			b = append(b, boundary{"above-max-length", strings.Repeat("a", *s.MaxLength+1)})
+		}
+		if s.Format != "" {
+			b = append(b, boundary{"invalid-format", "%"})
+		}
+	case openapi.TypeInteger, openapi.TypeNumber:
+		b = append(b, boundary{"wrong-type", "a"})
+		if s.Minimum != nil {
+			b = append(b, boundary{"below-minimum", *s.Minimum - 1})
+		}
+		if s.ExclusiveMinimum != nil {
+			b = append(b, boundary{"at-exclusive-minimum", *s.ExclusiveMinimum})
+		}
+		if s.Maximum != nil {
+			b = append(b, boundary{"above-maximum", *s.Maximum + 1})
+		}
+		if s.ExclusiveMaximum != nil {
+			b = append(b, boundary{"at-exclusive-maximum", *s.ExclusiveMaximum})
+		}
+		if s.Type == openapi.TypeInteger {
+			b = append(b, boundary{"not-integer", 0.5})
+		}
+	case openapi.TypeBoolean, openapi.TypeObject:
+		b = append(b, boundary{"wrong-type", "a"})
+	case openapi.TypeArray:
+		b = append(b, boundary{"wrong-type", "a"})
+		items := func(n int) []any {
+			values := make([]any, n)
+			for i := range values {
+				if s.Items != nil {
+					values[i] = Gen(r, s.Items).Value(rand)
+				}
+			}
+			return values
+		}
+		if s.MinItems != nil && *s.MinItems > 0 {
+			b = append(b, boundary{"below-min-items", items(*s.MinItems - 1)})
+		}
+		if s.MaxItems != nil {
+			b = append(b, boundary{"above-max-items", items(*s.MaxItems + 1)})
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		b = append(b, boundary{"not-in-enum", notInEnum(s.Enum)})
+	}
+
+	return b
+}
+
+// notInEnum returns a value of the type of enum which is not one of its
+// values.
+func notInEnum(enum []any) any {
+	if _, ok := enum[0].(string); ok {
+		v := "invalid"
+		for contains(enum, v) {
+			v += "_"
+		}
+		return v
+	}
+
+	v := 0.0
+	for contains(enum, v) {
+		v++
+	}
+	return v
+}
+
+func contains(values []any, v any) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the schema s references.
+func resolve(r openapi.Registry, s *openapi.Schema) *openapi.Schema {
+	for s.Ref != "" {
+		if s = r.SchemaFromRef(s.Ref); s == nil {
+			panic("cannot resolve schema reference")
+		}
+	}
+	return s
+}
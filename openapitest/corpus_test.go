@@ -0,0 +1,97 @@
+package openapitest_test
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+	"github.com/restk/openapi/openapitest"
+)
+
+type corpusUser struct {
+	ID    int      `json:"id" readOnly:"true"`
+	Name  string   `json:"name" minLength:"2" maxLength:"20"`
+	Role  string   `json:"role" enum:"admin,member"`
+	Age   int      `json:"age" minimum:"18"`
+	Email string   `json:"email" format:"email"`
+	Tags  []string `json:"tags,omitempty" maxItems:"2"`
+}
+
+func corpusAPI() *openapi.Builder {
+	builder := openapi.New("title", "version")
+	updateUser := builder.Register(&openapi.Operation{
+		OperationID: "updateUser",
+		Method:      http.MethodPut,
+		Path:        "/users/{id}",
+	})
+	updateUser.Request().PathParam("id", openapi.IntType).Required(true)
+	updateUser.Request().QueryParam("limit", openapi.IntType).Required(true)
+	updateUser.Request().Body(corpusUser{})
+	updateUser.Response(http.StatusOK).Body(corpusUser{})
+	return builder
+}
+
+func TestSeeds(t *testing.T) {
+	o := corpusAPI().OpenAPI()
+	handler := openapi.Middleware(o)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	seeds := openapitest.Seeds(o, http.MethodPut, "/users/{id}", rand.New(rand.NewSource(1)))
+	names := map[string]bool{}
+	for _, seed := range seeds {
+		names[seed.Name] = true
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, seed.Target, bytes.NewReader(seed.Body))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(w, req)
+
+		if valid := w.Code == http.StatusOK; valid != seed.Valid {
+			t.Errorf("expected %s to be valid=%v, got %d for %s %s: %s", seed.Name, seed.Valid, w.Code, seed.Target, seed.Body, w.Body)
+		}
+	}
+
+	for _, name := range []string{
+		"valid",
+		"path-id-wrong-type",
+		"query-limit-missing",
+		"body-malformed",
+		"body-name-below-min-length",
+		"body-name-above-max-length",
+		"body-role-not-in-enum",
+		"body-age-below-minimum",
+		"body-email-invalid-format",
+		"body-tags-above-max-items",
+		"body-email-missing",
+	} {
+		if !names[name] {
+			t.Errorf("expected a %s seed, got %v", name, names)
+		}
+	}
+	if names["body-id-wrong-type"] {
+		t.Errorf("expected no seeds for read only properties")
+	}
+}
+
+func TestWriteCorpus(t *testing.T) {
+	dir := t.TempDir()
+	if err := openapitest.WriteCorpus(dir, corpusAPI().OpenAPI(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "FuzzUpdateUser", "valid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) != 4 || lines[0] != "go test fuzz v1" || !strings.HasPrefix(lines[1], `string("/users/`) || !strings.HasPrefix(lines[2], `[]byte("{`) {
+		t.Errorf("unexpected corpus file\n%s", b)
+	}
+}
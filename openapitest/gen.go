@@ -0,0 +1,507 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package openapitest generates random values which are valid for schemas,
+// so property-based tests can fuzz handlers with structurally valid,
+// constraint-respecting inputs, and seed corpora for Go's native fuzzing, see
+// WriteCorpus:
+//
+//	g := openapitest.Gen(registry, registry.Schema(reflect.TypeOf(User{}), true, ""))
+//
+//	// testing/quick
+//	quick.Check(func(u User) bool { ... }, &quick.Config{Values: g.Values(User{})})
+//
+//	// rapid
+//	rapid.Custom(func(t *rapid.T) any {
+//		return g.Value(rand.New(rand.NewSource(rapid.Int64().Draw(t, "seed"))))
+//	})
+package openapitest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"net"
+	"reflect"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/restk/openapi"
+)
+
+// maxDepth is the depth after which optional properties are left out and
+// arrays get their minimum number of items, so recursive schemas terminate.
+const maxDepth = 5
+
+// attempts is how many times a value is generated again when it does not
+// satisfy a constraint, e.g. unique items or a pattern with a length limit.
+const attempts = 10
+
+// Generator generates random values for a schema, see Gen.
+type Generator struct {
+	registry openapi.Registry
+	schema   *openapi.Schema
+	mode     openapi.ValidateMode
+}
+
+// Gen returns a generator of random values for the schema s, whose
+// references are resolved with the registry r. The values are generated for
+// openapi.ModeWriteToServer, i.e. as request bodies, see Generator.Mode.
+func Gen(r openapi.Registry, s *openapi.Schema) *Generator {
+	if s == nil {
+		panic("schema must be specified")
+	}
+
+	return &Generator{
+		registry: r,
+		schema:   s,
+		mode:     openapi.ModeWriteToServer,
+	}
+}
+
+// Mode sets the validation mode the values are generated for. Read only
+// properties are left out of values written to the server and write only
+// properties out of values read from the server.
+func (g *Generator) Mode(mode openapi.ValidateMode) *Generator {
+	g.mode = mode
+
+	return g
+}
+
+// Value returns a random value for the schema, as decoded by encoding/json
+// into an `any`, i.e. a `map[string]any`, `[]any`, `float64`, `string`,
+// `bool` or nil.
+func (g *Generator) Value(rand *rand.Rand) any {
+	return g.value(rand, g.schema, 0)
+}
+
+// JSON returns a random value for the schema encoded as JSON, e.g. to be sent
+// as a request body.
+func (g *Generator) JSON(rand *rand.Rand) []byte {
+	b, err := json.Marshal(g.Value(rand))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Values returns a function setting every argument to a random value for the
+// schema, decoded from JSON into the type of v, e.g. the Go type of the
+// schema, `json.RawMessage` or `map[string]any`. It is meant for
+// `quick.Config.Values`.
+func (g *Generator) Values(v any) func(args []reflect.Value, rand *rand.Rand) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("value must be specified")
+	}
+
+	return func(args []reflect.Value, rand *rand.Rand) {
+		for i := range args {
+			b := g.JSON(rand)
+
+			v := reflect.New(t)
+			if err := json.Unmarshal(b, v.Interface()); err != nil {
+				panic("cannot decode " + string(b) + " into " + t.String() + ": " + err.Error())
+			}
+			args[i] = v.Elem()
+		}
+	}
+}
+
+func (g *Generator) value(rand *rand.Rand, s *openapi.Schema, depth int) any {
+	s = resolve(g.registry, s)
+
+	if len(s.Enum) > 0 {
+		return s.Enum[rand.Intn(len(s.Enum))]
+	}
+	if s.Nullable && rand.Intn(8) == 0 {
+		return nil
+	}
+
+	switch {
+	case len(s.OneOf) > 0:
+		return g.value(rand, s.OneOf[rand.Intn(len(s.OneOf))], depth)
+	case len(s.AnyOf) > 0:
+		return g.value(rand, s.AnyOf[rand.Intn(len(s.AnyOf))], depth)
+	case len(s.AllOf) > 0:
+		return g.allOf(rand, s, depth)
+	}
+
+	switch s.Type {
+	case openapi.TypeNull:
+		return nil
+	case openapi.TypeBoolean:
+		return rand.Intn(2) == 0
+	case openapi.TypeInteger:
+		return g.number(rand, s, true)
+	case openapi.TypeNumber:
+		return g.number(rand, s, false)
+	case openapi.TypeString:
+		return g.string(rand, s)
+	case openapi.TypeArray:
+		return g.array(rand, s, depth)
+	case openapi.TypeObject:
+		return g.object(rand, s, depth)
+	case "":
+		if s.Properties != nil {
+			return g.object(rand, s, depth)
+		}
+		return g.string(rand, s)
+	}
+
+	panic("unsupported schema type " + s.Type)
+}
+
+// allOf merges the objects generated for every schema of s.AllOf. Values
+// which are not objects are returned as is.
+func (g *Generator) allOf(rand *rand.Rand, s *openapi.Schema, depth int) any {
+	var merged map[string]any
+	for _, sub := range s.AllOf {
+		v := g.value(rand, sub, depth)
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		if merged == nil {
+			merged = map[string]any{}
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	if s.Properties != nil {
+		for k, v := range g.object(rand, s, depth) {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// number returns a random number within the bounds of s, which is whole if
+// integer is true.
+func (g *Generator) number(rand *rand.Rand, s *openapi.Schema, integer bool) float64 {
+	lo, hi := -1000.0, 1000.0
+	if s.Minimum != nil {
+		lo = *s.Minimum
+		if s.Maximum == nil && s.ExclusiveMaximum == nil {
+			hi = lo + 1000
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+		if s.Minimum == nil && s.ExclusiveMinimum == nil {
+			lo = hi - 1000
+		}
+	}
+	if s.ExclusiveMinimum != nil {
+		lo = math.Max(lo, *s.ExclusiveMinimum)
+		if s.Maximum == nil && s.ExclusiveMaximum == nil {
+			hi = lo + 1000
+		}
+	}
+	if s.ExclusiveMaximum != nil {
+		hi = math.Min(hi, *s.ExclusiveMaximum)
+		if s.Minimum == nil && s.ExclusiveMinimum == nil {
+			lo = hi - 1000
+		}
+	}
+
+	valid := func(v float64) bool {
+		return (s.ExclusiveMinimum == nil || v > *s.ExclusiveMinimum) &&
+			(s.ExclusiveMaximum == nil || v < *s.ExclusiveMaximum) &&
+			v >= lo && v <= hi
+	}
+
+	step := 0.0
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		step = *s.MultipleOf
+	} else if integer {
+		step = 1
+	}
+
+	if step == 0 {
+		for i := 0; i < attempts; i++ {
+			if v := lo + rand.Float64()*(hi-lo); valid(v) {
+				return v
+			}
+		}
+		return lo + (hi-lo)/2
+	}
+
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if integer && step != math.Trunc(step) {
+		// Only the multiples of the step which are whole are valid, which are
+		// found by trying.
+		last = math.Min(last, first+1000)
+	}
+	for i := 0; i < attempts*10 && first <= last; i++ {
+		v := (first + float64(rand.Int63n(int64(last-first)+1))) * step
+		if valid(v) && (!integer || v == math.Trunc(v)) {
+			return v
+		}
+	}
+	for k := first; k <= last; k++ {
+		if v := k * step; valid(v) && (!integer || v == math.Trunc(v)) {
+			return v
+		}
+	}
+	panic("no valid number between " + strconv.FormatFloat(lo, 'g', -1, 64) + " and " + strconv.FormatFloat(hi, 'g', -1, 64))
+}
+
+// string returns a random string of the format, pattern and length of s.
+func (g *Generator) string(rand *rand.Rand, s *openapi.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return randomTime(rand).Format(time.RFC3339)
+	case "date-time-http":
+		return randomTime(rand).Format(time.RFC1123)
+	case "date":
+		return randomTime(rand).Format("2006-01-02")
+	case "time":
+		return randomTime(rand).Format("15:04:05")
+	case "email", "idn-email":
+		return randomWord(rand, 1+rand.Intn(10)) + "@example.com"
+	case "hostname":
+		return randomWord(rand, 1+rand.Intn(10)) + ".example.com"
+	case "ipv4":
+		return net.IPv4(byte(rand.Intn(256)), byte(rand.Intn(256)), byte(rand.Intn(256)), byte(rand.Intn(256))).String()
+	case "ipv6":
+		ip := make(net.IP, net.IPv6len)
+		rand.Read(ip)
+		ip[0] = 0x20
+		return ip.String()
+	case "uri", "uri-reference", "iri", "iri-reference":
+		return "https://example.com/" + randomWord(rand, rand.Intn(10))
+	case "uuid":
+		b := make([]byte, 16)
+		rand.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		u := hex.EncodeToString(b)
+		return u[0:8] + "-" + u[8:12] + "-" + u[12:16] + "-" + u[16:20] + "-" + u[20:32]
+	}
+
+	minLength, maxLength := 0, -1
+	if s.MinLength != nil {
+		minLength = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		maxLength = *s.MaxLength
+	}
+
+	if s.Pattern != "" {
+		re, err := syntax.Parse(s.Pattern, syntax.Perl)
+		if err != nil {
+			panic(err)
+		}
+		re = re.Simplify()
+
+		var str string
+		for i := 0; i < attempts; i++ {
+			sb := &strings.Builder{}
+			writeRegexp(rand, sb, re)
+			str = sb.String()
+			if n := utf8.RuneCountInString(str); n >= minLength && (maxLength < 0 || n <= maxLength) {
+				break
+			}
+		}
+		return str
+	}
+
+	if maxLength < 0 {
+		maxLength = minLength + 20
+	}
+	return randomWord(rand, minLength+rand.Intn(maxLength-minLength+1))
+}
+
+// array returns a random array with items of s.Items.
+func (g *Generator) array(rand *rand.Rand, s *openapi.Schema, depth int) []any {
+	minItems, maxItems := 0, -1
+	if s.MinItems != nil {
+		minItems = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		maxItems = *s.MaxItems
+	}
+	if maxItems < 0 {
+		maxItems = minItems + 5
+	}
+
+	n := minItems
+	if depth < maxDepth {
+		n += rand.Intn(maxItems - minItems + 1)
+	}
+
+	items := make([]any, 0, n)
+	seen := map[string]bool{}
+	for len(items) < n {
+		var item any
+		for i := 0; i < attempts; i++ {
+			if s.Items != nil {
+				item = g.value(rand, s.Items, depth+1)
+			} else {
+				item = randomWord(rand, rand.Intn(10))
+			}
+
+			if !s.UniqueItems {
+				break
+			}
+			b, _ := json.Marshal(item)
+			if !seen[string(b)] {
+				seen[string(b)] = true
+				break
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// object returns a random object with every required property of s and some
+// of the optional ones.
+func (g *Generator) object(rand *rand.Rand, s *openapi.Schema, depth int) map[string]any {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m := map[string]any{}
+	var optional []string
+	for _, name := range names {
+		p := s.Properties[name]
+		if (g.mode != openapi.ModeReadFromServer && p.ReadOnly) || (g.mode == openapi.ModeReadFromServer && p.WriteOnly) {
+			continue
+		}
+		if !required[name] && (depth >= maxDepth || rand.Intn(2) == 0) {
+			optional = append(optional, name)
+			continue
+		}
+		m[name] = g.value(rand, p, depth+1)
+	}
+
+	// Dependent properties are added until none is missing.
+	for changed := true; changed; {
+		changed = false
+		for _, name := range sortedKeys(m) {
+			for _, dependent := range s.DependentRequired[name] {
+				if _, ok := m[dependent]; !ok && s.Properties[dependent] != nil {
+					m[dependent] = g.value(rand, s.Properties[dependent], depth+1)
+					changed = true
+				}
+			}
+		}
+	}
+
+	if s.MinProperties != nil {
+		for _, name := range optional {
+			if len(m) >= *s.MinProperties {
+				break
+			}
+			if _, ok := m[name]; !ok {
+				m[name] = g.value(rand, s.Properties[name], depth+1)
+			}
+		}
+
+		if additional, ok := s.AdditionalProperties.(*openapi.Schema); ok {
+			for i := 0; len(m) < *s.MinProperties; i++ {
+				m["additional"+strconv.Itoa(i)] = g.value(rand, additional, depth+1)
+			}
+		}
+	}
+
+	if additional, ok := s.AdditionalProperties.(*openapi.Schema); ok && depth < maxDepth {
+		for i := rand.Intn(3); i > 0 && (s.MaxProperties == nil || len(m) < *s.MaxProperties); i-- {
+			name := randomWord(rand, 1+rand.Intn(10))
+			if _, ok := s.Properties[name]; !ok {
+				m[name] = g.value(rand, additional, depth+1)
+			}
+		}
+	}
+
+	return m
+}
+
+// writeRegexp writes a random string matching re.
+func writeRegexp(rand *rand.Rand, sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		// Rune holds pairs of inclusive ranges.
+		if len(re.Rune) == 0 {
+			return
+		}
+		i := rand.Intn(len(re.Rune)/2) * 2
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		sb.WriteRune(lo + rune(rand.Int63n(int64(hi-lo)+1)))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(alphabet[rand.Intn(len(alphabet))])
+	case syntax.OpCapture:
+		writeRegexp(rand, sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeRegexp(rand, sb, sub)
+		}
+	case syntax.OpAlternate:
+		writeRegexp(rand, sb, re.Sub[rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 {
+			hi = lo + 5
+		}
+		for n := lo + rand.Intn(hi-lo+1); n > 0; n-- {
+			writeRegexp(rand, sb, re.Sub[0])
+		}
+	}
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randomWord returns a random alphanumeric string of length n.
+func randomWord(rand *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rand.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// randomTime returns a random time between 2000 and 2030 in UTC, with a
+// precision of seconds.
+func randomTime(rand *rand.Rand) time.Time {
+	return time.Unix(946684800+rand.Int63n(946684800), 0).UTC()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package openapitest_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/restk/openapi"
+	"github.com/restk/openapi/openapitest"
+)
+
+type genAddress struct {
+	City    string `json:"city" minLength:"2" maxLength:"20"`
+	Country string `json:"country" pattern:"^[A-Z]{2}$"`
+}
+
+type genUser struct {
+	ID        int               `json:"id" readOnly:"true"`
+	Email     string            `json:"email" format:"email"`
+	Name      string            `json:"name" minLength:"3" maxLength:"10"`
+	Role      string            `json:"role" enum:"admin,member"`
+	Age       int               `json:"age" minimum:"18" maximum:"120"`
+	Score     float64           `json:"score" exclusiveMinimum:"0" exclusiveMaximum:"1"`
+	Even      int               `json:"even" multipleOf:"2" minimum:"1" maximum:"9"`
+	Tags      []string          `json:"tags,omitempty" minItems:"1" maxItems:"3" uniqueItems:"true"`
+	Address   *genAddress       `json:"address,omitempty"`
+	Friends   []genUser         `json:"friends,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Reference string            `json:"reference" format:"uuid"`
+}
+
+func TestGen(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	s := registry.Schema(reflect.TypeOf(genUser{}), true, "")
+	g := openapitest.Gen(registry, s)
+
+	rand := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		v := g.Value(rand)
+
+		res := &openapi.ValidateResult{}
+		openapi.Validate(registry, s, &openapi.PathBuffer{}, openapi.ModeWriteToServerStrict, v, res)
+		if len(res.Errors) != 0 {
+			t.Fatalf("expected a valid value, got %v for %v", res.Errors, v)
+		}
+	}
+
+	err := quick.Check(func(u genUser) bool {
+		return u.Age >= 18 && u.Age <= 120 && u.Even%2 == 0 && strings.Contains(u.Email, "@") && u.ID == 0
+	}, &quick.Config{Values: g.Values(genUser{}), Rand: rand})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenReadFromServer(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	s := registry.Schema(reflect.TypeOf(genUser{}), true, "")
+	g := openapitest.Gen(registry, s).Mode(openapi.ModeReadFromServer)
+
+	rand := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		if m := g.Value(rand).(map[string]any); m["id"] == nil {
+			t.Fatalf("expected the read only id, got %v", m)
+		}
+	}
+}
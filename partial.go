@@ -0,0 +1,54 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Partial is the body type returned by PartialOf. It implements
+// SchemaProvider and is only meant to be used for documentation.
+type Partial[T any] struct{}
+
+// PartialOf returns a body whose schema is the one of v with every field
+// optional, for PATCH request bodies which only send the fields to change,
+// e.g. `Request().Body(openapi.PartialOf(User{}))`. The schema is registered
+// as a component named after the one of v, e.g. `UserPatch`. Only the fields
+// of v itself become optional, nested structs keep their required fields.
+func PartialOf[T any](v T) Partial[T] {
+	return Partial[T]{}
+}
+
+// Schema registers the partial schema of T in r and returns a reference to
+// it.
+func (Partial[T]) Schema(r Registry) *Schema {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	ref := r.Schema(t, true, "").Ref
+	if ref == "" {
+		panic("partial type " + t.String() + " must be a struct")
+	}
+
+	i := strings.LastIndex(ref, "/") + 1
+	name := ref[i:] + "Patch"
+	patch := &Schema{Ref: ref[:i] + name}
+
+	c := &cloner{seen: map[cloneKey]reflect.Value{}}
+	s := c.clone(reflect.ValueOf(r.SchemaFromRef(ref))).Interface().(*Schema)
+	s.Required = nil
+	s.requiredMap = nil
+	s.msgRequired = nil
+	s.PrecomputeMessages()
+	registerSchema(r, name, s, func(existing *Schema, t reflect.Type) bool {
+		if t != nil {
+			panic("duplicate name: " + name + " is already registered for " + t.String())
+		}
+		return true
+	})
+
+	return patch
+}
@@ -0,0 +1,74 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type PartialUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email" format:"email"`
+}
+
+func TestPartialOf(t *testing.T) {
+	builder := openapi.New("title", "version")
+	update := builder.Register(&openapi.Operation{
+		OperationID: "updateUser",
+		Method:      http.MethodPatch,
+		Path:        "/users",
+	})
+	update.Request().Body(openapi.PartialOf(PartialUser{}))
+	update.Response(http.StatusOK).Description("OK").Body(&PartialUser{})
+
+	o := builder.OpenAPI()
+	if ref := o.Paths["/users"].Patch.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/PartialUserPatch" {
+		t.Errorf("unexpected request body reference %s", ref)
+	}
+
+	schemas := o.Components.Schemas.Map()
+	if patch := schemas["PartialUserPatch"]; patch == nil || len(patch.Required) != 0 || patch.Properties["email"] == nil {
+		t.Errorf("unexpected patch schema %v", patch)
+	}
+	if user := schemas["PartialUser"]; user == nil || len(user.Required) != 2 {
+		t.Errorf("expected PartialUser to keep its required fields, got %v", user)
+	}
+
+	handler := openapi.Middleware(o)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for body, status := range map[string]int{
+		`{"name":"kari"}`:  http.StatusOK,
+		`{"email":"kari"}`: http.StatusUnprocessableEntity,
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(rec, req)
+		if rec.Code != status {
+			t.Errorf("expected %d for %s, got %d: %s", status, body, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestPartialOfOrder(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Layout(openapi.Layout{Schemas: openapi.OrderRegistration})
+	update := builder.Register(&openapi.Operation{
+		OperationID: "updateUser",
+		Method:      http.MethodPatch,
+		Path:        "/users",
+	})
+	update.Request().Body(openapi.PartialOf(PartialUser{}))
+	update.Response(http.StatusOK).Description("OK").Body(LayoutZebra{})
+
+	data, err := builder.OpenAPI().JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemas := keyOrder(t, data, "components", "schemas"); !reflect.DeepEqual(schemas, []string{"PartialUser", "PartialUserPatch", "LayoutZebra"}) {
+		t.Errorf("expected the patch schema in registration order, got %v", schemas)
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package publish pushes built specs to registries and developer portals,
+// so publishing is a single call after Build.
+//
+//	o, err := builder.Build()
+//	// ...
+//	err = publish.Publish(ctx, o,
+//		&publish.SwaggerHub{Owner: "acme", APIKey: os.Getenv("SWAGGERHUB_API_KEY")},
+//		&publish.Bucket{Uploader: s3Uploader{client, "acme-specs"}},
+//	)
+//
+// Specs are published under versioned keys derived from the title and
+// version of the document.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/restk/openapi"
+)
+
+// Spec is a spec being published.
+type Spec struct {
+	// Name is the title of the document in kebab case, e.g. `pet-store`.
+	Name string
+
+	// Version is the version of the document, e.g. `1.2.0`.
+	Version string
+
+	Document *openapi.OpenAPI
+	JSON     []byte
+	YAML     []byte
+}
+
+// Target is a registry or portal specs are published to.
+type Target interface {
+	Publish(ctx context.Context, spec *Spec) error
+}
+
+// Publish encodes the document and publishes it to every target in order,
+// stopping at the first error.
+func Publish(ctx context.Context, o *openapi.OpenAPI, targets ...Target) error {
+	spec := &Spec{
+		Name:     Name(o.Info.Title),
+		Version:  o.Info.Version,
+		Document: o,
+	}
+
+	var err error
+	if spec.JSON, err = o.JSONBytes(); err != nil {
+		return err
+	}
+	if spec.YAML, err = o.YAMLBytes(); err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if err := target.Publish(ctx, spec); err != nil {
+			return fmt.Errorf("publish to %T: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// Name returns the title in kebab case, e.g. `Pet Store API` becomes
+// `pet-store-api`.
+func Name(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// Uploader stores objects in a bucket. Implement it on top of the S3 or GCS
+// SDK, or use HTTPUploader with a bucket endpoint.
+type Uploader interface {
+	Upload(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// Bucket publishes to a bucket with the layout
+//
+//	<prefix><name>/<version>/openapi.json
+//	<prefix><name>/<version>/openapi.yaml
+//	<prefix><name>/latest/openapi.json
+//	<prefix><name>/latest/openapi.yaml
+type Bucket struct {
+	Uploader Uploader
+
+	// Prefix of the keys, e.g. `specs/`.
+	Prefix string
+
+	// SkipLatest doesn't update the `latest` keys, e.g. when publishing a
+	// patch of an old version.
+	SkipLatest bool
+}
+
+func (b *Bucket) Publish(ctx context.Context, spec *Spec) error {
+	versions := []string{spec.Version}
+	if !b.SkipLatest {
+		versions = append(versions, "latest")
+	}
+
+	for _, version := range versions {
+		key := b.Prefix + spec.Name + "/" + version + "/openapi"
+		if err := b.Uploader.Upload(ctx, key+".json", spec.JSON, "application/json"); err != nil {
+			return err
+		}
+		if err := b.Uploader.Upload(ctx, key+".yaml", spec.YAML, "application/yaml"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HTTPUploader uploads objects with a PUT request to BaseURL followed by the
+// key, e.g. to the XML API of GCS or an S3 compatible endpoint accepting a
+// bearer token.
+type HTTPUploader struct {
+	BaseURL string
+
+	// Header is added to every request, e.g. an Authorization header.
+	Header http.Header
+
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (u *HTTPUploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSuffix(u.BaseURL, "/")+"/"+key, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range u.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return do(u.Client, req)
+}
+
+// SwaggerHub publishes the spec as a version of an API on SwaggerHub.
+type SwaggerHub struct {
+	// Owner is the user or organization owning the API.
+	Owner string
+
+	// API is the name of the API, defaults to the name of the spec.
+	API string
+
+	APIKey string
+
+	// Private publishes the API as private.
+	Private bool
+
+	// BaseURL defaults to `https://api.swaggerhub.com`, set it for
+	// on-premise installations.
+	BaseURL string
+
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (s *SwaggerHub) Publish(ctx context.Context, spec *Spec) error {
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.swaggerhub.com"
+	}
+	api := s.API
+	if api == "" {
+		api = spec.Name
+	}
+
+	query := "?version=" + url.QueryEscape(spec.Version) + "&force=true"
+	if s.Private {
+		query += "&isPrivate=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/apis/"+s.Owner+"/"+api+query, bytes.NewReader(spec.JSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.APIKey)
+
+	return do(s.Client, req)
+}
+
+// Backstage publishes the spec as an API entity of the Backstage software
+// catalog. The entity, with the spec embedded as its definition, is uploaded
+// as `<name>/catalog-info.yaml` and its location is registered with the
+// catalog, which then keeps it in sync.
+type Backstage struct {
+	// Uploader stores the entity where the catalog can read it, and
+	// LocationURL is the URL it is reachable at, without the key.
+	Uploader    Uploader
+	LocationURL string
+
+	// CatalogURL is the URL of the Backstage backend, e.g.
+	// `https://backstage.example.com`, and Token authenticates with it.
+	CatalogURL string
+	Token      string
+
+	// Owner and Lifecycle are the owner and lifecycle of the entity, e.g.
+	// `team-payments` and `production`. Lifecycle defaults to `production`.
+	Owner     string
+	Lifecycle string
+
+	// System is the system the API belongs to, if any.
+	System string
+
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (b *Backstage) Publish(ctx context.Context, spec *Spec) error {
+	key := spec.Name + "/catalog-info.yaml"
+	if err := b.Uploader.Upload(ctx, key, b.Entity(spec), "application/yaml"); err != nil {
+		return err
+	}
+
+	location, err := json.Marshal(map[string]string{
+		"type":   "url",
+		"target": strings.TrimSuffix(b.LocationURL, "/") + "/" + key,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(b.CatalogURL, "/")+"/api/catalog/locations", bytes.NewReader(location))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	}
+
+	err = do(b.Client, req)
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusConflict {
+		// The location is already registered and is refreshed by the catalog.
+		return nil
+	}
+	return err
+}
+
+// Entity returns the catalog-info.yaml of the API entity.
+func (b *Backstage) Entity(spec *Spec) []byte {
+	lifecycle := b.Lifecycle
+	if lifecycle == "" {
+		lifecycle = "production"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("apiVersion: backstage.io/v1alpha1\n")
+	sb.WriteString("kind: API\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString("  name: " + spec.Name + "\n")
+	if spec.Document.Info.Description != "" {
+		sb.WriteString("  description: " + quote(spec.Document.Info.Description) + "\n")
+	}
+	sb.WriteString("spec:\n")
+	sb.WriteString("  type: openapi\n")
+	sb.WriteString("  lifecycle: " + quote(lifecycle) + "\n")
+	sb.WriteString("  owner: " + quote(b.Owner) + "\n")
+	if b.System != "" {
+		sb.WriteString("  system: " + quote(b.System) + "\n")
+	}
+	sb.WriteString("  definition: |\n")
+	for _, line := range strings.Split(strings.TrimSuffix(string(spec.YAML), "\n"), "\n") {
+		sb.WriteString("    " + line + "\n")
+	}
+	return []byte(sb.String())
+}
+
+// quote returns s as a double quoted YAML scalar.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// StatusError is returned when a registry responds with an unexpected
+// status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s responded with %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package publish_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/restk/openapi"
+	"github.com/restk/openapi/publish"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+	status   int
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	request := r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("Authorization")
+	rec.requests = append(rec.requests, request)
+	rec.bodies[r.URL.Path] = string(body)
+	if rec.status != 0 {
+		http.Error(w, "conflict", rec.status)
+	}
+}
+
+func newRecorder(status int) (*recorder, *httptest.Server) {
+	rec := &recorder{bodies: map[string]string{}, status: status}
+	return rec, httptest.NewServer(rec)
+}
+
+func newSpec() *openapi.OpenAPI {
+	builder := openapi.New("Pet Store API", "1.2.0")
+	builder.Register(&openapi.Operation{
+		OperationID: "listPets",
+		Method:      http.MethodGet,
+		Path:        "/pets",
+	})
+	return builder.OpenAPI()
+}
+
+func TestPublish(t *testing.T) {
+	rec, server := newRecorder(0)
+	defer server.Close()
+
+	err := publish.Publish(context.Background(), newSpec(),
+		&publish.Bucket{
+			Uploader: &publish.HTTPUploader{BaseURL: server.URL, Header: http.Header{"Authorization": {"Bearer bucket"}}},
+			Prefix:   "specs/",
+		},
+		&publish.SwaggerHub{Owner: "acme", APIKey: "key", BaseURL: server.URL},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"PUT /specs/pet-store-api/1.2.0/openapi.json Bearer bucket",
+		"PUT /specs/pet-store-api/1.2.0/openapi.yaml Bearer bucket",
+		"PUT /specs/pet-store-api/latest/openapi.json Bearer bucket",
+		"PUT /specs/pet-store-api/latest/openapi.yaml Bearer bucket",
+		"POST /apis/acme/pet-store-api?version=1.2.0&force=true key",
+	}
+	if strings.Join(rec.requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected requests %v", rec.requests)
+	}
+	if body := rec.bodies["/apis/acme/pet-store-api"]; !strings.Contains(body, `"operationId":"listPets"`) {
+		t.Errorf("unexpected SwaggerHub body %s", body)
+	}
+}
+
+func TestPublishBackstage(t *testing.T) {
+	rec, server := newRecorder(http.StatusConflict)
+	defer server.Close()
+
+	var uploaded string
+	err := publish.Publish(context.Background(), newSpec(), &publish.Backstage{
+		Uploader: uploaderFunc(func(key string, body []byte) {
+			uploaded = key + "\n" + string(body)
+		}),
+		LocationURL: "https://specs.example.com",
+		CatalogURL:  server.URL,
+		Token:       "token",
+		Owner:       "team-pets",
+	})
+	if err != nil {
+		t.Fatalf("expected an already registered location to succeed, got %v", err)
+	}
+
+	for _, want := range []string{"pet-store-api/catalog-info.yaml\n", "kind: API\n", "  name: pet-store-api\n", `  owner: "team-pets"`, "  definition: |\n    "} {
+		if !strings.Contains(uploaded, want) {
+			t.Errorf("expected %q in entity %s", want, uploaded)
+		}
+	}
+	if len(rec.requests) != 1 || rec.requests[0] != "POST /api/catalog/locations Bearer token" {
+		t.Errorf("unexpected requests %v", rec.requests)
+	}
+	if body := rec.bodies["/api/catalog/locations"]; body != `{"target":"https://specs.example.com/pet-store-api/catalog-info.yaml","type":"url"}` {
+		t.Errorf("unexpected location %s", body)
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	_, server := newRecorder(http.StatusUnauthorized)
+	defer server.Close()
+
+	err := publish.Publish(context.Background(), newSpec(), &publish.SwaggerHub{Owner: "acme", BaseURL: server.URL})
+	var statusErr *publish.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+type uploaderFunc func(key string, body []byte)
+
+func (f uploaderFunc) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+	f(key, body)
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SplitReadWrite derives `<Name>Read` and `<Name>Write` variants of every
+// component schema with readOnly or writeOnly properties, as well as of the
+// schemas referencing them. The read variant drops the writeOnly properties
+// and the write variant drops the readOnly ones, so a single struct such as
+//
+//	type User struct {
+//		ID       int    `json:"id" readOnly:"true"`
+//		Name     string `json:"name"`
+//		Password string `json:"password" writeOnly:"true"`
+//	}
+//
+// is published as `UserRead` in responses and `UserWrite` in request bodies.
+// A variant is only kept if it is used, e.g. a schema which is only returned
+// gets no write variant, and schemas which aren't used at all keep both. The
+// original schema is removed unless something else still references it.
+func (o *OpenAPI) SplitReadWrite() {
+	if o.Components == nil || o.Components.Schemas == nil {
+		return
+	}
+	schemas := o.Components.Schemas.Map()
+
+	g := o.DependencyGraph()
+	split := map[string]bool{}
+	for name, s := range schemas {
+		if hasReadWriteOnly(s) {
+			for dependent := range g.dependents(name) {
+				split[dependent] = true
+			}
+		}
+	}
+	if len(split) == 0 {
+		return
+	}
+
+	names := sortedKeys(split)
+	for _, name := range names {
+		for _, suffix := range []string{"Read", "Write"} {
+			if _, ok := schemas[name+suffix]; ok {
+				panic("duplicate name: " + name + suffix)
+			}
+		}
+	}
+
+	for _, name := range names {
+		schemas[name+"Read"] = readWriteVariant(schemas[name], split, "Read")
+		schemas[name+"Write"] = readWriteVariant(schemas[name], split, "Write")
+	}
+
+	walkStructs(reflect.ValueOf(o), func(v reflect.Value) {
+		switch v.Type() {
+		case reflect.TypeOf(RequestBody{}):
+			rewriteContent(v.Addr().Interface().(*RequestBody).Content, split, "Write")
+		case reflect.TypeOf(Response{}):
+			rewriteContent(v.Addr().Interface().(*Response).Content, split, "Read")
+		}
+	})
+
+	// Originals and variants are only kept if something besides the other
+	// originals and variants still references them, e.g. a parameter, so a
+	// schema which is only returned doesn't get a write variant.
+	pending := map[string]*Schema{}
+	for _, name := range names {
+		for _, n := range []string{name, name + "Read", name + "Write"} {
+			pending[n] = schemas[n]
+			delete(schemas, n)
+		}
+	}
+	restore := func() {
+		for changed := true; changed; {
+			changed = false
+			for _, name := range schemaRefs(reflect.ValueOf(o)) {
+				if s, ok := pending[name]; ok {
+					schemas[name] = s
+					delete(pending, name)
+					changed = true
+				}
+			}
+		}
+	}
+	restore()
+
+	// Schemas which aren't used at all keep both variants.
+	unused := false
+	for _, name := range names {
+		_, original := pending[name]
+		_, read := pending[name+"Read"]
+		_, write := pending[name+"Write"]
+		if original && read && write {
+			for _, n := range []string{name + "Read", name + "Write"} {
+				schemas[n] = pending[n]
+				delete(pending, n)
+			}
+			unused = true
+		}
+	}
+	if unused {
+		restore()
+	}
+
+	if r, ok := o.Components.Schemas.(*mapRegistry); ok {
+		order := make([]string, 0, len(r.order)+len(names))
+		for _, name := range r.order {
+			if split[name] {
+				for _, variant := range []string{name + "Read", name + "Write"} {
+					if _, ok := schemas[variant]; ok {
+						order = append(order, variant)
+					}
+				}
+			}
+			if _, ok := schemas[name]; ok {
+				order = append(order, name)
+			}
+		}
+		r.order = order
+	}
+
+	o.Invalidate()
+}
+
+// hasReadWriteOnly returns whether s or one of its inline schemas has
+// readOnly or writeOnly properties. References are not followed.
+func hasReadWriteOnly(s *Schema) bool {
+	found := false
+	walkStructs(reflect.ValueOf(s), func(v reflect.Value) {
+		if v.Type() != schemaType {
+			return
+		}
+		for _, prop := range v.Addr().Interface().(*Schema).Properties {
+			if prop.ReadOnly || prop.WriteOnly {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// readWriteVariant returns a copy of s without the properties which don't
+// belong to the variant, which is either "Read" or "Write". References to
+// split schemas are replaced by references to the same variant.
+func readWriteVariant(s *Schema, split map[string]bool, variant string) *Schema {
+	c := &cloner{seen: map[cloneKey]reflect.Value{}}
+	copied := c.clone(reflect.ValueOf(s)).Interface().(*Schema)
+
+	walkStructs(reflect.ValueOf(copied), func(v reflect.Value) {
+		if v.Type() != schemaType {
+			return
+		}
+		s := v.Addr().Interface().(*Schema)
+		rewriteRef(s, split, variant)
+
+		removed := map[string]bool{}
+		for name, prop := range s.Properties {
+			if (variant == "Read" && prop.WriteOnly) || (variant == "Write" && prop.ReadOnly) {
+				removed[name] = true
+				delete(s.Properties, name)
+			}
+		}
+		if len(removed) == 0 {
+			return
+		}
+
+		s.Required = withoutNames(s.Required, removed)
+		s.propertyNames = withoutNames(s.propertyNames, removed)
+		s.requiredMap = nil
+	})
+
+	copied.PrecomputeMessages()
+
+	return copied
+}
+
+// rewriteContent replaces the schemas of content which reference split
+// schemas by copies referencing the given variant instead.
+func rewriteContent(content map[string]*MediaType, split map[string]bool, variant string) {
+	for _, mt := range content {
+		if mt == nil || mt.Schema == nil || !referencesAny(mt.Schema, split) {
+			continue
+		}
+
+		c := &cloner{seen: map[cloneKey]reflect.Value{}}
+		mt.Schema = c.clone(reflect.ValueOf(mt.Schema)).Interface().(*Schema)
+		walkStructs(reflect.ValueOf(mt.Schema), func(v reflect.Value) {
+			if v.Type() == schemaType {
+				rewriteRef(v.Addr().Interface().(*Schema), split, variant)
+			}
+		})
+	}
+}
+
+// referencesAny returns whether s references one of the named schemas.
+func referencesAny(s *Schema, names map[string]bool) bool {
+	for _, name := range schemaRefs(reflect.ValueOf(s)) {
+		if names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteRef points s and its discriminator mapping at the variant of split
+// schemas.
+func rewriteRef(s *Schema, split map[string]bool, variant string) {
+	const prefix = "#/components/schemas/"
+
+	if name := strings.TrimPrefix(s.Ref, prefix); s.Ref != name && split[name] {
+		s.Ref = prefix + name + variant
+	}
+
+	if s.Discriminator != nil {
+		for value, ref := range s.Discriminator.Mapping {
+			if name := strings.TrimPrefix(ref, prefix); ref != name && split[name] {
+				s.Discriminator.Mapping[value] = prefix + name + variant
+			}
+		}
+	}
+}
+
+// withoutNames returns names without the removed ones, keeping the order.
+func withoutNames(names []string, removed map[string]bool) []string {
+	if names == nil {
+		return nil
+	}
+
+	kept := make([]string, 0, len(names))
+	for _, name := range names {
+		if !removed[name] {
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) == 0 && len(names) > 0 {
+		return nil
+	}
+	return kept
+}
@@ -0,0 +1,105 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type SplitUser struct {
+	ID       int    `json:"id" readOnly:"true"`
+	Name     string `json:"name"`
+	Password string `json:"password" writeOnly:"true"`
+}
+
+type SplitTeam struct {
+	Name    string       `json:"name"`
+	Members []*SplitUser `json:"members"`
+}
+
+type SplitTag struct {
+	Name string `json:"name"`
+}
+
+func TestSplitReadWrite(t *testing.T) {
+	builder := openapi.New("title", "version")
+	create := builder.Register(&openapi.Operation{
+		OperationID: "createTeam",
+		Method:      http.MethodPost,
+		Path:        "/teams",
+	})
+	create.Request().Body(&SplitTeam{})
+	create.Response(http.StatusCreated).Description("Created").Body(&SplitTeam{})
+	create.Response(http.StatusAccepted).Description("Accepted").Body(&SplitTag{})
+
+	o, err := builder.SplitReadWrite().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := o.Components.Schemas.Map()
+	for _, name := range []string{"SplitUser", "SplitTeam"} {
+		if schemas[name] != nil {
+			t.Errorf("expected %s to be replaced by its variants", name)
+		}
+	}
+	if schemas["SplitTag"] == nil || schemas["SplitTagRead"] != nil {
+		t.Errorf("expected SplitTag to be left alone")
+	}
+
+	read, write := schemas["SplitUserRead"], schemas["SplitUserWrite"]
+	if read == nil || read.Properties["id"] == nil || read.Properties["password"] != nil {
+		t.Errorf("unexpected read variant %v", read)
+	}
+	if write == nil || write.Properties["id"] != nil || write.Properties["password"] == nil {
+		t.Errorf("unexpected write variant %v", write)
+	}
+	if ref := schemas["SplitTeamWrite"].Properties["members"].Items.Ref; ref != "#/components/schemas/SplitUserWrite" {
+		t.Errorf("expected nested reference to the write variant, got %s", ref)
+	}
+
+	op := o.Paths["/teams"].Post
+	if ref := op.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/SplitTeamWrite" {
+		t.Errorf("expected request body to use the write variant, got %s", ref)
+	}
+	if ref := op.Responses["201"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/SplitTeamRead" {
+		t.Errorf("expected response to use the read variant, got %s", ref)
+	}
+
+	if builder.OpenAPI().Components.Schemas.Map()["SplitUser"] == nil {
+		t.Errorf("expected the builder's document to be left untouched")
+	}
+}
+
+type SplitProfile struct {
+	ID      int    `json:"id" readOnly:"true"`
+	Display string `json:"display"`
+}
+
+func TestSplitReadWriteUsedVariants(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "getProfile",
+		Method:      http.MethodGet,
+		Path:        "/profile",
+	}).Response(http.StatusOK).Description("OK").Body(&SplitProfile{})
+	builder.Register(&openapi.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/login",
+	}).Request().Body(&SplitUser{})
+
+	o, err := builder.SplitReadWrite().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := o.Components.Schemas.Map()
+	if schemas["SplitProfileRead"] == nil || schemas["SplitProfileWrite"] != nil {
+		t.Errorf("expected only the read variant of a returned schema, got %v", schemas)
+	}
+	if schemas["SplitUserWrite"] == nil || schemas["SplitUserRead"] != nil {
+		t.Errorf("expected only the write variant of an accepted schema, got %v", schemas)
+	}
+}
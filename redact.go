@@ -0,0 +1,222 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+// DefaultRedactor masks fields whose names commonly hold secrets or personal
+// data, as well as fields marked with the `sensitive:"true"` tag.
+var DefaultRedactor = NewRedactor(
+	`(?i)pass(word|wd)?$`,
+	`(?i)secret`,
+	`(?i)token`,
+	`(?i)api[-_]?key`,
+	`(?i)^authorization$`,
+	`(?i)^(ssn|social[-_]?security[-_]?number)$`,
+	`(?i)^(card[-_]?number|credit[-_]?card|cvv|cvc)$`,
+)
+
+// Redactor masks sensitive values in examples and captured traffic before they
+// are published. A value is masked if its field name matches one of the
+// patterns, or if its schema has the `x-sensitive` extension, which is set by
+// the `sensitive:"true"` struct tag, or is classified as secret.
+type Redactor struct {
+	// Mask replaces sensitive values.
+	Mask string
+
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor masking fields whose names match any of the
+// regular expressions. It panics if a pattern is invalid.
+func NewRedactor(patterns ...string) *Redactor {
+	r := &Redactor{Mask: "********"}
+	for _, pattern := range patterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(pattern))
+	}
+	return r
+}
+
+// Redact returns a copy of the decoded JSON value v with sensitive values
+// masked. The schema, which may be nil, is used to find `x-sensitive` fields.
+func (r *Redactor) Redact(registry Registry, s *Schema, v any) any {
+	return r.redact(registry, s, v, false)
+}
+
+// redact masks the sensitive values of v, and its `writeOnly` properties if
+// writeOnly is true.
+func (r *Redactor) redact(registry Registry, s *Schema, v any, writeOnly bool) any {
+	// The tags of a struct typed property are set alongside its `$ref`, and a
+	// nullable property is an anyOf of the type and null, so the property, the
+	// referenced schema and every branch are checked.
+	schemas := redactSchemas(registry, s, map[*Schema]bool{})
+	for _, c := range schemas {
+		if c.masked(writeOnly) {
+			return r.Mask
+		}
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(value))
+		for k, item := range value {
+			if r.sensitiveName(k) {
+				redacted[k] = r.Mask
+				continue
+			}
+			var props []*Schema
+			for _, c := range schemas {
+				if prop := c.Properties[k]; prop != nil {
+					props = append(props, prop)
+				}
+			}
+			redacted[k] = r.redact(registry, anyOfSchemas(props), item, writeOnly)
+		}
+		return redacted
+	case []any:
+		var items []*Schema
+		for _, c := range schemas {
+			if c.Items != nil {
+				items = append(items, c.Items)
+			}
+		}
+		redacted := make([]any, len(value))
+		for i, item := range value {
+			redacted[i] = r.redact(registry, anyOfSchemas(items), item, writeOnly)
+		}
+		return redacted
+	}
+
+	return v
+}
+
+// redactSchemas returns s, the schema it references and the schemas of its
+// allOf, anyOf and oneOf branches, recursively.
+func redactSchemas(registry Registry, s *Schema, seen map[*Schema]bool) []*Schema {
+	if s == nil || seen[s] {
+		return nil
+	}
+	seen[s] = true
+
+	schemas := []*Schema{s}
+	if s.Ref != "" && registry != nil {
+		schemas = append(schemas, redactSchemas(registry, registry.SchemaFromRef(s.Ref), seen)...)
+	}
+	for _, branches := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, branch := range branches {
+			schemas = append(schemas, redactSchemas(registry, branch, seen)...)
+		}
+	}
+	return schemas
+}
+
+// anyOfSchemas returns a schema matching any of schemas, which is nil if there
+// are none.
+func anyOfSchemas(schemas []*Schema) *Schema {
+	switch len(schemas) {
+	case 0:
+		return nil
+	case 1:
+		return schemas[0]
+	}
+	return &Schema{AnyOf: schemas}
+}
+
+// sensitive reports whether values of the schema must always be masked.
+func (s *Schema) sensitive() bool {
+	return s != nil && (s.Extensions["x-sensitive"] == true || s.Extensions["x-data-classification"] == ClassificationSecret)
+}
+
+// masked reports whether values of the schema must be masked, where writeOnly
+// masks `writeOnly` schemas as well.
+func (s *Schema) masked(writeOnly bool) bool {
+	return s.sensitive() || (writeOnly && s != nil && s.WriteOnly)
+}
+
+func (r *Redactor) sensitiveName(name string) bool {
+	for _, re := range r.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactExamples masks sensitive values in the examples of every schema,
+// parameter and media type of the document. Only examples which are decoded
+// JSON values, i.e. maps and slices, are masked by field name.
+func (o *OpenAPI) RedactExamples(r *Redactor) {
+	var registry Registry
+	if o.Components != nil {
+		registry = o.Components.Schemas
+	}
+
+	redactExample := func(s *Schema, e *Example) {
+		if e != nil && e.Value != nil {
+			e.Value = r.Redact(registry, s, e.Value)
+		}
+	}
+
+	walkStructs(reflect.ValueOf(o), func(v reflect.Value) {
+		switch value := v.Addr().Interface().(type) {
+		case *Schema:
+			if value.sensitive() {
+				for i := range value.Examples {
+					value.Examples[i] = r.Mask
+				}
+				if value.Default != nil {
+					value.Default = r.Mask
+				}
+			}
+			for name, prop := range value.Properties {
+				if r.sensitiveName(name) {
+					for i := range prop.Examples {
+						prop.Examples[i] = r.Mask
+					}
+				}
+			}
+			for i, example := range value.Examples {
+				value.Examples[i] = r.Redact(registry, value, example)
+			}
+		case *MediaType:
+			if text, ok := value.Example.(string); ok {
+				// The builder takes examples as JSON text.
+				var decoded any
+				if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+					if b, err := json.Marshal(r.Redact(registry, value.Schema, decoded)); err == nil {
+						value.Example = string(b)
+					}
+				}
+			} else if value.Example != nil {
+				value.Example = r.Redact(registry, value.Schema, value.Example)
+			}
+			for _, e := range value.Examples {
+				redactExample(value.Schema, e)
+			}
+		case *Param:
+			if value.Example != nil {
+				if r.sensitiveName(value.Name) {
+					value.Example = r.Mask
+				} else {
+					value.Example = r.Redact(registry, value.Schema, value.Example)
+				}
+			}
+			for _, e := range value.Examples {
+				if r.sensitiveName(value.Name) && e != nil && e.Value != nil {
+					e.Value = r.Mask
+					continue
+				}
+				redactExample(value.Schema, e)
+			}
+		}
+	})
+	o.Invalidate()
+}
@@ -0,0 +1,101 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type Login struct {
+	Username string `json:"username" example:"joe"`
+	Password string `json:"password" example:"hunter2"`
+	PIN      string `json:"pin" sensitive:"true" example:"1234"`
+}
+
+func TestRedactExamples(t *testing.T) {
+	builder := openapi.New("title", "version")
+	login := builder.Register(&openapi.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/login",
+	})
+	login.Request().Body(Login{}).Example(`{"username":"joe","password":"hunter2","pin":"1234"}`)
+	builder.Redact(openapi.DefaultRedactor)
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	example := o.Paths["/login"].Post.RequestBody.Content["application/json"].Example
+	if example != `{"password":"********","pin":"********","username":"joe"}` {
+		t.Errorf("unexpected example %v", example)
+	}
+
+	props := o.Components.Schemas.Map()["Login"].Properties
+	if props["password"].Examples[0] != "********" || props["pin"].Examples[0] != "********" || props["username"].Examples[0] != "joe" {
+		t.Errorf("unexpected schema examples %v %v %v", props["password"].Examples, props["pin"].Examples, props["username"].Examples)
+	}
+
+	if builder.OpenAPI().Components.Schemas.Map()["Login"].Properties["password"].Examples[0] != "hunter2" {
+		t.Errorf("expected builder document to be untouched")
+	}
+}
+
+func TestRecorderRedacts(t *testing.T) {
+	builder := openapi.New("title", "version")
+	login := builder.Register(&openapi.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/login",
+	})
+	login.Request().Body(Login{})
+
+	recorder := openapi.NewRecorder(builder.OpenAPI(), 1)
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"accessToken": "abc"}`))
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/login?api_key=abc", strings.NewReader(`{"username":"joe","password":"hunter2","pin":"1234"}`))
+	r.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	s := recorder.Samples(builder.OpenAPI().Paths["/login"].Post)[0]
+	body := s.RequestBody.(map[string]any)
+	if body["password"] != "********" || body["pin"] != "********" || body["username"] != "joe" {
+		t.Errorf("unexpected request body %v", body)
+	}
+	if token := s.ResponseBody.(map[string]any)["accessToken"]; token != "********" {
+		t.Errorf("unexpected response token %v", token)
+	}
+	if key := s.Query.Get("api_key"); key != "********" {
+		t.Errorf("unexpected query %v", s.Query)
+	}
+}
+
+type LoginCard struct {
+	Number string `json:"number"`
+}
+
+type LoginWithCard struct {
+	Username string    `json:"username"`
+	Card     LoginCard `json:"card" sensitive:"true"`
+}
+
+func TestRedactStructField(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	s := registry.Schema(reflect.TypeOf(LoginWithCard{}), true, "")
+
+	redacted := openapi.DefaultRedactor.Redact(registry, s, map[string]any{
+		"username": "joe",
+		"card":     map[string]any{"number": "4111"},
+	}).(map[string]any)
+	if redacted["card"] != "********" || redacted["username"] != "joe" {
+		t.Errorf("expected the sensitive struct field to be masked, got %v", redacted)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
@@ -71,54 +72,90 @@ type mapRegistry struct {
 	seen    map[reflect.Type]bool
 	namer   func(reflect.Type, string) string
 	aliases map[reflect.Type]reflect.Type
+
+	// names are the names of prebuilt schemas, see PrebuiltSchemas.
+	names map[reflect.Type]string
+
+	// order is the order in which schemas were generated, used when
+	// schemaOrder is OrderRegistration.
+	order       []string
+	schemaOrder Order
+
+	// propertyOrder is the order of the properties of generated schemas.
+	propertyOrder Order
+
+	// required is how the required fields of structs are computed.
+	required RequiredMode
+
+	// nullablePointers makes every pointer field without omitempty nullable,
+	// see NullablePointers.
+	nullablePointers bool
+
+	// elems are the schemas of basic types reused as array items and map
+	// values, see elemSchema.
+	elems map[reflect.Kind]*Schema
+
+	strict   bool
+	warnings []*ErrorDetail
+	hooks    *Hooks
+
+	// mu guards the maps and warnings so schemas can be generated
+	// concurrently, see Builder.Preload. It is never held while generating a
+	// schema, as that recursively calls Schema.
+	mu sync.Mutex
 }
 
 func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema {
 	origType := t
 	t = deref(t)
 
+	r.mu.Lock()
 	alias, ok := r.aliases[t]
+	r.mu.Unlock()
 	if ok {
 		return r.Schema(alias, allowRef, hint)
 	}
 
-	getsRef := t.Kind() == reflect.Struct
-	if t == timeType {
-		// Special case: time.Time is always a string.
-		getsRef = false
-	}
-
-	v := reflect.New(t).Interface()
-	if _, ok := v.(SchemaProvider); ok {
-		// Special case: type provides its own schema
-		getsRef = false
-	}
-
-	name := r.namer(origType, hint)
+	getsRef := getsRef(t)
 
+	var name string
 	if getsRef {
+		r.mu.Lock()
+		name, ok = r.names[t]
+		r.mu.Unlock()
+		if !ok {
+			name = r.namer(origType, hint)
+		}
+
+		r.mu.Lock()
 		if s, ok := r.schemas[name]; ok {
-			if _, ok := r.seen[t]; !ok {
+			_, seen := r.seen[t]
+			existing := r.types[name]
+			r.mu.Unlock()
+			if !seen {
 				// Name matches but type is different, so we have a dupe.
 
-				panic(fmt.Errorf("duplicate name: %s, new type: %s, existing type: %s", name, t, r.types[name]))
+				panic(fmt.Errorf("duplicate name: %s, new type: %s, existing type: %s", name, t, existing))
 			}
 			if allowRef {
 				return &Schema{Ref: r.prefix + name}
 			}
 			return s
 		}
-	}
 
-	// First, register the type so refs can be created above for recursive types.
-	if getsRef {
+		// First, register the type so refs can be created above for recursive types.
+		r.order = append(r.order, name)
 		r.schemas[name] = &Schema{}
 		r.types[name] = t
 		r.seen[t] = true
+		r.mu.Unlock()
 	}
 	s := SchemaFromType(r, origType)
 	if getsRef {
+		r.mu.Lock()
 		r.schemas[name] = s
+		r.mu.Unlock()
+		r.hooks.schemaGenerated(name, t, s)
 	}
 
 	if getsRef && allowRef {
@@ -127,22 +164,77 @@ func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema
 	return s
 }
 
+// getsRef returns whether the schema of t is registered and referenced.
+func getsRef(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || wellKnownSchema(t) != nil {
+		// Special case: time.Time, UUIDs, etc. are always strings.
+		return false
+	}
+	// Special case: type provides its own schema
+	return !reflect.PtrTo(t).Implements(schemaProviderType)
+}
+
 func (r *mapRegistry) SchemaFromRef(ref string) *Schema {
 	if !strings.HasPrefix(ref, r.prefix) {
 		return nil
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.schemas[ref[len(r.prefix):]]
 }
 
 func (r *mapRegistry) TypeFromRef(ref string) reflect.Type {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.types[ref[len(r.prefix):]]
 }
 
+// register adds the schema s under name, which isn't generated from a Go type,
+// unless keep reports that the schema already registered under the name, of
+// type t, is to be kept. It returns the schema registered under the name.
+func (r *mapRegistry) register(name string, s *Schema, keep func(existing *Schema, t reflect.Type) bool) *Schema {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.schemas[name]; ok {
+		if keep(existing, r.types[name]) {
+			return existing
+		}
+	} else {
+		r.order = append(r.order, name)
+	}
+	r.schemas[name] = s
+	return s
+}
+
+// registerSchema is mapRegistry.register for any registry. Registries other
+// than those created by NewMapRegistry are written through Map, without a type
+// for existing schemas.
+func registerSchema(registry Registry, name string, s *Schema, keep func(existing *Schema, t reflect.Type) bool) *Schema {
+	if r, ok := registry.(*mapRegistry); ok {
+		return r.register(name, s, keep)
+	}
+
+	schemas := registry.Map()
+	if existing, ok := schemas[name]; ok && keep(existing, nil) {
+		return existing
+	}
+	schemas[name] = s
+	return s
+}
+
 func (r *mapRegistry) Map() map[string]*Schema {
 	return r.schemas
 }
 
 func (r *mapRegistry) MarshalJSON() ([]byte, error) {
+	if r.schemaOrder == OrderRegistration {
+		values := make(map[string]any, len(r.schemas))
+		for k, v := range r.schemas {
+			values[k] = v
+		}
+		return json.Marshal(orderedMap{registrationOrder(r.schemas, r.order), values})
+	}
 	return json.Marshal(r.schemas) //nolint:musttag
 }
 
@@ -152,18 +244,85 @@ func (r *mapRegistry) MarshalYAML() (interface{}, error) {
 
 // RegisterTypeAlias(t, alias) makes the schema generator use the `alias` type instead of `t`.
 func (r *mapRegistry) RegisterTypeAlias(t reflect.Type, alias reflect.Type) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.aliases[t] = alias
 }
 
+// RegistryOption configures a registry created by NewMapRegistry.
+type RegistryOption interface {
+	apply(r *mapRegistry)
+}
+
+type registryOptionFunc func(r *mapRegistry)
+
+func (f registryOptionFunc) apply(r *mapRegistry) {
+	f(r)
+}
+
+// StrictTags makes the registry panic on struct tags which look like
+// misspelled schema tags, e.g. `maxLenght`, and on schema tags which don't
+// apply to the type of the field, e.g. `minimum` on a string.
+func StrictTags() RegistryOption {
+	return registryOptionFunc(func(r *mapRegistry) {
+		r.strict = true
+	})
+}
+
+// NullablePointers makes every pointer field without `omitempty` nullable,
+// e.g. `type: ["array", "null"]`, for APIs which distinguish `null` from an
+// absent field. Without it only pointers to scalars are nullable. Pointers to
+// structs become `anyOf` the reference and `null`. The `nullable` tag takes
+// precedence either way.
+func NullablePointers() RegistryOption {
+	return registryOptionFunc(func(r *mapRegistry) {
+		r.nullablePointers = true
+	})
+}
+
+// RequiredMode controls which struct fields are required, see
+// RequiredFields. An explicit `required:"true"` or `required:"false"` tag
+// always takes precedence.
+type RequiredMode int
+
+const (
+	// RequiredUnlessOmitEmpty requires every field without `omitempty`. This
+	// is the default.
+	RequiredUnlessOmitEmpty RequiredMode = iota
+
+	// RequiredNone only requires fields tagged `required:"true"`.
+	RequiredNone
+
+	// RequiredAll requires every field, including `omitempty` ones, unless
+	// tagged `required:"false"`.
+	RequiredAll
+)
+
+// RequiredFields sets how the registry computes the required fields of
+// structs, e.g. `RequiredFields(openapi.RequiredNone)` to only require the
+// fields tagged `required:"true"`.
+func RequiredFields(mode RequiredMode) RegistryOption {
+	return registryOptionFunc(func(r *mapRegistry) {
+		r.required = mode
+	})
+}
+
 // NewMapRegistry creates a new registry that stores schemas in a map and
 // returns references to them using the given prefix.
-func NewMapRegistry(prefix string, namer func(t reflect.Type, hint string) string) Registry {
-	return &mapRegistry{
+func NewMapRegistry(prefix string, namer func(t reflect.Type, hint string) string, opts ...RegistryOption) Registry {
+	r := &mapRegistry{
 		prefix:  prefix,
 		schemas: map[string]*Schema{},
 		types:   map[string]reflect.Type{},
 		seen:    map[reflect.Type]bool{},
 		aliases: map[reflect.Type]reflect.Type{},
+		elems:   map[reflect.Kind]*Schema{},
 		namer:   namer,
 	}
+
+	for _, opt := range opts {
+		opt.apply(r)
+	}
+
+	return r
 }
@@ -0,0 +1,354 @@
+package openapi_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+type PreloadNode struct {
+	Name     string         `json:"name"`
+	Children []*PreloadNode `json:"children"`
+}
+
+type PreloadPage[T any] struct {
+	Items   []T          `json:"items"`
+	Next    string       `json:"next,omitempty"`
+	Created time.Time    `json:"created"`
+	Index   map[string]T `json:"index"`
+}
+
+type PreloadUser struct {
+	ID    int    `json:"id" minimum:"1"`
+	Email string `json:"email" format:"email"`
+}
+
+type PreloadOrder struct {
+	ID    int           `json:"id"`
+	User  PreloadUser   `json:"user"`
+	Items []PreloadNode `json:"items"`
+}
+
+var preloadTypes = []any{
+	PreloadNode{},
+	PreloadUser{},
+	PreloadOrder{},
+	PreloadPage[PreloadNode]{},
+	PreloadPage[PreloadUser]{},
+	PreloadPage[PreloadOrder]{},
+	PreloadPage[PreloadPage[PreloadUser]]{},
+	PreloadPage[PreloadPage[PreloadOrder]]{},
+	PreloadPage[string]{},
+	PreloadPage[int]{},
+}
+
+func TestPreload(t *testing.T) {
+	sequential := openapi.New("title", "version").Preload(1, preloadTypes...)
+	parallel := openapi.New("title", "version").Preload(8, preloadTypes...)
+
+	expected, _ := json.Marshal(sequential.Registry())
+	actual, _ := json.Marshal(parallel.Registry())
+	if string(expected) != string(actual) {
+		t.Errorf("expected parallel schemas to match sequential ones\n%s\n%s", expected, actual)
+	}
+	if len(parallel.Registry().Map()) != len(preloadTypes) {
+		t.Errorf("expected %d schemas, got %d", len(preloadTypes), len(parallel.Registry().Map()))
+	}
+}
+
+func BenchmarkPreload(b *testing.B) {
+	for _, workers := range []int{1, 4} {
+		workers := workers
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				openapi.New("title", "version").Preload(workers, preloadTypes...)
+			}
+		})
+	}
+}
+
+type AllocProfile struct {
+	Name     string            `json:"name" doc:"Display name" maxLength:"80"`
+	Tags     []string          `json:"tags,omitempty" enum:"a,b,c"`
+	Scores   []int             `json:"scores"`
+	Labels   map[string]string `json:"labels"`
+	Flags    []bool            `json:"flags"`
+	Ratios   []float64         `json:"ratios"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Age      int               `json:"age" minimum:"0"`
+}
+
+type AllocAccount struct {
+	ID       string          `json:"id" format:"uuid"`
+	Owner    AllocProfile    `json:"owner"`
+	Members  []AllocProfile  `json:"members"`
+	Settings map[string]bool `json:"settings"`
+	Created  time.Time       `json:"created"`
+}
+
+func BenchmarkRegistrySchema(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+		registry.Schema(reflect.TypeOf(AllocAccount{}), true, "")
+	}
+}
+
+// UUID mirrors github.com/google/uuid.UUID.
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+type WellKnownTypes struct {
+	ID       UUID          `json:"id"`
+	Created  time.Time     `json:"created"`
+	Deleted  *time.Time    `json:"deleted"`
+	Website  url.URL       `json:"website"`
+	IP       net.IP        `json:"ip"`
+	Addr     netip.Addr    `json:"addr"`
+	Duration time.Duration `json:"duration"`
+}
+
+func TestWellKnownTypes(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	registry.Schema(reflect.TypeOf(WellKnownTypes{}), true, "")
+
+	b, err := json.Marshal(registry.Map()["WellKnownTypes"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"addr":{"type":"string"},"created":{"format":"date-time","type":"string"},"deleted":{"format":"date-time","type":["string","null"]},"duration":{"format":"int64","type":"integer"},"id":{"format":"uuid","type":"string"},"ip":{"anyOf":[{"format":"ipv4","type":"string"},{"format":"ipv6","type":"string"}],"type":"string"},"website":{"format":"uri","type":"string"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected properties %s", b)
+	}
+	if len(registry.Map()) != 1 {
+		t.Errorf("expected only WellKnownTypes to be registered, got %v", registry.Map())
+	}
+
+	ip := registry.Map()["WellKnownTypes"].Properties["ip"]
+	for value, valid := range map[string]bool{"192.0.2.1": true, "2001:db8::1": true, "not an ip": false} {
+		res := &openapi.ValidateResult{}
+		openapi.Validate(registry, ip, &openapi.PathBuffer{}, openapi.ModeWriteToServer, value, res)
+		if (len(res.Errors) == 0) != valid {
+			t.Errorf("unexpected errors for %s: %v", value, res.Errors)
+		}
+	}
+}
+
+type NullableAddress struct {
+	City string `json:"city"`
+}
+
+type NullablePointers struct {
+	Name    *string          `json:"name"`
+	Tags    *[]string        `json:"tags"`
+	Home    *NullableAddress `json:"home"`
+	Work    *NullableAddress `json:"work,omitempty"`
+	Billing *NullableAddress `json:"billing" nullable:"false"`
+}
+
+func TestNullablePointers(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer, openapi.NullablePointers())
+	registry.Schema(reflect.TypeOf(NullablePointers{}), true, "")
+
+	b, err := json.Marshal(registry.Map()["NullablePointers"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"billing":{"$ref":"#/components/schemas/NullableAddress"},"home":{"anyOf":[{"$ref":"#/components/schemas/NullableAddress"},{"type":"null"}]},"name":{"type":["string","null"]},"tags":{"items":{"type":"string"},"type":["array","null"]},"work":{"$ref":"#/components/schemas/NullableAddress"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected properties %s", b)
+	}
+
+	for value, valid := range map[string]bool{
+		`{"name":null,"tags":null,"home":null,"billing":{"city":"x"}}`: true,
+		`{"name":"x","tags":[],"home":{"city":"x"},"billing":null}`:    false,
+		`{"name":"x","tags":[],"home":1,"billing":{"city":"x"}}`:       false,
+	} {
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			t.Fatal(err)
+		}
+		res := &openapi.ValidateResult{}
+		openapi.Validate(registry, registry.Map()["NullablePointers"], openapi.NewPathBuffer([]byte{}, 0), openapi.ModeWriteToServer, v, res)
+		if (len(res.Errors) == 0) != valid {
+			t.Errorf("expected %s to be valid %v, got %v", value, valid, res.Errors)
+		}
+	}
+}
+
+type RequiredFields struct {
+	Name     string `json:"name"`
+	Nickname string `json:"nickname,omitempty"`
+	Email    string `json:"email,omitempty" required:"true"`
+	Bio      string `json:"bio" required:"false"`
+}
+
+func TestRequiredFields(t *testing.T) {
+	for mode, expected := range map[openapi.RequiredMode][]string{
+		openapi.RequiredUnlessOmitEmpty: {"name", "email"},
+		openapi.RequiredNone:            {"email"},
+		openapi.RequiredAll:             {"name", "nickname", "email"},
+	} {
+		registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer, openapi.RequiredFields(mode))
+		registry.Schema(reflect.TypeOf(RequiredFields{}), true, "")
+
+		if required := registry.Map()["RequiredFields"].Required; !reflect.DeepEqual(required, expected) {
+			t.Errorf("expected required %v for mode %d, got %v", expected, mode, required)
+		}
+	}
+}
+
+type EnumStatus int
+
+const (
+	EnumStatusActive EnumStatus = iota + 1
+	EnumStatusSuspended
+)
+
+func (EnumStatus) Enum() []any {
+	return []any{EnumStatusActive, EnumStatusSuspended}
+}
+
+type EnumFields struct {
+	Status   EnumStatus   `json:"status"`
+	History  []EnumStatus `json:"history"`
+	Override EnumStatus   `json:"override" enum:"1"`
+	Color    string       `json:"color" enum:"red,green"`
+}
+
+func TestEnums(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	registry.Schema(reflect.TypeOf(EnumFields{}), true, "")
+
+	b, err := json.Marshal(registry.Map()["EnumFields"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"color":{"enum":["red","green"],"type":"string"},"history":{"items":{"enum":[1,2],"format":"int64","type":"integer"},"type":"array"},"override":{"enum":[1],"format":"int64","type":"integer"},"status":{"enum":[1,2],"format":"int64","type":"integer"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected properties %s", b)
+	}
+
+	builder := openapi.New("title", "version")
+	list := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	list.Request().QueryParam("sort", "").Enum("asc", "desc")
+	list.Request().QueryParam("status", []int{}).Enum(1, 2)
+
+	params := builder.OpenAPI().Paths["/users"].Get.Parameters
+	if enum := params[0].Schema.Enum; !reflect.DeepEqual(enum, []any{"asc", "desc"}) {
+		t.Errorf("unexpected sort enum %v", enum)
+	}
+	if enum := params[1].Schema.Items.Enum; !reflect.DeepEqual(enum, []any{1.0, 2.0}) {
+		t.Errorf("unexpected status enum %v", enum)
+	}
+	if enum := builder.Registry().Schema(reflect.TypeOf([]int{}), true, "").Items.Enum; enum != nil {
+		t.Errorf("expected shared items to be left alone, got %v", enum)
+	}
+}
+
+func TestElemSchemasPerRegistry(t *testing.T) {
+	first := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	second := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+
+	items := first.Schema(reflect.TypeOf([]string{}), true, "").Items
+	if first.Schema(reflect.TypeOf(map[string]string{}), true, "").AdditionalProperties != items {
+		t.Errorf("expected basic element schemas to be shared within a registry")
+	}
+
+	items.Description = "modified"
+	if description := second.Schema(reflect.TypeOf([]string{}), true, "").Items.Description; description != "" {
+		t.Errorf("expected registries to be independent, got %q", description)
+	}
+}
+
+type ReadOnlyFields struct {
+	ID       int    `json:"id" readOnly:"true"`
+	Name     string `json:"name"`
+	Password string `json:"password" writeOnly:"true"`
+}
+
+func TestReadOnlyWriteOnly(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	schema := registry.Schema(reflect.TypeOf(ReadOnlyFields{}), true, "")
+
+	for _, test := range []struct {
+		mode  openapi.ValidateMode
+		value map[string]any
+		valid bool
+	}{
+		{openapi.ModeWriteToServer, map[string]any{"name": "x", "password": "p"}, true},
+		{openapi.ModeWriteToServer, map[string]any{"id": 1.0, "name": "x", "password": "p"}, true},
+		{openapi.ModeWriteToServerStrict, map[string]any{"id": 1.0, "name": "x", "password": "p"}, false},
+		{openapi.ModeWriteToServerStrict, map[string]any{"id": 0.0, "name": "x", "password": "p"}, true},
+		{openapi.ModeReadFromServer, map[string]any{"id": 1.0, "name": "x"}, true},
+		{openapi.ModeReadFromServer, map[string]any{"id": 1.0, "name": "x", "password": "p"}, false},
+	} {
+		res := &openapi.ValidateResult{}
+		openapi.Validate(registry, schema, openapi.NewPathBuffer([]byte{}, 0), test.mode, test.value, res)
+		if (len(res.Errors) == 0) != test.valid {
+			t.Errorf("expected %v to be valid %v in mode %d, got %v", test.value, test.valid, test.mode, res.Errors)
+		}
+	}
+}
+
+type DefaultFields struct {
+	Limit   int      `json:"limit" default:"20"`
+	Active  bool     `json:"active" default:"true"`
+	Ratio   float64  `json:"ratio" default:"0.5"`
+	Sort    string   `json:"sort" default:"name"`
+	Tags    []string `json:"tags" default:"a,b"`
+	Retries *int     `json:"retries,omitempty" default:"3"`
+}
+
+func TestDefaults(t *testing.T) {
+	registry := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer)
+	registry.Schema(reflect.TypeOf(DefaultFields{}), true, "")
+
+	b, err := json.Marshal(registry.Map()["DefaultFields"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"active":{"default":true,"type":"boolean"},"limit":{"default":20,"format":"int64","type":"integer"},"ratio":{"default":0.5,"format":"double","type":"number"},"retries":{"default":3,"format":"int64","type":"integer"},"sort":{"default":"name","type":"string"},"tags":{"default":["a","b"],"items":{"type":"string"},"type":"array"}}`
+	if string(b) != expected {
+		t.Errorf("unexpected properties %s", b)
+	}
+
+	type InvalidDefault struct {
+		Limit int `json:"limit" default:"many"`
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected a panic for an invalid default")
+			}
+		}()
+		registry.Schema(reflect.TypeOf(InvalidDefault{}), true, "")
+	}()
+
+	builder := openapi.New("title", "version")
+	list := builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	list.Request().QueryParam("limit", 0).Default(20)
+	if d := builder.OpenAPI().Paths["/users"].Get.Parameters[0].Schema.Default; d != 20.0 {
+		t.Errorf("unexpected param default %v", d)
+	}
+}
@@ -7,6 +7,7 @@
 package openapi
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -32,6 +34,7 @@ const (
 	TypeString  = "string"
 	TypeArray   = "array"
 	TypeObject  = "object"
+	TypeNull    = "null"
 )
 
 // Special JSON Schema formats.
@@ -42,6 +45,45 @@ var (
 	rawMessageType = reflect.TypeOf(json.RawMessage{})
 )
 
+var schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+var enumProviderType = reflect.TypeOf((*EnumProvider)(nil)).Elem()
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// wellKnownSchema returns the schema of types which encoding/json marshals as
+// strings rather than by their fields, or nil for other types. Besides
+// time.Time, url.URL and net.IP these are the types implementing
+// encoding.TextMarshaler, e.g. netip.Addr, and `UUID` types such as
+// github.com/google/uuid get the uuid format.
+func wellKnownSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: TypeString, Format: "date-time"}
+	case urlType:
+		return &Schema{Type: TypeString, Format: "uri"}
+	case ipType:
+		// net.IP holds either version, so both formats are allowed.
+		return &Schema{Type: TypeString, AnyOf: []*Schema{
+			{Type: TypeString, Format: "ipv4"},
+			{Type: TypeString, Format: "ipv6"},
+		}}
+	}
+
+	ptr := reflect.PtrTo(t)
+	if !ptr.Implements(textMarshalerType) || ptr.Implements(jsonMarshalerType) {
+		return nil
+	}
+
+	s := &Schema{Type: TypeString}
+	if t.Name() == "UUID" && t.Kind() == reflect.Array && t.Len() == 16 {
+		s.Format = "uuid"
+	}
+	return s
+}
+
 func deref(t reflect.Type) reflect.Type {
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -68,9 +110,11 @@ type Schema struct {
 	Nullable             bool                `yaml:"-"`
 	Title                string              `yaml:"title,omitempty"`
 	Description          string              `yaml:"description,omitempty"`
+	DescriptionKey       string              `yaml:"-"`
 	Ref                  string              `yaml:"$ref,omitempty"`
 	Format               string              `yaml:"format,omitempty"`
 	ContentEncoding      string              `yaml:"contentEncoding,omitempty"`
+	ContentMediaType     string              `yaml:"contentMediaType,omitempty"`
 	Default              any                 `yaml:"default,omitempty"`
 	Examples             []any               `yaml:"examples,omitempty"`
 	Items                *Schema             `yaml:"items,omitempty"`
@@ -98,15 +142,24 @@ type Schema struct {
 	Extensions           map[string]any      `yaml:",inline"`
 	DependentRequired    map[string][]string `yaml:"dependentRequired,omitempty"`
 
-	OneOf []*Schema `yaml:"oneOf,omitempty"`
-	AnyOf []*Schema `yaml:"anyOf,omitempty"`
-	AllOf []*Schema `yaml:"allOf,omitempty"`
-	Not   *Schema   `yaml:"not,omitempty"`
+	OneOf         []*Schema      `yaml:"oneOf,omitempty"`
+	AnyOf         []*Schema      `yaml:"anyOf,omitempty"`
+	AllOf         []*Schema      `yaml:"allOf,omitempty"`
+	Not           *Schema        `yaml:"not,omitempty"`
+	Discriminator *Discriminator `yaml:"discriminator,omitempty"`
 
 	patternRe     *regexp.Regexp  `yaml:"-"`
 	requiredMap   map[string]bool `yaml:"-"`
 	propertyNames []string        `yaml:"-"`
 
+	// orderProperties marshals the properties in the order of propertyNames,
+	// i.e. of the struct fields, for OrderRegistration.
+	orderProperties bool
+
+	// shared is set for the schemas of basic types which are reused as array
+	// items and map values, see elemSchema.
+	shared bool
+
 	// Precomputed validation messages. These prevent allocations during
 	// validation and are known at schema creation time.
 	msgEnum              string                       `yaml:"-"`
@@ -133,6 +186,10 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 	if s.Nullable {
 		typ = []string{s.Type, "null"}
 	}
+	var properties any = s.Properties
+	if s.orderProperties && len(s.Properties) > 0 {
+		properties = newOrderedMap(s.Properties, registrationOrder(s.Properties, s.propertyNames))
+	}
 	return marshalJSON([]jsonFieldInfo{
 		{"type", typ, omitEmpty},
 		{"title", s.Title, omitEmpty},
@@ -140,11 +197,12 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 		{"$ref", s.Ref, omitEmpty},
 		{"format", s.Format, omitEmpty},
 		{"contentEncoding", s.ContentEncoding, omitEmpty},
+		{"contentMediaType", s.ContentMediaType, omitEmpty},
 		{"default", s.Default, omitNil},
 		{"examples", s.Examples, omitEmpty},
 		{"items", s.Items, omitEmpty},
 		{"additionalProperties", s.AdditionalProperties, omitNil},
-		{"properties", s.Properties, omitEmpty},
+		{"properties", properties, omitEmpty},
 		{"enum", s.Enum, omitEmpty},
 		{"minimum", s.Minimum, omitEmpty},
 		{"exclusiveMinimum", s.ExclusiveMinimum, omitEmpty},
@@ -169,15 +227,63 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 		{"anyOf", s.AnyOf, omitEmpty},
 		{"allOf", s.AllOf, omitEmpty},
 		{"not", s.Not, omitEmpty},
+		{"discriminator", s.Discriminator, omitEmpty},
 	}, s.Extensions)
 }
 
+// Discriminator tells clients which schema of a oneOf or anyOf applies to a
+// payload, based on the value of one of its properties.
+type Discriminator struct {
+	// PropertyName is the name of the property holding the discriminating
+	// value.
+	PropertyName string `yaml:"propertyName"`
+
+	// Mapping maps the values of the property to schema references. Values
+	// which aren't mapped are matched against the schema names.
+	Mapping map[string]string `yaml:"mapping,omitempty"`
+}
+
+func (d *Discriminator) MarshalJSON() ([]byte, error) {
+	return marshalJSON([]jsonFieldInfo{
+		{"propertyName", d.PropertyName, omitNever},
+		{"mapping", d.Mapping, omitEmpty},
+	}, nil)
+}
+
+// requiredMessages interns the messages of required properties, as the same
+// property names, e.g. `id`, show up in many schemas.
+var requiredMessages = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: map[string]string{}}
+
+func requiredMessage(name string) string {
+	requiredMessages.RLock()
+	msg, ok := requiredMessages.m[name]
+	requiredMessages.RUnlock()
+	if ok {
+		return msg
+	}
+
+	msg = "expected required property " + name + " to be present"
+	requiredMessages.Lock()
+	requiredMessages.m[name] = msg
+	requiredMessages.Unlock()
+	return msg
+}
+
 // PrecomputeMessages tries to precompute as many validation error messages
 // as possible so that new strings aren't allocated during request validation.
 func (s *Schema) PrecomputeMessages() {
-	s.msgEnum = "expected value to be one of \"" + strings.Join(mapTo(s.Enum, func(v any) string {
-		return fmt.Sprintf("%v", v)
-	}), ", ") + "\""
+	if s.shared {
+		// Shared schemas have no constraints and are never modified.
+		return
+	}
+	if len(s.Enum) > 0 {
+		s.msgEnum = "expected value to be one of \"" + strings.Join(mapTo(s.Enum, func(v any) string {
+			return fmt.Sprintf("%v", v)
+		}), ", ") + "\""
+	}
 	if s.Minimum != nil {
 		s.msgMinimum = fmt.Sprintf("expected number >= %v", *s.Minimum)
 	}
@@ -225,7 +331,7 @@ func (s *Schema) PrecomputeMessages() {
 			s.msgRequired = map[string]string{}
 		}
 		for _, name := range s.Required {
-			s.msgRequired[name] = "expected required property " + name + " to be present"
+			s.msgRequired[name] = requiredMessage(name)
 		}
 	}
 
@@ -248,9 +354,10 @@ func (s *Schema) PrecomputeMessages() {
 		for name := range s.Properties {
 			s.propertyNames = append(s.propertyNames, name)
 		}
+		sort.Strings(s.propertyNames)
 	}
 
-	if s.requiredMap == nil {
+	if s.requiredMap == nil && len(s.Required) > 0 {
 		s.requiredMap = map[string]bool{}
 		for _, name := range s.Required {
 			s.requiredMap[name] = true
@@ -317,6 +424,22 @@ func floatTag(f reflect.StructField, tag string) *float64 {
 	return nil
 }
 
+// checkBounds returns an error if the lower bound tag of a field is greater
+// than its upper bound tag, or not less than it if both are exclusive, as no
+// value could ever be valid.
+func checkBounds[T int | float64](f reflect.StructField, lowerTag, upperTag string, lower, upper *T, exclusive bool) error {
+	if lower == nil || upper == nil {
+		return nil
+	}
+	if exclusive && *lower >= *upper {
+		return fmt.Errorf("tag '%s' is not less than tag '%s' for field '%s': %v >= %v", lowerTag, upperTag, f.Name, *lower, *upper)
+	}
+	if *lower > *upper {
+		return fmt.Errorf("tag '%s' is greater than tag '%s' for field '%s': %v > %v", lowerTag, upperTag, f.Name, *lower, *upper)
+	}
+	return nil
+}
+
 // ensureType panics if the given value does not match the JSON Schema type.
 func ensureType(r Registry, fieldName string, s *Schema, value string, v any) {
 	if s.Ref != "" {
@@ -462,6 +585,7 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 		return fs
 	}
 	fs.Description = f.Tag.Get("doc")
+	fs.DescriptionKey = f.Tag.Get("docKey")
 	if fs.Format == "date-time" && f.Tag.Get("header") != "" {
 		// Special case: this is a header and uses a different date/time format.
 		// Note that it can still be overridden by the `format` or `timeFormat`
@@ -484,6 +608,9 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 	if enc := f.Tag.Get("encoding"); enc != "" {
 		fs.ContentEncoding = enc
 	}
+	if mediaType := f.Tag.Get("contentMediaType"); mediaType != "" {
+		fs.ContentMediaType = mediaType
+	}
 	fs.Default = jsonTag(registry, f, fs, "default")
 
 	if value := f.Tag.Get("example"); value != "" {
@@ -502,23 +629,29 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 			enumValues = append(enumValues, jsonTagValue(registry, f.Name, s, e))
 		}
 		if fs.Type == TypeArray {
-			fs.Items.Enum = enumValues
+			// Copy the items as they may be shared with other schemas.
+			items := *fs.Items
+			items.shared = false
+			items.Enum = enumValues
+			fs.Items = &items
 		} else {
 			fs.Enum = enumValues
 		}
 	}
 
+	nullable := fs.Nullable
 	if _, ok := f.Tag.Lookup("nullable"); ok {
-		fs.Nullable = boolTag(f, "nullable")
-		if fs.Nullable && fs.Ref != "" {
-			// Nullability is only supported for scalar types for now. Objects are
-			// much more complicated because the `null` type lives within the object
-			// definition (requiring multiple copies of the object) or needs to use
-			// `anyOf` or `not` which is not supported by all code generators, or is
-			// supported poorly & generates hard-to-use code. This is less than ideal
-			// but a compromise for now to support some nullability built-in.
-			panic(fmt.Errorf("nullable is not supported for field '%s' which is type '%s'", f.Name, fs.Ref))
-		}
+		nullable = boolTag(f, "nullable")
+	} else if mr, ok := registry.(*mapRegistry); ok && mr.nullablePointers && f.Type.Kind() == reflect.Ptr && !strings.Contains(f.Tag.Get("json"), "omitempty") {
+		nullable = true
+	}
+	if nullable && fs.Ref != "" {
+		// The `null` type can't live next to the reference, as the referenced
+		// schema would have to allow it too, so it becomes an alternative.
+		fs.AnyOf = []*Schema{{Ref: fs.Ref}, {Type: TypeNull}}
+		fs.Ref = ""
+	} else {
+		fs.Nullable = nullable
 	}
 
 	fs.Minimum = floatTag(f, "minimum")
@@ -538,6 +671,23 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 	fs.ReadOnly = boolTag(f, "readOnly")
 	fs.WriteOnly = boolTag(f, "writeOnly")
 	fs.Deprecated = boolTag(f, "deprecated")
+	if boolTag(f, "sensitive") {
+		if fs.Extensions == nil {
+			fs.Extensions = map[string]any{}
+		}
+		fs.Extensions["x-sensitive"] = true
+	}
+	if classification := f.Tag.Get("classification"); classification != "" {
+		switch classification {
+		case ClassificationPII, ClassificationSecret, ClassificationPublic:
+		default:
+			panic(fmt.Errorf("invalid classification '%s' for field '%s', must be one of pii, secret or public", classification, f.Name))
+		}
+		if fs.Extensions == nil {
+			fs.Extensions = map[string]any{}
+		}
+		fs.Extensions["x-data-classification"] = classification
+	}
 	fs.PrecomputeMessages()
 
 	return fs
@@ -596,6 +746,61 @@ type SchemaProvider interface {
 	Schema(r Registry) *Schema
 }
 
+// EnumProvider is an interface that can be implemented by named types to
+// document their possible values, as the constants of a type can't be listed
+// through reflection, e.g.
+//
+//	type Status int
+//
+//	func (Status) Enum() []any {
+//		return []any{StatusActive, StatusSuspended}
+//	}
+//
+// The values are marshaled to JSON, so types with their own marshaling are
+// documented the way they are sent. An `enum` field tag takes precedence.
+type EnumProvider interface {
+	Enum() []any
+}
+
+// providedEnum returns the JSON values of the enum of t, or nil if t is not
+// an EnumProvider.
+func providedEnum(t reflect.Type) []any {
+	var provider EnumProvider
+	if t.Implements(enumProviderType) {
+		provider = reflect.New(t).Elem().Interface().(EnumProvider)
+	} else if reflect.PtrTo(t).Implements(enumProviderType) {
+		provider = reflect.New(t).Interface().(EnumProvider)
+	} else {
+		return nil
+	}
+
+	return enumValues(provider.Enum())
+}
+
+// enumValues returns values as they are decoded from JSON, so they compare
+// equal to validated values.
+func enumValues(values []any) []any {
+	enum := make([]any, 0, len(values))
+	for _, value := range values {
+		enum = append(enum, jsonValue("enum", value))
+	}
+	return enum
+}
+
+// jsonValue returns value as it is decoded from JSON. kind names the value in
+// the panic message if it can't be marshaled.
+func jsonValue(kind string, value any) any {
+	b, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Errorf("invalid %s value %v: %w", kind, value, err))
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		panic(fmt.Errorf("invalid %s value %v: %w", kind, value, err))
+	}
+	return v
+}
+
 // SchemaFromType returns a schema for a given type, using the registry to
 // possibly create references for nested structs. The schema that is returned
 // can then be passed to `openapi.Validate` to efficiently validate incoming
@@ -605,10 +810,9 @@ type SchemaProvider interface {
 //	registry := openapi.NewMapRegistry("#/prefix", openapi.DefaultSchemaNamer)
 //	schema := openapi.SchemaFromType(registry, reflect.TypeOf(MyType{}))
 func SchemaFromType(r Registry, t reflect.Type) *Schema {
-	v := reflect.New(t).Interface()
-	if sp, ok := v.(SchemaProvider); ok {
+	if reflect.PtrTo(t).Implements(schemaProviderType) {
 		// Special case: type provides its own schema. Do not try to generate.
-		return sp.Schema(r)
+		return reflect.New(t).Interface().(SchemaProvider).Schema(r)
 	}
 
 	isPointer := t.Kind() == reflect.Pointer
@@ -617,16 +821,14 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 	t = deref(t)
 
 	// Handle special cases.
-	switch t {
-	case timeType:
-		return &Schema{Type: TypeString, Nullable: isPointer, Format: "date-time"}
-	case urlType:
-		return &Schema{Type: TypeString, Nullable: isPointer, Format: "uri"}
-	case ipType:
-		return &Schema{Type: TypeString, Nullable: isPointer, Format: "ipv4"}
-	case rawMessageType:
+	if t == rawMessageType {
 		return &Schema{}
 	}
+	if s := wellKnownSchema(t); s != nil {
+		s.Nullable = isPointer
+		s.Enum = providedEnum(t)
+		return s
+	}
 
 	minZero := 0.0
 	switch t.Kind() {
@@ -678,7 +880,7 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 			s.ContentEncoding = "base64"
 		} else {
 			s.Type = TypeArray
-			s.Items = r.Schema(t.Elem(), true, t.Name()+"Item")
+			s.Items = elemSchema(r, t.Elem(), t.Name()+"Item")
 
 			if t.Kind() == reflect.Array {
 				l := t.Len()
@@ -688,7 +890,7 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 		}
 	case reflect.Map:
 		s.Type = TypeObject
-		s.AdditionalProperties = r.Schema(t.Elem(), true, t.Name()+"Value")
+		s.AdditionalProperties = elemSchema(r, t.Elem(), t.Name()+"Value")
 	case reflect.Struct:
 		var required []string
 		requiredMap := map[string]bool{}
@@ -707,17 +909,22 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 
 			fieldSet[f.Name] = struct{}{}
 
-			// Controls whether the field is required or not. All fields start as
-			// required, then can be made optional with the `omitempty` JSON tag or it
-			// can be overridden manually via the `required` tag.
-			fieldRequired := true
+			// Controls whether the field is required or not. By default all fields
+			// start as required, then can be made optional with the `omitempty` JSON
+			// tag, see RequiredFields. It can be overridden manually via the
+			// `required` tag.
+			mode := RequiredUnlessOmitEmpty
+			if mr, ok := r.(*mapRegistry); ok {
+				mode = mr.required
+			}
+			fieldRequired := mode != RequiredNone
 
 			name := f.Name
 			if j := f.Tag.Get("json"); j != "" {
-				if n := strings.Split(j, ",")[0]; n != "" {
+				if n, _, _ := strings.Cut(j, ","); n != "" {
 					name = n
 				}
-				if strings.Contains(j, "omitempty") {
+				if mode == RequiredUnlessOmitEmpty && strings.Contains(j, "omitempty") {
 					fieldRequired = false
 				}
 			}
@@ -741,6 +948,9 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 			}
 
 			fs := SchemaFromField(r, f, t.Name()+f.Name+"Struct")
+			if mr, ok := r.(*mapRegistry); ok && fs != nil {
+				mr.checkTags(t, f, fs)
+			}
 			if fs != nil {
 				props[name] = fs
 				propNames = append(propNames, name)
@@ -794,6 +1004,9 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 
 		s.Properties = props
 		s.propertyNames = propNames
+		if mr, ok := r.(*mapRegistry); ok {
+			s.orderProperties = mr.propertyOrder == OrderRegistration
+		}
 		s.Required = required
 		s.DependentRequired = dependentRequiredMap
 		s.requiredMap = requiredMap
@@ -811,5 +1024,51 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 		s.Nullable = isPointer
 	}
 
+	if t.Kind() != reflect.Struct {
+		s.Enum = providedEnum(t)
+	}
+
 	return &s
 }
+
+// basicTypes are the basic types whose schemas are reused as array items and
+// map values, so e.g. every `[]string` doesn't allocate its own items schema.
+var basicTypes = map[reflect.Kind]reflect.Type{}
+
+func init() {
+	for _, v := range []any{
+		false, int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), "",
+	} {
+		t := reflect.TypeOf(v)
+		basicTypes[t.Kind()] = t
+	}
+}
+
+// elemSchema returns the schema of the elements of an array or map. Basic
+// types get a schema shared within the registry unless the registry could
+// treat them differently, e.g. because of a type alias. Each registry has its
+// own shared schemas, so passes modifying the schemas of one document don't
+// affect another.
+func elemSchema(r Registry, t reflect.Type, hint string) *Schema {
+	if mr, ok := r.(*mapRegistry); ok {
+		if basic := basicTypes[t.Kind()]; basic != nil && !reflect.PtrTo(t).Implements(schemaProviderType) && !reflect.PtrTo(t).Implements(enumProviderType) {
+			mr.mu.Lock()
+			_, aliased := mr.aliases[t]
+			s := mr.elems[t.Kind()]
+			if !aliased && s == nil {
+				s = SchemaFromType(nil, basic)
+				s.PrecomputeMessages()
+				s.shared = true
+				mr.elems[t.Kind()] = s
+			}
+			mr.mu.Unlock()
+			if !aliased {
+				return s
+			}
+		}
+	}
+
+	return r.Schema(t, true, hint)
+}
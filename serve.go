@@ -3,6 +3,12 @@ package openapi
 import (
 	"bytes"
 	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"text/template"
 )
 
@@ -34,7 +40,16 @@ var scalarHTML = `
 `
 
 // Scalar returns text/HTML for serving an OpenAPI spec using the scalar library.
+// A ScalarAuthentication can be passed as the "authentication" configuration
+// to prefill credentials in the try-it console.
 func Scalar(openAPI *OpenAPI, configuration map[string]any) []byte {
+	switch auth := configuration["authentication"].(type) {
+	case ScalarAuthentication:
+		auth.check(openAPI)
+	case *ScalarAuthentication:
+		auth.check(openAPI)
+	}
+
 	scalar := template.New("scalar")
 	scalar, err := scalar.Parse(scalarHTML)
 	if err != nil {
@@ -67,3 +82,502 @@ func Scalar(openAPI *OpenAPI, configuration map[string]any) []byte {
 
 	return buf.Bytes()
 }
+
+// ScalarAuthentication preconfigures the authentication of the Scalar try-it
+// console, so internal users can exercise endpoints without entering
+// credentials each time. Never prefill real secrets in a public deployment.
+//
+//	openapi.Scalar(o, map[string]any{
+//		"authentication": openapi.ScalarAuthentication{
+//			PreferredSecurityScheme: "OAuth2",
+//			OAuth2ClientID:          "docs",
+//		},
+//	})
+type ScalarAuthentication struct {
+	// PreferredSecurityScheme is the name of the security scheme selected by
+	// default. It must exist in the components of the document.
+	PreferredSecurityScheme string
+
+	// APIKey prefills the token of apiKey security schemes, e.g. a placeholder
+	// like "YOUR_API_KEY".
+	APIKey string
+
+	// BearerToken prefills the token of http bearer security schemes.
+	BearerToken string
+
+	// OAuth2ClientID and OAuth2Scopes prefill the OAuth2 flows.
+	OAuth2ClientID string
+	OAuth2Scopes   []string
+}
+
+// check panics if the preferred security scheme doesn't exist.
+func (a *ScalarAuthentication) check(openAPI *OpenAPI) {
+	if a.PreferredSecurityScheme == "" {
+		return
+	}
+	if _, ok := openAPI.Components.SecuritySchemes[a.PreferredSecurityScheme]; !ok {
+		panic("security scheme " + a.PreferredSecurityScheme + " does not exist")
+	}
+}
+
+// MarshalJSON encodes the authentication as Scalar configuration.
+func (a ScalarAuthentication) MarshalJSON() ([]byte, error) {
+	auth := map[string]any{}
+	if a.PreferredSecurityScheme != "" {
+		auth["preferredSecurityScheme"] = a.PreferredSecurityScheme
+	}
+	if a.APIKey != "" {
+		auth["apiKey"] = map[string]any{"token": a.APIKey}
+	}
+	if a.BearerToken != "" {
+		auth["http"] = map[string]any{"bearer": map[string]any{"token": a.BearerToken}}
+	}
+	if a.OAuth2ClientID != "" || len(a.OAuth2Scopes) != 0 {
+		oauth := map[string]any{}
+		if a.OAuth2ClientID != "" {
+			oauth["clientId"] = a.OAuth2ClientID
+		}
+		if len(a.OAuth2Scopes) != 0 {
+			oauth["scopes"] = a.OAuth2Scopes
+		}
+		auth["oAuth2"] = oauth
+	}
+	return json.Marshal(auth)
+}
+
+var swaggerUIHTML = `
+<!doctype html>
+<html>
+  <head>
+    <title>{{.Title}}</title>
+    <meta charset="utf-8" />
+    <meta
+      name="viewport"
+      content="width=device-width, initial-scale=1" />
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui.css" />
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"></script>
+    <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui-standalone-preset.js"></script>
+    <script>
+      var configuration = {{.Config}}
+      var oauth = {{.OAuth}}
+
+      window.ui = SwaggerUIBundle(Object.assign(configuration, {
+        spec: {{.Spec}},
+        dom_id: '#swagger-ui',
+        presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+        layout: 'StandaloneLayout'
+      }))
+      if (oauth) {
+        window.ui.initOAuth(oauth)
+      }
+    </script>
+  </body>
+</html>
+`
+
+// SwaggerUIConfig configures the page served by SwaggerUI.
+type SwaggerUIConfig struct {
+	// Title of the page, defaults to the title of the document.
+	Title string
+
+	// Version of swagger-ui-dist loaded from the CDN, defaults to "5".
+	Version string
+
+	// DocExpansion is the default expansion of operations and tags: "list",
+	// "full" or "none". Defaults to "list".
+	DocExpansion string
+
+	// DeepLinking updates the URL when operations and tags are expanded, so
+	// they can be linked to.
+	DeepLinking bool
+
+	// PersistAuthorization keeps credentials entered in the try-it console
+	// across page reloads.
+	PersistAuthorization bool
+
+	// TryItOutEnabled enables the try-it console of every operation by
+	// default.
+	TryItOutEnabled bool
+
+	// OAuth2ClientID and OAuth2Scopes prefill the OAuth2 flows.
+	OAuth2ClientID string
+	OAuth2Scopes   []string
+}
+
+// SwaggerUI returns a handler serving the spec using Swagger UI, for
+// organizations which standardized on it instead of Scalar. The spec is
+// embedded in the page from the encoding cached by JSONBytes, so call
+// Invalidate after changing openAPI directly.
+func SwaggerUI(openAPI *OpenAPI, config SwaggerUIConfig) http.Handler {
+	page, err := template.New("swaggerUI").Parse(swaggerUIHTML)
+	if err != nil {
+		panic(err)
+	}
+
+	if config.Title == "" && openAPI.Info != nil {
+		config.Title = openAPI.Info.Title
+	}
+	if config.Version == "" {
+		config.Version = "5"
+	}
+
+	options := map[string]any{
+		"deepLinking":          config.DeepLinking,
+		"persistAuthorization": config.PersistAuthorization,
+		"tryItOutEnabled":      config.TryItOutEnabled,
+	}
+	if config.DocExpansion != "" {
+		options["docExpansion"] = config.DocExpansion
+	}
+	configJSON, err := json.Marshal(options)
+	if err != nil {
+		panic(err)
+	}
+
+	oauthJSON := []byte("null")
+	if config.OAuth2ClientID != "" || len(config.OAuth2Scopes) != 0 {
+		oauthJSON, err = json.Marshal(map[string]any{
+			"clientId": config.OAuth2ClientID,
+			"scopes":   strings.Join(config.OAuth2Scopes, " "),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		specJSON, err := openAPI.JSONBytes()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.Execute(w, map[string]string{
+			"Title":   html.EscapeString(config.Title),
+			"Version": url.PathEscape(config.Version),
+			"Spec":    string(specJSON),
+			"Config":  string(configJSON),
+			"OAuth":   string(oauthJSON),
+		})
+	})
+}
+
+var redocHTML = `
+<!doctype html>
+<html>
+  <head>
+    <title>{{.Title}}</title>
+    <meta charset="utf-8" />
+    <meta
+      name="viewport"
+      content="width=device-width, initial-scale=1" />
+  </head>
+  <body>
+    <div id="redoc"></div>
+    <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+    <script>
+      Redoc.init({{.Spec}}, {{.Config}}, document.getElementById('redoc'))
+    </script>
+  </body>
+</html>
+`
+
+// RedocOption configures the page served by Redoc.
+type RedocOption interface {
+	apply(c *redocConfig)
+}
+
+type redocConfig struct {
+	options map[string]any
+	logo    map[string]any
+}
+
+type redocOptionFunc func(c *redocConfig)
+
+func (f redocOptionFunc) apply(c *redocConfig) {
+	f(c)
+}
+
+// RedocLogo shows a logo above the menu, written as the `x-logo` extension
+// of the info of the served spec. The document itself is left untouched.
+func RedocLogo(url, altText string) RedocOption {
+	return redocOptionFunc(func(c *redocConfig) {
+		c.logo = map[string]any{"url": url}
+		if altText != "" {
+			c.logo["altText"] = altText
+		}
+	})
+}
+
+// RedocTheme sets the Redoc theme, e.g.
+// `{"colors": {"primary": {"main": "#32329f"}}}`.
+func RedocTheme(theme map[string]any) RedocOption {
+	return redocOptionFunc(func(c *redocConfig) {
+		c.options["theme"] = theme
+	})
+}
+
+// RedocOptions sets any other Redoc configuration, e.g.
+// `{"hideDownloadButton": true, "expandResponses": "200"}`.
+func RedocOptions(options map[string]any) RedocOption {
+	return redocOptionFunc(func(c *redocConfig) {
+		for k, v := range options {
+			c.options[k] = v
+		}
+	})
+}
+
+// Redoc returns a handler serving the spec using Redoc. The spec is embedded
+// in the page from the encoding cached by JSONBytes, so call Invalidate after
+// changing openAPI directly.
+func Redoc(openAPI *OpenAPI, opts ...RedocOption) http.Handler {
+	page, err := template.New("redoc").Parse(redocHTML)
+	if err != nil {
+		panic(err)
+	}
+
+	config := &redocConfig{options: map[string]any{}}
+	for _, opt := range opts {
+		opt.apply(config)
+	}
+	configJSON, err := json.Marshal(config.options)
+	if err != nil {
+		panic(err)
+	}
+
+	title := ""
+	if openAPI.Info != nil {
+		title = openAPI.Info.Title
+	}
+
+	// The spec with the logo is kept along with the encoding it was made from.
+	var mu sync.Mutex
+	var source, logoSpec []byte
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		specJSON, err := openAPI.JSONBytes()
+		if err == nil && config.logo != nil {
+			mu.Lock()
+			if logoSpec == nil || !bytes.Equal(source, specJSON) {
+				var spec []byte
+				if spec, err = withLogo(specJSON, config.logo); err == nil {
+					source, logoSpec = specJSON, spec
+				}
+			}
+			if err == nil {
+				specJSON = logoSpec
+			}
+			mu.Unlock()
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.Execute(w, map[string]string{
+			"Title":  html.EscapeString(title),
+			"Spec":   string(specJSON),
+			"Config": string(configJSON),
+		})
+	})
+}
+
+// withLogo returns the spec with logo as the `x-logo` extension of its info,
+// keeping the order of its keys.
+func withLogo(specJSON []byte, logo map[string]any) ([]byte, error) {
+	var spec map[string]json.RawMessage
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil, err
+	}
+	info := []byte(spec["info"])
+	if len(info) == 0 {
+		info = []byte("{}")
+	}
+	info, err := setJSONKey(info, "x-logo", logo)
+	if err != nil {
+		return nil, err
+	}
+	return setJSONKey(specJSON, "info", json.RawMessage(info))
+}
+
+// VersionHandler returns a handler, typically mounted at `/openapi/version`,
+// which responds with the hash of the spec and the API version along with the
+// `x-build-info` stamped by Builder.BuildInfo, if any. The hash is also sent as
+// the ETag so consumers can cheaply detect when the published contract changed.
+func VersionHandler(openAPI *OpenAPI) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash, err := openAPI.Hash()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		etag := `"` + hash + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		version := struct {
+			Hash      string `json:"hash"`
+			Version   string `json:"version"`
+			BuildInfo any    `json:"buildInfo,omitempty"`
+		}{
+			Hash:      hash,
+			Version:   openAPI.Info.Version,
+			BuildInfo: openAPI.Info.Extensions["x-build-info"],
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(version)
+	})
+}
+
+// SpecHandler returns a handler, typically mounted at `/openapi.json`, which
+// responds with the spec as JSON, or as YAML if the `format` query parameter
+// is `yaml` or the Accept header asks for YAML. The encodings are cached by
+// JSONBytes and YAMLBytes, so call Invalidate after changing openAPI directly.
+func SpecHandler(openAPI *OpenAPI) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveSpec(w, r, openAPI, openAPI.Hooks)
+	})
+}
+
+// maxFilteredVariants is the number of variants cached by FilteredSpecHandler.
+const maxFilteredVariants = 64
+
+// FilteredSpecHandler is like SpecHandler but serves a filtered variant of
+// the spec per request, e.g. partners only see partner-tagged operations.
+// filter returns a key identifying the variant, typically derived from the
+// auth claims or headers of the request, and the OperationFilter producing
+// it. Variants are computed once per key with OpenAPI.Filter and cached, so
+// openAPI must not change once it is served. At most 64 variants are cached,
+// evicting the oldest, so keys should name a small set of variants such as
+// roles or plans rather than users. If keep is nil the full spec is served.
+//
+//	openapi.FilteredSpecHandler(o, func(r *http.Request) (string, openapi.OperationFilter) {
+//		if r.Header.Get("X-Partner") == "" {
+//			return "", nil
+//		}
+//		return "partner", func(path, method string, op *openapi.Operation) bool {
+//			return op.Extensions["x-partner"] == true
+//		}
+//	})
+func FilteredSpecHandler(openAPI *OpenAPI, filter func(r *http.Request) (key string, keep OperationFilter)) http.Handler {
+	var mu sync.Mutex
+	variants := map[string]*OpenAPI{}
+	var keys []string
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, keep := filter(r)
+		if keep == nil {
+			serveSpec(w, r, openAPI, openAPI.Hooks)
+			return
+		}
+
+		mu.Lock()
+		variant, ok := variants[key]
+		if !ok {
+			if len(keys) == maxFilteredVariants {
+				delete(variants, keys[0])
+				keys = keys[1:]
+			}
+			variant = openAPI.Filter(keep)
+			variants[key] = variant
+			keys = append(keys, key)
+		}
+		mu.Unlock()
+
+		serveSpec(w, r, variant, openAPI.Hooks)
+	})
+}
+
+// serveSpec writes o as JSON or YAML depending on the request.
+func serveSpec(w http.ResponseWriter, r *http.Request, o *OpenAPI, hooks *Hooks) {
+	format := "json"
+	if r.URL.Query().Get("format") == "yaml" || strings.Contains(r.Header.Get("Accept"), "yaml") {
+		format = "yaml"
+	}
+
+	var body []byte
+	var err error
+	if format == "yaml" {
+		body, err = o.YAMLBytes()
+	} else {
+		body, err = o.JSONBytes()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/"+format)
+	w.Write(body)
+	hooks.specServed(r, format, len(body))
+}
+
+var devReloadScript = `
+<script>
+  (function () {
+    var hash = null
+    setInterval(function () {
+      fetch('?hash', { cache: 'no-store' })
+        .then(function (res) { return res.text() })
+        .then(function (h) {
+          if (hash !== null && h !== hash) {
+            location.reload()
+          }
+          hash = h
+        })
+        .catch(function () {})
+    }, 1000)
+  })()
+</script>
+`
+
+// DevHandler returns a docs handler for development which calls build on
+// every request instead of serving a fixed spec, so descriptions loaded by
+// build, e.g. from markdown files or a Translations catalog on disk, show up
+// without restarting the server. The Scalar page polls the handler with the
+// `hash` query parameter and reloads itself when the spec changes. Build
+// errors are shown on the page, which keeps polling until they are fixed.
+//
+// DevHandler rebuilds the spec on every poll and must not be used in
+// production.
+func DevHandler(build func() (*OpenAPI, error), configuration map[string]any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		o, err := build()
+		var hash string
+		if err == nil {
+			hash, err = o.Hash()
+		}
+
+		if _, ok := r.URL.Query()["hash"]; ok {
+			if err != nil {
+				// The page reloads once the error changes or is fixed.
+				hash = "error:" + err.Error()
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, hash)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, "<!doctype html>\n<html>\n  <body>\n    <pre>"+html.EscapeString(err.Error())+"</pre>"+devReloadScript+"  </body>\n</html>\n")
+			return
+		}
+
+		page := string(Scalar(o, configuration))
+		io.WriteString(w, strings.Replace(page, "</body>", devReloadScript+"  </body>", 1))
+	})
+}
@@ -0,0 +1,419 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/restk/openapi"
+)
+
+func TestBuildInfoZeroTime(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	builder.BuildInfo("1.0.0", "abc123", time.Time{})
+
+	b, err := builder.OpenAPI().JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"x-build-info":{"version":"1.0.0","commit":"abc123"}`) {
+		t.Errorf("expected the zero build time to be omitted, got %s", b)
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	builder.BuildInfo("1.0.0", "abc123", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	handler := openapi.VersionHandler(builder.OpenAPI())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi/version", nil))
+
+	var body struct {
+		Hash      string `json:"hash"`
+		Version   string `json:"version"`
+		BuildInfo struct {
+			Commit string `json:"commit"`
+		} `json:"buildInfo"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, _ := builder.OpenAPI().Hash()
+	if body.Hash != hash || body.Version != "1.0.0" || body.BuildInfo.Commit != "abc123" {
+		t.Errorf("unexpected version response %s", w.Body.String())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/openapi/version", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", w.Code)
+	}
+}
+
+func TestChangelogEntry(t *testing.T) {
+	builder := openapi.New("title", "1.1.0")
+	builder.ChangelogEntry("1.0.0", "2024-01-01", "Initial release").
+		ChangelogEntry("1.1.0", "2024-02-01", "Add widgets")
+
+	specJSON, err := json.Marshal(builder.OpenAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spec struct {
+		Info struct {
+			Changelog []openapi.ChangelogEntry `json:"x-changelog"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spec.Info.Changelog) != 2 || spec.Info.Changelog[1].Version != "1.1.0" {
+		t.Errorf("unexpected changelog %v", spec.Info.Changelog)
+	}
+}
+
+func TestDevHandler(t *testing.T) {
+	description := "first"
+	var buildErr error
+	handler := openapi.DevHandler(func() (*openapi.OpenAPI, error) {
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		return openapi.New("title", "1.0.0").Description(description).OpenAPI(), nil
+	}, nil)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	page := get("/docs").Body.String()
+	if !strings.Contains(page, "first") || !strings.Contains(page, "location.reload()") {
+		t.Errorf("expected docs page with reload script, got %s", page)
+	}
+
+	hash := get("/docs?hash").Body.String()
+	if get("/docs?hash").Body.String() != hash {
+		t.Errorf("expected a stable hash")
+	}
+	description = "second"
+	if get("/docs?hash").Body.String() == hash {
+		t.Errorf("expected the hash to change with the spec")
+	}
+
+	buildErr = errors.New("bad <tag>")
+	if w := get("/docs"); w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "bad &lt;tag&gt;") {
+		t.Errorf("expected the build error to be shown, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJSONBytesCache(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := o.JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o.Info.Title = "changed"
+	if cached, _ := o.JSONBytes(); string(cached) != string(first) {
+		t.Errorf("expected the cached encoding until Invalidate")
+	}
+	o.Invalidate()
+	if fresh, _ := o.JSONBytes(); !strings.Contains(string(fresh), "changed") {
+		t.Errorf("expected a fresh encoding after Invalidate, got %s", fresh)
+	}
+
+	o.AddOperation(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	yamlBytes, _ := o.YAMLBytes()
+	if !strings.Contains(string(yamlBytes), "getUser") {
+		t.Errorf("expected AddOperation to invalidate the cache, got %s", yamlBytes)
+	}
+
+	clone := o.Clone()
+	clone.Info.Title = "clone"
+	if cloneJSON, _ := clone.JSONBytes(); !strings.Contains(string(cloneJSON), "clone") {
+		t.Errorf("expected the clone not to share the cache, got %s", cloneJSON)
+	}
+}
+
+func TestJSONBytesBuilder(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	op := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users",
+	})
+	if _, err := builder.OpenAPI().JSONBytes(); err != nil {
+		t.Fatal(err)
+	}
+
+	op.Response(http.StatusOK).Description("The user")
+	builder.Description("Users API")
+	builder.Tag("users")
+	spec, _ := builder.OpenAPI().JSONBytes()
+	for _, expected := range []string{"The user", "Users API", `"users"`} {
+		if !strings.Contains(string(spec), expected) {
+			t.Errorf("expected changes through the builders to be encoded, missing %s in %s", expected, spec)
+		}
+	}
+}
+
+func TestMutatorsInvalidate(t *testing.T) {
+	build := func(t *testing.T, register func(builder *openapi.Builder)) *openapi.OpenAPI {
+		builder := openapi.New("title", "1.0.0")
+		register(builder)
+		o, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.JSONBytes(); err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	subscribe := func(builder *openapi.Builder) {
+		builder.Register(&openapi.Operation{
+			OperationID: "subscribe",
+			Method:      http.MethodPost,
+			Path:        "/subscribe",
+		}).Callback("userCreated", &openapi.Operation{
+			Method: http.MethodPost,
+			Path:   "{$request.body#/callbackUrl}",
+		})
+	}
+
+	testCases := []struct {
+		name     string
+		register func(builder *openapi.Builder)
+		mutate   func(o *openapi.OpenAPI)
+		expected string
+	}{
+		{
+			name:     "CallbacksToWebhooks",
+			register: subscribe,
+			mutate: func(o *openapi.OpenAPI) {
+				o.CallbacksToWebhooks(false)
+			},
+			expected: `"webhooks":`,
+		},
+		{
+			name: "WebhooksToCallbacks",
+			register: func(builder *openapi.Builder) {
+				builder.Register(&openapi.Operation{
+					OperationID: "subscribe",
+					Method:      http.MethodPost,
+					Path:        "/subscribe",
+				})
+				builder.Webhook("userCreated", &openapi.Operation{OperationID: "userCreated"})
+			},
+			mutate: func(o *openapi.OpenAPI) {
+				o.WebhooksToCallbacks(o.Paths["/subscribe"].Post, "{$request.body#/callbackUrl}", false)
+			},
+			expected: `"callbacks":`,
+		},
+		{
+			name: "LinkSuggestion.Accept",
+			register: func(builder *openapi.Builder) {
+				builder.Register(&openapi.Operation{
+					OperationID: "createUser",
+					Method:      http.MethodPost,
+					Path:        "/users",
+				}).Response(http.StatusCreated).Description("Created").Body(LinkUser{})
+				getUser := builder.Register(&openapi.Operation{
+					OperationID: "getUser",
+					Method:      http.MethodGet,
+					Path:        "/users/{linkUserId}",
+				})
+				getUser.Request().PathParam("linkUserId", openapi.IntType)
+				getUser.Response(http.StatusOK).Description("OK")
+			},
+			mutate: func(o *openapi.OpenAPI) {
+				o.SuggestLinks()[0].Accept()
+			},
+			expected: `"links":`,
+		},
+		{
+			name: "Recorder.MergeExamples",
+			register: func(builder *openapi.Builder) {
+				builder.Register(&openapi.Operation{
+					OperationID: "getUser",
+					Method:      http.MethodGet,
+					Path:        "/users",
+				}).Response(http.StatusOK).Description("OK").Body(CaptureUser{})
+			},
+			mutate: func(o *openapi.OpenAPI) {
+				recorder := openapi.NewRecorder(o, 1)
+				handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"id":1,"name":"alice"}`))
+				}))
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+				recorder.MergeExamples()
+			},
+			expected: `"captured-1"`,
+		},
+		{
+			name: "RedactExamples",
+			register: func(builder *openapi.Builder) {
+				builder.Register(&openapi.Operation{
+					OperationID: "login",
+					Method:      http.MethodPost,
+					Path:        "/login",
+				}).Request().Body(Login{})
+			},
+			mutate: func(o *openapi.OpenAPI) {
+				o.RedactExamples(openapi.DefaultRedactor)
+			},
+			expected: `"********"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := build(t, tc.register)
+			tc.mutate(o)
+
+			spec, err := o.JSONBytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(spec), tc.expected) {
+				t.Errorf("expected %s in the fresh encoding, got %s", tc.expected, spec)
+			}
+		})
+	}
+}
+
+func TestScalarAuthentication(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	builder.ApiKeyAuth("X-API-Key")
+
+	page := string(openapi.Scalar(builder.OpenAPI(), map[string]any{
+		"authentication": openapi.ScalarAuthentication{
+			PreferredSecurityScheme: "ApiKeyAuth",
+			APIKey:                  "YOUR_API_KEY",
+			OAuth2ClientID:          "docs",
+		},
+	}))
+	for _, want := range []string{`"preferredSecurityScheme":"ApiKeyAuth"`, `"apiKey":{"token":"YOUR_API_KEY"}`, `"oAuth2":{"clientId":"docs"}`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected %s in page", want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for unknown security scheme")
+		}
+	}()
+	openapi.Scalar(builder.OpenAPI(), map[string]any{
+		"authentication": &openapi.ScalarAuthentication{PreferredSecurityScheme: "missing"},
+	})
+}
+
+func TestSwaggerUI(t *testing.T) {
+	builder := openapi.New("<Pets>", "1.0.0")
+	builder.Register(&openapi.Operation{
+		OperationID: "listPets",
+		Method:      http.MethodGet,
+		Path:        "/pets",
+	})
+
+	handler := openapi.SwaggerUI(builder.OpenAPI(), openapi.SwaggerUIConfig{
+		DocExpansion:   "none",
+		OAuth2ClientID: "docs",
+		OAuth2Scopes:   []string{"read", "write"},
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	page := w.Body.String()
+	for _, want := range []string{
+		"<title>&lt;Pets&gt;</title>",
+		"swagger-ui-dist@5/swagger-ui-bundle.js",
+		`"docExpansion":"none"`,
+		`{"clientId":"docs","scopes":"read write"}`,
+		`"operationId":"listPets"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected %s in page", want)
+		}
+	}
+}
+
+func TestRedoc(t *testing.T) {
+	builder := openapi.New("Pets", "1.0.0")
+
+	handler := openapi.Redoc(builder.OpenAPI(),
+		openapi.RedocLogo("https://example.com/logo.png", "Pets"),
+		openapi.RedocTheme(map[string]any{"colors": map[string]any{"primary": map[string]any{"main": "#32329f"}}}),
+		openapi.RedocOptions(map[string]any{"hideDownloadButton": true}),
+	)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	page := w.Body.String()
+	for _, want := range []string{
+		"<title>Pets</title>",
+		"redoc.standalone.js",
+		`"x-logo":{"altText":"Pets","url":"https://example.com/logo.png"}`,
+		`"hideDownloadButton":true`,
+		`"theme":{"colors":{"primary":{"main":"#32329f"}}}`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected %s in page", want)
+		}
+	}
+	if builder.OpenAPI().Info.Extensions["x-logo"] != nil {
+		t.Errorf("expected the document to be left untouched")
+	}
+}
+
+func TestRedocLogoInvalidate(t *testing.T) {
+	o, err := openapi.New("Pets", "1.0.0").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := openapi.Redoc(o, openapi.RedocLogo("https://example.com/logo.png", "Pets"))
+
+	page := func() string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		return w.Body.String()
+	}
+	if first := page(); !strings.Contains(first, `"info":{"title":"Pets","version":"1.0.0","x-logo":`) {
+		t.Errorf("expected the logo after the info keys, got %s", first)
+	}
+
+	o.Info.Title = "Animals"
+	o.Invalidate()
+	if second := page(); !strings.Contains(second, `"info":{"title":"Animals","version":"1.0.0","x-logo":`) {
+		t.Errorf("expected the logo on the fresh encoding, got %s", second)
+	}
+}
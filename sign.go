@@ -0,0 +1,158 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrSignatureInvalid is returned by Verify if the spec is not signed or the
+// signature doesn't match.
+var ErrSignatureInvalid = errors.New("signature is invalid")
+
+// Sign returns the JSON spec, e.g. from JSONBytes or Downgrade, with a
+// detached JWS of its content as the `x-signature` extension, so consumers
+// pulling the spec from a registry can check with Verify that it was
+// published by the owner of key. The key must be an ed25519.PrivateKey
+// (EdDSA), an *ecdsa.PrivateKey on P-256 (ES256) or an *rsa.PrivateKey
+// (RS256).
+//
+// The signature covers the spec without `x-signature` in canonical form,
+// with object keys sorted, so reformatting the JSON doesn't invalidate it.
+// The spec itself is returned with its key order, e.g. from a Layout, kept.
+func Sign(spec []byte, key crypto.Signer) ([]byte, error) {
+	_, payload, err := signaturePayload(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var alg string
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		alg = "EdDSA"
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("ecdsa keys must use the P-256 curve")
+		}
+		alg = "ES256"
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	default:
+		return nil, fmt.Errorf("unsupported signing key %T", key)
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + alg + `"}`))
+	input := []byte(header + "." + base64.RawURLEncoding.EncodeToString(payload))
+
+	var sig []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, input)
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed size concatenation of r and s, not ASN.1.
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(input)
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return setJSONKey(spec, "x-signature", header+".."+base64.RawURLEncoding.EncodeToString(sig))
+}
+
+// Verify checks the `x-signature` extension written by Sign against the
+// public key, returning an error wrapping ErrSignatureInvalid if the spec is
+// not signed or was modified.
+func Verify(spec []byte, key crypto.PublicKey) error {
+	doc, payload, err := signaturePayload(spec)
+	if err != nil {
+		return err
+	}
+
+	jws, _ := doc["x-signature"].(string)
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("%w: missing detached x-signature", ErrSignatureInvalid)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+
+	input := []byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload))
+	digest := sha256.Sum256(input)
+
+	valid := false
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		valid = header.Alg == "EdDSA" && ed25519.Verify(k, input, sig)
+	case *ecdsa.PublicKey:
+		valid = header.Alg == "ES256" && len(sig) == 64 &&
+			ecdsa.Verify(k, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+	case *rsa.PublicKey:
+		valid = header.Alg == "RS256" && rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return fmt.Errorf("unsupported verification key %T", key)
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: signature of %s does not match", ErrSignatureInvalid, header.Alg)
+	}
+	return nil
+}
+
+// signaturePayload decodes the spec and returns it along with the canonical
+// encoding of its content without `x-signature`.
+func signaturePayload(spec []byte) (map[string]any, []byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(spec))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	signature, signed := doc["x-signature"]
+	delete(doc, "x-signature")
+	payload, err := json.Marshal(doc)
+	if signed {
+		doc["x-signature"] = signature
+	}
+	return doc, payload, err
+}
@@ -0,0 +1,102 @@
+package openapi_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestSign(t *testing.T) {
+	builder := openapi.New("title", "1.0.0")
+	builder.Register(&openapi.Operation{
+		OperationID: "listPets",
+		Method:      http.MethodGet,
+		Path:        "/pets",
+	})
+	spec, err := builder.OpenAPI().JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaPrivate, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	for _, keys := range []struct {
+		alg     string
+		private crypto.Signer
+		public  crypto.PublicKey
+	}{
+		{"EdDSA", edPrivate, edPublic},
+		{"ES256", ecPrivate, &ecPrivate.PublicKey},
+		{"RS256", rsaPrivate, &rsaPrivate.PublicKey},
+	} {
+		t.Run(keys.alg, func(t *testing.T) {
+			signed, err := openapi.Sign(spec, keys.private)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := openapi.Verify(signed, keys.public); err != nil {
+				t.Errorf("expected valid signature, got %v", err)
+			}
+
+			// Reformatting the spec keeps the signature valid.
+			var indented bytes.Buffer
+			json.Indent(&indented, signed, "", "  ")
+			if err := openapi.Verify(indented.Bytes(), keys.public); err != nil {
+				t.Errorf("expected valid signature after reformatting, got %v", err)
+			}
+
+			tampered := bytes.Replace(signed, []byte("listPets"), []byte("listCats"), 1)
+			if err := openapi.Verify(tampered, keys.public); !errors.Is(err, openapi.ErrSignatureInvalid) {
+				t.Errorf("expected invalid signature for a modified spec, got %v", err)
+			}
+		})
+	}
+
+	if err := openapi.Verify(spec, edPublic); !errors.Is(err, openapi.ErrSignatureInvalid) {
+		t.Errorf("expected error for an unsigned spec, got %v", err)
+	}
+}
+
+func TestSignKeepsLayout(t *testing.T) {
+	builder := openapi.New("title", "1.0.0").Layout(openapi.Layout{Paths: openapi.OrderRegistration})
+	for _, path := range []string{"/zebras", "/apples"} {
+		builder.Register(&openapi.Operation{Method: http.MethodGet, Path: path})
+	}
+	spec, err := builder.OpenAPI().JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	signed, err := openapi.Sign(spec, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := keyOrder(t, signed, "paths"); !reflect.DeepEqual(paths, []string{"/zebras", "/apples"}) {
+		t.Errorf("expected the layout to be kept, got %v", paths)
+	}
+
+	// Signing again replaces the signature.
+	if signed, err = openapi.Sign(signed, private); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(signed, []byte(`"x-signature"`)); n != 1 {
+		t.Errorf("expected one signature, got %d", n)
+	}
+	if err := openapi.Verify(signed, public); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import "net/http"
+
+// Span describes the tracing span of a request handled by SpanMiddleware,
+// based on the operation the request matched rather than its URL.
+type Span struct {
+	// Name is the span name following the OpenTelemetry HTTP conventions,
+	// e.g. `GET /users/{id}`.
+	Name string
+
+	// Route is the path template of the operation, e.g. `/users/{id}`.
+	Route string
+
+	// OperationID is the ID of the operation, or the one DefaultOperationID
+	// generates if it has none, e.g. `getUserById`. Pass the document returned
+	// by Builder.Build for IDs from Builder.OperationIDs.
+	OperationID string
+
+	// Tags are the tags of the operation.
+	Tags []string
+}
+
+// Attributes returns the span attributes: `http.route`, and
+// `openapi.operation_id` and `openapi.tags` for the operation.
+func (s *Span) Attributes() map[string]any {
+	attributes := map[string]any{
+		"http.route":           s.Route,
+		"openapi.operation_id": s.OperationID,
+	}
+	if len(s.Tags) > 0 {
+		attributes["openapi.tags"] = s.Tags
+	}
+	return attributes
+}
+
+// SpanMiddleware calls enrich with the span of every request matching an
+// operation, before next handles it, so traces use stable route identifiers
+// instead of raw URLs. The span of the request must have been started by an
+// outer middleware, e.g. with OpenTelemetry:
+//
+//	enrich := openapi.SpanMiddleware(openAPI, func(r *http.Request, s *openapi.Span) {
+//		span := trace.SpanFromContext(r.Context())
+//		span.SetName(s.Name)
+//		span.SetAttributes(
+//			semconv.HTTPRoute(s.Route),
+//			attribute.String("openapi.operation_id", s.OperationID),
+//			attribute.StringSlice("openapi.tags", s.Tags),
+//		)
+//	})
+//	http.ListenAndServe(":8080", otelhttp.NewHandler(enrich(mux), "server"))
+//
+// Requests which do not match any operation are passed through as is.
+func SpanMiddleware(o *OpenAPI, enrich func(r *http.Request, s *Span)) func(next http.Handler) http.Handler {
+	if enrich == nil {
+		panic("enrich must be specified")
+	}
+
+	matcher := NewMatcher(o)
+	spans := map[*Operation]*Span{}
+	o.WalkOperations(func(path, method string, op *Operation) {
+		span := &Span{
+			Name:        method + " " + path,
+			Route:       path,
+			OperationID: op.OperationID,
+			Tags:        op.Tags,
+		}
+		if span.OperationID == "" {
+			span.OperationID = DefaultOperationID(method, path)
+		}
+		spans[op] = span
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if op, _ := matcher.Match(r.Method, r.URL.Path); op != nil && spans[op] != nil {
+				span := *spans[op]
+				enrich(r, &span)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestSpanMiddleware(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+		Tags:        []string{"users"},
+	})
+	getUser.Request().PathParam("id", openapi.IntType).Required(true)
+	builder.Register(&openapi.Operation{
+		Method: http.MethodGet,
+		Path:   "/orders",
+	})
+
+	var spans []*openapi.Span
+	handled := 0
+	handler := openapi.SpanMiddleware(builder.OpenAPI(), func(r *http.Request, s *openapi.Span) {
+		spans = append(spans, s)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled++
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/invoices", nil))
+
+	if handled != 3 || len(spans) != 2 {
+		t.Fatalf("expected every request to be handled and two spans, got %d %v", handled, spans)
+	}
+	if id := spans[1].OperationID; id != "getOrders" {
+		t.Errorf("expected the ID DefaultOperationID generates, got %q", id)
+	}
+	if s := spans[0]; s.Name != "GET /users/{id}" || s.Route != "/users/{id}" || s.OperationID != "getUser" {
+		t.Errorf("unexpected span %+v", s)
+	}
+
+	expected := map[string]any{
+		"http.route":           "/users/{id}",
+		"openapi.operation_id": "getUser",
+		"openapi.tags":         []string{"users"},
+	}
+	if attributes := spans[0].Attributes(); !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("unexpected attributes %v", attributes)
+	}
+}
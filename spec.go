@@ -0,0 +1,193 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Spec is a read-only snapshot of a document, created by Builder.Spec or
+// OpenAPI.Snapshot, for serving handlers and validators. It owns a private
+// copy of the document, so changes to the builder after serving can't race
+// with requests reading the spec. Operations and schemas returned by its
+// accessors are shared with the snapshot and must not be modified.
+type Spec struct {
+	o       *OpenAPI
+	matcher *Matcher
+	hash    string
+}
+
+// Spec builds the document like Build and returns a read-only snapshot of it.
+func (b *Builder) Spec() (*Spec, error) {
+	o, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return o.Snapshot()
+}
+
+// Snapshot returns a read-only snapshot of the document. The JSON and YAML
+// encodings are computed up front so serving never marshals the document.
+func (o *OpenAPI) Snapshot() (*Spec, error) {
+	c := o.Clone()
+	if _, err := c.YAMLBytes(); err != nil {
+		return nil, err
+	}
+	hash, err := c.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spec{
+		o:       c,
+		matcher: NewMatcher(c),
+		hash:    hash,
+	}, nil
+}
+
+// Title returns the title of the API.
+func (s *Spec) Title() string {
+	return s.o.Info.Title
+}
+
+// Version returns the version of the API.
+func (s *Spec) Version() string {
+	return s.o.Info.Version
+}
+
+// Hash returns the hash of the snapshot, see OpenAPI.Hash.
+func (s *Spec) Hash() string {
+	return s.hash
+}
+
+// JSON returns a copy of the JSON encoding of the snapshot.
+func (s *Spec) JSON() []byte {
+	b, _ := s.o.JSONBytes()
+	return append([]byte(nil), b...)
+}
+
+// YAML returns a copy of the YAML encoding of the snapshot.
+func (s *Spec) YAML() []byte {
+	b, _ := s.o.YAMLBytes()
+	return append([]byte(nil), b...)
+}
+
+// Operation returns the operation with the given ID, or nil.
+func (s *Spec) Operation(operationID string) *Operation {
+	_, op := s.o.findOperation(operationID)
+	return op
+}
+
+// Match returns the operation for a concrete request, see Matcher.Match.
+func (s *Spec) Match(method, path string) (*Operation, map[string]string) {
+	return s.matcher.Match(method, path)
+}
+
+// Registry returns a read-only view of the schema registry of the snapshot,
+// e.g. for Validate. Schema returns the registered schemas and generates
+// inline ones, but panics for types which would have to be registered.
+func (s *Spec) Registry() Registry {
+	return specRegistry{s.o.Components.Schemas}
+}
+
+// Document returns a mutable copy of the snapshot's document.
+func (s *Spec) Document() *OpenAPI {
+	return s.o.Clone()
+}
+
+// Handler returns a handler serving the snapshot like SpecHandler, with the
+// hash as the ETag.
+func (s *Spec) Handler() http.Handler {
+	etag := `"` + s.hash + `"`
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		serveSpec(w, r, s.o, s.o.Hooks)
+	})
+}
+
+// Middleware returns a middleware validating requests against the snapshot,
+// see Middleware.
+func (s *Spec) Middleware() func(next http.Handler) http.Handler {
+	return Middleware(s.o)
+}
+
+// SwaggerUI returns a handler serving the snapshot using Swagger UI, see
+// SwaggerUI.
+func (s *Spec) SwaggerUI(config SwaggerUIConfig) http.Handler {
+	return SwaggerUI(s.o, config)
+}
+
+// Redoc returns a handler serving the snapshot using Redoc, see Redoc.
+func (s *Spec) Redoc(opts ...RedocOption) http.Handler {
+	return Redoc(s.o, opts...)
+}
+
+// Scalar returns the page serving the snapshot using Scalar, see Scalar.
+func (s *Spec) Scalar(configuration map[string]any) []byte {
+	return Scalar(s.o, configuration)
+}
+
+// specRegistry is the read-only view of the registry of a Spec returned by
+// Spec.Registry.
+type specRegistry struct {
+	Registry
+}
+
+func (v specRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema {
+	r, ok := v.Registry.(*mapRegistry)
+	if !ok {
+		return v.Registry.Schema(t, allowRef, hint)
+	}
+
+	base := deref(t)
+	r.mu.Lock()
+	alias, aliased := r.aliases[base]
+	r.mu.Unlock()
+	if aliased {
+		return v.Schema(alias, allowRef, hint)
+	}
+	if !getsRef(base) {
+		// Inline schemas aren't registered, and their elements come back here.
+		return SchemaFromType(v, t)
+	}
+
+	r.mu.Lock()
+	name, ok := r.names[base]
+	if !ok {
+		name = r.namer(t, hint)
+	}
+	s, registered := r.schemas[name]
+	registered = registered && r.seen[base]
+	r.mu.Unlock()
+	if !registered {
+		panic("the registry of a Spec is read-only, " + base.String() + " is not registered")
+	}
+
+	if allowRef {
+		return &Schema{Ref: r.prefix + name}
+	}
+	return s
+}
+
+// Map returns a copy of the schemas, so they can't be added or removed.
+func (v specRegistry) Map() map[string]*Schema {
+	schemas := v.Registry.Map()
+	c := make(map[string]*Schema, len(schemas))
+	for name, s := range schemas {
+		c[name] = s
+	}
+	return c
+}
+
+func (v specRegistry) RegisterTypeAlias(t reflect.Type, alias reflect.Type) {
+	panic("the registry of a Spec is read-only")
+}
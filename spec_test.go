@@ -0,0 +1,136 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestSpec(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	builder := openapi.New("title", "1.0.0")
+	getUser := builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	})
+	getUser.Request().PathParam("id", openapi.IntType)
+	getUser.Response(http.StatusOK).Description("user").Body(User{})
+
+	spec, err := builder.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := string(spec.JSON())
+
+	builder.Register(&openapi.Operation{
+		OperationID: "deleteUser",
+		Method:      http.MethodDelete,
+		Path:        "/users/{id}",
+	})
+	builder.OpenAPI().Info.Title = "changed"
+
+	if string(spec.JSON()) != before || spec.Title() != "title" || spec.Version() != "1.0.0" {
+		t.Errorf("expected the snapshot to be unaffected by builder changes, got %s", spec.JSON())
+	}
+	if spec.Operation("getUser") == nil || spec.Operation("deleteUser") != nil {
+		t.Errorf("unexpected operations in snapshot")
+	}
+	if op, params := spec.Match(http.MethodGet, "/users/42"); op == nil || params["id"] != "42" {
+		t.Errorf("expected getUser to match, got %v %v", op, params)
+	}
+	if spec.Registry().SchemaFromRef("#/components/schemas/User") == nil {
+		t.Errorf("expected User schema in snapshot registry")
+	}
+
+	doc := spec.Document()
+	doc.Info.Title = "copy"
+	if spec.Title() != "title" {
+		t.Errorf("expected Document to return a copy")
+	}
+
+	w := httptest.NewRecorder()
+	spec.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Body.String() != before || w.Header().Get("ETag") != `"`+spec.Hash()+`"` {
+		t.Errorf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	spec.Handler().ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", w.Code)
+	}
+}
+
+func TestSpecConsumers(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name" minLength:"2"`
+	}
+	type Unregistered struct {
+		ID int `json:"id"`
+	}
+
+	builder := openapi.New("Pets", "1.0.0")
+	builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/pets",
+	}).Request().Body(Pet{})
+
+	spec, err := builder.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := spec.Registry()
+	if s := registry.Schema(reflect.TypeOf(Pet{}), true, ""); s.Ref != "#/components/schemas/Pet" {
+		t.Errorf("expected a reference to Pet, got %v", s)
+	}
+	if s := registry.Schema(reflect.TypeOf([]Pet{}), true, ""); s.Items == nil || s.Items.Ref != "#/components/schemas/Pet" {
+		t.Errorf("expected an inline list of Pet, got %v", s)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected registering a new type to panic")
+			}
+		}()
+		registry.Schema(reflect.TypeOf(Unregistered{}), true, "")
+	}()
+	delete(registry.Map(), "Pet")
+	if registry.SchemaFromRef("#/components/schemas/Pet") == nil || len(spec.Registry().Map()) != 1 {
+		t.Errorf("expected the snapshot schemas to be left alone")
+	}
+
+	handler := spec.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"R"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected the snapshot to validate requests, got %d", w.Code)
+	}
+
+	for name, ui := range map[string]http.Handler{
+		"swagger": spec.SwaggerUI(openapi.SwaggerUIConfig{}),
+		"redoc":   spec.Redoc(),
+	} {
+		w := httptest.NewRecorder()
+		ui.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		if !strings.Contains(w.Body.String(), `"operationId":"createPet"`) {
+			t.Errorf("%s: expected the snapshot in the page, got %s", name, w.Body.String())
+		}
+	}
+	if page := string(spec.Scalar(map[string]any{})); !strings.Contains(page, "createPet") {
+		t.Errorf("expected the snapshot in the Scalar page, got %s", page)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package static generates schemas from Go source using go/types instead of
+// reflection, so no value of the types has to be instantiated. This allows
+// generating schemas for unexported types and emitting specs at build time,
+// e.g. from a `go generate` step, without linking the API into the
+// generator.
+//
+// Struct tags and well-known types, such as time.Time and the types
+// implementing encoding.TextMarshaler, are interpreted exactly like the
+// reflection based generator of the openapi package. Types implementing
+// openapi.SchemaProvider can't be called from source and are described by
+// their underlying type instead.
+package static
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/restk/openapi"
+)
+
+// Prefix is the prefix of the references between the generated schemas.
+const Prefix = "#/components/schemas/"
+
+// Schemas type-checks the Go package in dir and returns the component schemas
+// of the named types, and of every struct type they reference, keyed by
+// schema name. Test files are ignored.
+func Schemas(dir string, typeNames ...string) (schemas map[string]*openapi.Schema, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a single package in %s, found %d", dir, len(pkgs))
+	}
+
+	var files []*ast.File
+	for _, pkg := range pkgs {
+		names := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, pkg.Files[name])
+		}
+	}
+
+	config := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := config.Check(dir, fset, files, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{schemas: map[string]*openapi.Schema{}}
+	defer func() {
+		// Invalid tags panic like they do for the reflection based generator.
+		if r := recover(); r != nil {
+			schemas, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	for _, name := range typeNames {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, pkg.Path())
+		}
+		g.schema(obj.Type())
+	}
+
+	return g.schemas, nil
+}
+
+type generator struct {
+	schemas map[string]*openapi.Schema
+}
+
+// name returns the schema name of a named type, upper-casing the first letter
+// like openapi.DefaultSchemaNamer does.
+func name(t *types.Named) string {
+	n := t.Obj().Name()
+	if args := t.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			if named, ok := args.At(i).(*types.Named); ok {
+				n += name(named)
+			} else {
+				n += args.At(i).String()
+			}
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(n)
+	return string(unicode.ToUpper(r)) + n[size:]
+}
+
+// wellKnownTypes are the named types with a schema of their own, which are
+// described by openapi.SchemaFromType.
+var wellKnownTypes = map[string]reflect.Type{
+	"time.Time":                reflect.TypeOf(time.Time{}),
+	"net/url.URL":              reflect.TypeOf(url.URL{}),
+	"net.IP":                   reflect.TypeOf(net.IP{}),
+	"encoding/json.RawMessage": reflect.TypeOf(json.RawMessage{}),
+}
+
+// marshalsText returns whether encoding/json marshals t with its MarshalText
+// method, i.e. *t implements encoding.TextMarshaler but not json.Marshaler.
+func marshalsText(t *types.Named) bool {
+	methods := types.NewMethodSet(types.NewPointer(t))
+	hasMethod := func(name string, result types.Type) bool {
+		sel := methods.Lookup(nil, name)
+		if sel == nil {
+			return false
+		}
+		sig, ok := sel.Type().(*types.Signature)
+		if !ok || sig.Params().Len() != 0 || sig.Results().Len() != 2 {
+			return false
+		}
+		return types.Identical(sig.Results().At(0).Type(), result) && types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+	}
+	bytes := types.NewSlice(types.Typ[types.Byte])
+	return hasMethod("MarshalText", bytes) && !hasMethod("MarshalJSON", bytes)
+}
+
+// schema returns the schema of t, which is a reference for structs. Other
+// types are described by openapi.SchemaFromType, using the statically
+// generated schema of their elements.
+func (g *generator) schema(t types.Type) *openapi.Schema {
+	base := t
+	if p, ok := base.(*types.Pointer); ok {
+		base = p.Elem()
+	}
+
+	if named, ok := base.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil {
+			if rt, ok := wellKnownTypes[obj.Pkg().Path()+"."+obj.Name()]; ok {
+				if base != t {
+					rt = reflect.PtrTo(rt)
+				}
+				return openapi.SchemaFromType(&staticRegistry{g: g}, rt)
+			}
+		}
+
+		if marshalsText(named) {
+			// Like for reflected types, text marshalers are strings rather
+			// than objects of their fields, and UUIDs get their format.
+			s := &openapi.Schema{Type: openapi.TypeString, Nullable: base != t}
+			if a, ok := named.Underlying().(*types.Array); ok && named.Obj().Name() == "UUID" && a.Len() == 16 {
+				s.Format = "uuid"
+			}
+			return s
+		}
+
+		if st, ok := named.Underlying().(*types.Struct); ok {
+			n := name(named)
+			if _, ok := g.schemas[n]; !ok {
+				// Register first so recursive types get a reference.
+				g.schemas[n] = &openapi.Schema{}
+				g.schemas[n] = g.object(st)
+			}
+			return &openapi.Schema{Ref: Prefix + n}
+		}
+	}
+
+	r := &staticRegistry{g: g}
+	var rt reflect.Type
+	switch u := base.Underlying().(type) {
+	case *types.Basic:
+		if rt = reflectType(u); rt == anyType {
+			return nil
+		}
+	case *types.Slice:
+		if r.schema = g.schema(u.Elem()); r.schema == nil {
+			return nil
+		}
+		rt = reflect.SliceOf(reflectType(u.Elem()))
+	case *types.Array:
+		if r.schema = g.schema(u.Elem()); r.schema == nil {
+			return nil
+		}
+		rt = reflect.ArrayOf(int(u.Len()), reflectType(u.Elem()))
+	case *types.Map:
+		if r.schema = g.schema(u.Elem()); r.schema == nil {
+			return nil
+		}
+		rt = reflect.MapOf(reflect.TypeOf(""), reflectType(u.Elem()))
+	case *types.Struct:
+		return g.object(u)
+	case *types.Interface:
+		rt = anyType
+	default:
+		return nil
+	}
+	if base != t {
+		rt = reflect.PtrTo(rt)
+	}
+
+	return openapi.SchemaFromType(r, rt)
+}
+
+// fields returns the exported fields of st, including those of embedded
+// structs, outer fields taking precedence.
+func fields(st *types.Struct, seen map[*types.Struct]bool) []fieldTag {
+	if seen[st] {
+		return nil
+	}
+	seen[st] = true
+
+	var result, embedded []fieldTag
+	for i := 0; i < st.NumFields(); i++ {
+		f := fieldTag{st.Field(i), reflect.StructTag(st.Tag(i))}
+		if f.Embedded() {
+			embedded = append(embedded, f)
+		} else if f.Exported() {
+			result = append(result, f)
+		}
+	}
+	for _, f := range embedded {
+		t := f.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if inner, ok := t.Underlying().(*types.Struct); ok {
+			result = append(result, fields(inner, seen)...)
+		}
+	}
+	return result
+}
+
+type fieldTag struct {
+	*types.Var
+	tag reflect.StructTag
+}
+
+// object returns the schema of a struct, following the rules of
+// openapi.SchemaFromType.
+func (g *generator) object(st *types.Struct) *openapi.Schema {
+	s := &openapi.Schema{
+		Type:                 openapi.TypeObject,
+		Properties:           map[string]*openapi.Schema{},
+		AdditionalProperties: false,
+	}
+
+	names := map[string]bool{}
+	dependentRequired := map[string][]string{}
+	for _, f := range fields(st, map[*types.Struct]bool{}) {
+		if names[f.Name()] {
+			continue
+		}
+		names[f.Name()] = true
+
+		required := true
+		name := f.Name()
+		if j := f.tag.Get("json"); j != "" {
+			if n, _, _ := strings.Cut(j, ","); n != "" {
+				name = n
+			}
+			if strings.Contains(j, "omitempty") {
+				required = false
+			}
+		}
+		if name == "-" || f.tag.Get("hidden") == "true" {
+			continue
+		}
+		if v, ok := f.tag.Lookup("required"); ok {
+			required = v == "true"
+		}
+		if dr := f.tag.Get("dependentRequired"); strings.TrimSpace(dr) != "" {
+			dependentRequired[name] = strings.Split(dr, ",")
+		}
+
+		fieldSchema := g.schema(f.Type())
+		if fieldSchema == nil {
+			continue
+		}
+		fieldSchema = openapi.SchemaFromField(&staticRegistry{g, fieldSchema}, reflect.StructField{
+			Name: f.Name(),
+			Type: reflectType(f.Type()),
+			Tag:  f.tag,
+		}, "")
+
+		if _, ok := f.Type().(*types.Pointer); ok && strings.Contains(f.tag.Get("json"), "omitempty") && f.tag.Get("nullable") != "true" {
+			fieldSchema.Nullable = false
+		}
+
+		s.Properties[name] = fieldSchema
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	if len(dependentRequired) > 0 {
+		s.DependentRequired = dependentRequired
+	}
+	s.PrecomputeMessages()
+
+	return s
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// reflectType returns a reflect.Type equivalent to t, which is used to convert
+// the values of `default` tags. Structs and other types which can't be
+// constructed at runtime become `any`.
+func reflectType(t types.Type) reflect.Type {
+	if named, ok := t.(*types.Named); ok {
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			return anyType
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Bool:
+			return reflect.TypeOf(false)
+		case types.Int:
+			return reflect.TypeOf(int(0))
+		case types.Int8:
+			return reflect.TypeOf(int8(0))
+		case types.Int16:
+			return reflect.TypeOf(int16(0))
+		case types.Int32:
+			return reflect.TypeOf(int32(0))
+		case types.Int64:
+			return reflect.TypeOf(int64(0))
+		case types.Uint:
+			return reflect.TypeOf(uint(0))
+		case types.Uint8:
+			return reflect.TypeOf(uint8(0))
+		case types.Uint16:
+			return reflect.TypeOf(uint16(0))
+		case types.Uint32:
+			return reflect.TypeOf(uint32(0))
+		case types.Uint64:
+			return reflect.TypeOf(uint64(0))
+		case types.Float32:
+			return reflect.TypeOf(float32(0))
+		case types.Float64:
+			return reflect.TypeOf(float64(0))
+		case types.String:
+			return reflect.TypeOf("")
+		}
+	case *types.Pointer:
+		return reflect.PtrTo(reflectType(u.Elem()))
+	case *types.Slice:
+		return reflect.SliceOf(reflectType(u.Elem()))
+	}
+	return anyType
+}
+
+// staticRegistry hands a statically generated schema to the openapi package,
+// as the schema of a struct field or of the elements of a slice or map, so it
+// is described exactly like for reflected types.
+type staticRegistry struct {
+	g      *generator
+	schema *openapi.Schema
+}
+
+func (r *staticRegistry) Schema(t reflect.Type, allowRef bool, hint string) *openapi.Schema {
+	return r.schema
+}
+
+func (r *staticRegistry) SchemaFromRef(ref string) *openapi.Schema {
+	return r.g.schemas[strings.TrimPrefix(ref, Prefix)]
+}
+
+func (r *staticRegistry) TypeFromRef(ref string) reflect.Type {
+	return nil
+}
+
+func (r *staticRegistry) Map() map[string]*openapi.Schema {
+	return r.g.schemas
+}
+
+func (r *staticRegistry) RegisterTypeAlias(t reflect.Type, alias reflect.Type) {}
@@ -0,0 +1,90 @@
+package static_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+	"github.com/restk/openapi/static"
+)
+
+// UUID mirrors the UUID of the test package, and github.com/google/uuid.UUID.
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func TestSchemas(t *testing.T) {
+	schemas, err := static.Schemas("testdata/api", "Team")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(schemas) != 2 {
+		t.Fatalf("expected Team and User schemas, got %v", schemas)
+	}
+
+	team := schemas["Team"]
+	if team.Properties["members"].Items.Ref != "#/components/schemas/User" {
+		t.Errorf("unexpected members %v", team.Properties["members"].Items)
+	}
+	if team.Properties["updatedBy"] == nil || team.Properties["hidden"] != nil {
+		t.Errorf("expected embedded fields and no hidden fields, got %v", team.Properties)
+	}
+	if team.Properties["logo"].ContentEncoding != "base64" {
+		t.Errorf("expected base64 logo, got %v", team.Properties["logo"])
+	}
+
+	// Types other than structs are described like the reflected generator does.
+	registry := openapi.NewMapRegistry(static.Prefix, openapi.DefaultSchemaNamer)
+	reflected := openapi.SchemaFromType(registry, reflect.TypeOf(struct {
+		Labels map[string]string `json:"labels,omitempty"`
+		Scores [3]uint16         `json:"scores"`
+		ID     UUID              `json:"id"`
+		Addr   *netip.Addr       `json:"addr"`
+		IP     net.IP            `json:"ip,omitempty"`
+	}{}))
+	for _, prop := range []string{"labels", "scores", "id", "addr", "ip"} {
+		got, _ := json.Marshal(team.Properties[prop])
+		want, _ := json.Marshal(reflected.Properties[prop])
+		if string(got) != string(want) {
+			t.Errorf("expected %s to be %s, got %s", prop, want, got)
+		}
+	}
+
+	user := schemas["User"]
+	if _, ok := user.Properties["secret"]; ok {
+		t.Errorf("expected unexported fields to be skipped")
+	}
+	if id := user.Properties["id"]; *id.Minimum != 1 || id.Description != "The user ID" || id.Format != "int64" {
+		t.Errorf("unexpected id %v", id)
+	}
+	if role := user.Properties["role"]; role.Default != "member" || len(role.Enum) != 2 {
+		t.Errorf("unexpected role %v", role)
+	}
+	if created := user.Properties["created"]; created.Format != "date-time" {
+		t.Errorf("unexpected created %v", created)
+	}
+
+	required, _ := json.Marshal(user.Required)
+	if string(required) != `["id","name","role","created"]` {
+		t.Errorf("unexpected required %s", required)
+	}
+
+	res := &openapi.ValidateResult{}
+	openapi.Validate(registry, user, &openapi.PathBuffer{}, openapi.ModeWriteToServer, map[string]any{"id": 0.0, "name": "a", "role": "owner", "created": "2024-01-01T00:00:00Z"}, res)
+	if len(res.Errors) != 2 {
+		t.Errorf("expected minimum and enum errors, got %v", res.Errors)
+	}
+}
+
+func TestSchemasMissingType(t *testing.T) {
+	if _, err := static.Schemas("testdata/api", "Missing"); err == nil {
+		t.Errorf("expected an error for a missing type")
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"time"
+)
+
+type UUID [16]byte
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+type user struct {
+	ID      int       `json:"id" minimum:"1" doc:"The user ID"`
+	Name    string    `json:"name" maxLength:"80"`
+	Email   *string   `json:"email,omitempty" format:"email"`
+	Role    string    `json:"role" enum:"admin,member" default:"member"`
+	Created time.Time `json:"created"`
+	Friends []*user   `json:"friends,omitempty"`
+	secret  string
+}
+
+type Team struct {
+	Audit
+	Name    string            `json:"name"`
+	Members []user            `json:"members"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Logo    []byte            `json:"logo,omitempty"`
+	Scores  [3]uint16         `json:"scores"`
+	ID      UUID              `json:"id"`
+	Addr    *netip.Addr       `json:"addr"`
+	IP      net.IP            `json:"ip,omitempty"`
+	Hidden  string            `json:"hidden" hidden:"true"`
+}
+
+type Audit struct {
+	UpdatedBy string `json:"updatedBy"`
+}
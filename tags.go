@@ -0,0 +1,177 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// schemaTags are the struct tags used to generate schemas, along with the
+// schema types they apply to. An empty list means any type.
+var schemaTags = map[string][]string{
+	"doc":                  nil,
+	"docKey":               nil,
+	"format":               nil,
+	"timeFormat":           {TypeString},
+	"encoding":             {TypeString},
+	"contentMediaType":     {TypeString},
+	"default":              nil,
+	"example":              nil,
+	"enum":                 nil,
+	"nullable":             nil,
+	"minimum":              {TypeInteger, TypeNumber},
+	"exclusiveMinimum":     {TypeInteger, TypeNumber},
+	"maximum":              {TypeInteger, TypeNumber},
+	"exclusiveMaximum":     {TypeInteger, TypeNumber},
+	"multipleOf":           {TypeInteger, TypeNumber},
+	"minLength":            {TypeString},
+	"maxLength":            {TypeString},
+	"pattern":              {TypeString},
+	"patternDescription":   {TypeString},
+	"minItems":             {TypeArray},
+	"maxItems":             {TypeArray},
+	"uniqueItems":          {TypeArray},
+	"minProperties":        {TypeObject},
+	"maxProperties":        {TypeObject},
+	"additionalProperties": {TypeObject},
+	"dependentRequired":    {TypeObject},
+	"readOnly":             nil,
+	"writeOnly":            nil,
+	"deprecated":           nil,
+	"hidden":               nil,
+	"required":             nil,
+	"sensitive":            nil,
+	"classification":       nil,
+}
+
+// schemaTagNames are the keys of schemaTags, sorted.
+var schemaTagNames = func() []string {
+	names := make([]string, 0, len(schemaTags))
+	for name := range schemaTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// parseTagKeys returns the keys of a struct tag in the conventional
+// `key:"value" key2:"value2"` format.
+func parseTagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	s := string(tag)
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		s = s[i:]
+
+		i = 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			break
+		}
+		keys = append(keys, s[:i])
+		s = s[i+1:]
+
+		// Skip the quoted value.
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		s = s[i+1:]
+	}
+	return keys
+}
+
+// checkTags checks the tags of field f of struct t for tags which look like
+// misspelled schema tags, schema tags which don't apply to the type of the
+// field, or bounds no value could satisfy, e.g. `minItems:"5" maxItems:"1"`.
+// Tags of other libraries, e.g. `db`, are ignored as long as they aren't close
+// to a schema tag. Strict registries panic on the first problem, others record
+// it as a warning.
+func (r *mapRegistry) checkTags(t reflect.Type, f reflect.StructField, fs *Schema) {
+	report := func(err error) {
+		if r.strict {
+			panic(err)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.warnings = append(r.warnings, &ErrorDetail{
+			Message:  err.Error(),
+			Location: t.Name() + "." + f.Name,
+		})
+	}
+
+	for _, err := range []error{
+		checkBounds(f, "minimum", "maximum", fs.Minimum, fs.Maximum, false),
+		checkBounds(f, "exclusiveMinimum", "exclusiveMaximum", fs.ExclusiveMinimum, fs.ExclusiveMaximum, true),
+		checkBounds(f, "minLength", "maxLength", fs.MinLength, fs.MaxLength, false),
+		checkBounds(f, "minItems", "maxItems", fs.MinItems, fs.MaxItems, false),
+		checkBounds(f, "minProperties", "maxProperties", fs.MinProperties, fs.MaxProperties, false),
+	} {
+		if err != nil {
+			report(err)
+		}
+	}
+
+	for _, key := range parseTagKeys(f.Tag) {
+		types, ok := schemaTags[key]
+		if !ok {
+			if suggestion := closestTag(key, schemaTagNames); suggestion != "" {
+				report(fmt.Errorf("unknown tag '%s' for field '%s', did you mean '%s'?", key, f.Name, suggestion))
+			}
+			continue
+		}
+
+		if len(types) == 0 || fs.Ref != "" {
+			continue
+		}
+
+		applies := false
+		for _, t := range types {
+			if fs.Type == t {
+				applies = true
+			}
+		}
+		if !applies {
+			report(fmt.Errorf("tag '%s' for field '%s' does not apply to type '%s'", key, f.Name, fs.Type))
+		}
+	}
+}
+
+// closestTag returns the known tag which key is most likely a misspelling of.
+// This is stricter than closestMatch so tags of other libraries, e.g. `form`,
+// are not mistaken for schema tags, e.g. `format`.
+func closestTag(key string, known []string) string {
+	allowed := 1
+	if len(key) >= 6 {
+		allowed = 2
+	}
+
+	best, bestDistance := "", allowed+1
+	for _, name := range known {
+		if diff := len(key) - len(name); diff > allowed || -diff > allowed {
+			// The distance is at least the difference in length.
+			continue
+		}
+		if d := levenshtein(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
@@ -0,0 +1,122 @@
+package openapi_test
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type StrictUser struct {
+	Name  string `json:"name" form:"name" db:"name" maxLength:"80"`
+	Email string `json:"email" maxLenght:"80"`
+}
+
+type StrictOrder struct {
+	Code string `json:"code" minimum:"1"`
+}
+
+func TestStrictTags(t *testing.T) {
+	register := func(body any) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		builder := openapi.New("title", "version").RegistryOptions(openapi.StrictTags())
+		builder.Register(&openapi.Operation{
+			OperationID: "create",
+			Method:      http.MethodPost,
+			Path:        "/create",
+		}).Request().Body(body)
+		return nil
+	}
+
+	err := register(StrictUser{})
+	if err == nil || err.Error() != "unknown tag 'maxLenght' for field 'Email', did you mean 'maxLength'?" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err = register(StrictOrder{})
+	if err == nil || err.Error() != "tag 'minimum' for field 'Code' does not apply to type 'string'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Without strict mode the tags are ignored.
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "create",
+		Method:      http.MethodPost,
+		Path:        "/create",
+	}).Request().Body(StrictUser{})
+}
+
+type ConstrainedItem struct {
+	Quantity int      `json:"quantity" minimum:"1" maximum:"100" multipleOf:"1"`
+	Price    float64  `json:"price" exclusiveMinimum:"0"`
+	SKU      string   `json:"sku" pattern:"^[A-Z0-9]+$" minLength:"4" maxLength:"12"`
+	Tags     []string `json:"tags" minItems:"1" maxItems:"5" uniqueItems:"true"`
+}
+
+type InvertedItem struct {
+	Tags []string `json:"tags" minItems:"5" maxItems:"1"`
+}
+
+type EmptyRangeItem struct {
+	Score float64 `json:"score" exclusiveMinimum:"1" exclusiveMaximum:"1"`
+}
+
+func TestConstraintTags(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "createItem",
+		Method:      http.MethodPost,
+		Path:        "/items",
+	}).Request().Body(ConstrainedItem{})
+
+	s := builder.OpenAPI().Components.Schemas.Map()["ConstrainedItem"]
+	quantity, price, sku, tags := s.Properties["quantity"], s.Properties["price"], s.Properties["sku"], s.Properties["tags"]
+	if *quantity.Minimum != 1 || *quantity.Maximum != 100 || *price.ExclusiveMinimum != 0 {
+		t.Errorf("unexpected number constraints %v %v", quantity, price)
+	}
+	if sku.Pattern != "^[A-Z0-9]+$" || *sku.MinLength != 4 || *sku.MaxLength != 12 {
+		t.Errorf("unexpected string constraints %v", sku)
+	}
+	if *tags.MinItems != 1 || *tags.MaxItems != 5 || !tags.UniqueItems {
+		t.Errorf("unexpected array constraints %v", tags)
+	}
+
+	builder.Register(&openapi.Operation{
+		OperationID: "createInverted",
+		Method:      http.MethodPost,
+		Path:        "/inverted",
+	}).Request().Body(InvertedItem{})
+	builder.Register(&openapi.Operation{
+		OperationID: "createEmptyRange",
+		Method:      http.MethodPost,
+		Path:        "/empty-range",
+	}).Request().Body(EmptyRangeItem{})
+
+	var messages []string
+	for _, w := range builder.Warnings() {
+		messages = append(messages, w.Message)
+	}
+	expected := []string{
+		"tag 'minItems' is greater than tag 'maxItems' for field 'Tags': 5 > 1",
+		"tag 'exclusiveMinimum' is not less than tag 'exclusiveMaximum' for field 'Score': 1 >= 1",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected bound warnings, got %v", messages)
+	}
+
+	strict := openapi.NewMapRegistry("#/components/schemas/", openapi.DefaultSchemaNamer, openapi.StrictTags())
+	defer func() {
+		if r := recover(); r == nil || fmt.Sprint(r) != expected[0] {
+			t.Errorf("unexpected panic %v", r)
+		}
+	}()
+	strict.Schema(reflect.TypeOf(InvertedItem{}), true, "")
+}
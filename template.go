@@ -0,0 +1,53 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// renderTemplates executes every title, summary and description of the
+// document which contains a placeholder as a text/template with the given
+// values. Unknown placeholders are an error so typos don't get published.
+func renderTemplates(o *OpenAPI, values map[string]any) error {
+	var err error
+
+	walkStructs(reflect.ValueOf(o), func(v reflect.Value) {
+		if err != nil {
+			return
+		}
+
+		for _, name := range []string{"Title", "Summary", "Description"} {
+			f := v.FieldByName(name)
+			if f.Kind() != reflect.String || !strings.Contains(f.String(), "{{") {
+				continue
+			}
+
+			field := v.Type().Name() + "." + name
+
+			var tmpl *template.Template
+			tmpl, err = template.New(field).Option("missingkey=error").Parse(f.String())
+			if err != nil {
+				err = fmt.Errorf("unable to parse template: %w", err)
+				return
+			}
+
+			var sb strings.Builder
+			if err = tmpl.Execute(&sb, values); err != nil {
+				err = fmt.Errorf("unable to render template: %w", err)
+				return
+			}
+
+			f.SetString(sb.String())
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,42 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestBuildTemplates(t *testing.T) {
+	builder := openapi.New("{{.ProductName}} API", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "listUsers",
+		Method:      http.MethodGet,
+		Path:        "/users",
+		Description: "Lists users. Limited to {{.RateLimit}} requests per minute.",
+	})
+	builder.TemplateValues(map[string]any{
+		"ProductName": "Acme",
+		"RateLimit":   100,
+	})
+
+	o, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Info.Title != "Acme API" {
+		t.Errorf("unexpected title %q", o.Info.Title)
+	}
+	if desc := o.Paths["/users"].Get.Description; desc != "Lists users. Limited to 100 requests per minute." {
+		t.Errorf("unexpected description %q", desc)
+	}
+	if builder.OpenAPI().Info.Title != "{{.ProductName}} API" {
+		t.Errorf("expected builder document to be untouched")
+	}
+
+	builder.OpenAPI().Info.Description = "{{.Unknown}}"
+	if _, err := builder.Build(); err == nil {
+		t.Errorf("expected error for unknown placeholder")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OneOf returns a schema matching exactly one of the types of values, e.g.
+// `OneOf(registry, Cat{}, Dog{})`. The types are added to the registry like
+// bodies, so named structs are referenced.
+func OneOf(registry Registry, values ...any) *Schema {
+	if len(values) < 2 {
+		panic("oneOf needs at least two types")
+	}
+
+	return &Schema{OneOf: composedSchemas(registry, values)}
+}
+
+// AnyOf returns a schema matching at least one of the types of values. See
+// OneOf.
+func AnyOf(registry Registry, values ...any) *Schema {
+	if len(values) < 2 {
+		panic("anyOf needs at least two types")
+	}
+
+	return &Schema{AnyOf: composedSchemas(registry, values)}
+}
+
+// AllOf returns a schema matching all of the types of values, e.g. to extend
+// a base type, `AllOf(registry, Resource{}, User{})`. See OneOf.
+func AllOf(registry Registry, values ...any) *Schema {
+	if len(values) < 1 {
+		panic("allOf needs at least one type")
+	}
+
+	return &Schema{AllOf: composedSchemas(registry, values)}
+}
+
+func composedSchemas(registry Registry, values []any) []*Schema {
+	schemas := make([]*Schema, 0, len(values))
+	for _, v := range values {
+		schemas = append(schemas, registry.Schema(reflect.TypeOf(v), true, ""))
+	}
+	return schemas
+}
+
+// RegisterPolymorphic adds a polymorphic base type to the registry as the
+// component schema name, which is one of the types of mapping and uses
+// propertyName as the discriminator, e.g.
+//
+//	RegisterPolymorphic(registry, "Pet", "type", map[string]any{"cat": Cat{}, "dog": Dog{}})
+//
+// It returns a reference to the schema. Registering the same name again
+// replaces the schema, so a base type can implement SchemaProvider to be used
+// as a body:
+//
+//	func (Pet) Schema(r openapi.Registry) *openapi.Schema {
+//		return openapi.RegisterPolymorphic(r, "Pet", "type", petTypes)
+//	}
+//
+// Panics if the name is taken by a schema which isn't polymorphic or if a
+// mapped type isn't a named struct.
+func RegisterPolymorphic(registry Registry, name, propertyName string, mapping map[string]any) *Schema {
+	if name == "" {
+		panic("polymorphic type name must be specified")
+	}
+	if len(mapping) < 2 {
+		panic("polymorphic type " + name + " needs at least two types")
+	}
+
+	values := make([]string, 0, len(mapping))
+	for value := range mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	discriminator := newDiscriminator(registry, propertyName, mapping)
+	schema := &Schema{Discriminator: discriminator}
+	seen := map[string]bool{}
+	for _, value := range values {
+		if ref := discriminator.Mapping[value]; !seen[ref] {
+			seen[ref] = true
+			schema.OneOf = append(schema.OneOf, &Schema{Ref: ref})
+		}
+	}
+
+	registerSchema(registry, name, schema, func(existing *Schema, t reflect.Type) bool {
+		if existing.Discriminator == nil {
+			panic("duplicate name: " + name + " is already registered")
+		}
+		return false
+	})
+
+	ref := discriminator.Mapping[values[0]]
+	return &Schema{Ref: ref[:strings.LastIndex(ref, "/")+1] + name}
+}
@@ -0,0 +1,161 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+type Cat struct {
+	Type  string `json:"type"`
+	Lives int    `json:"lives"`
+}
+
+type Dog struct {
+	Type  string `json:"type"`
+	Breed string `json:"breed"`
+}
+
+func TestOneOfBodies(t *testing.T) {
+	builder := openapi.New("title", "version")
+	getPet := builder.Register(&openapi.Operation{
+		OperationID: "getPet",
+		Method:      http.MethodGet,
+		Path:        "/pet",
+	})
+	getPet.Response(http.StatusOK).Description("A cat or a dog").
+		OneOfBodies(Cat{}, Dog{}).
+		Discriminator("type", map[string]any{"cat": Cat{}, "dog": Dog{}})
+
+	b, err := json.Marshal(builder.OpenAPI().Paths["/pet"].Get.Responses["200"].Content["application/json"].Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"discriminator":{"mapping":{"cat":"#/components/schemas/Cat","dog":"#/components/schemas/Dog"},"propertyName":"type"},"oneOf":[{"$ref":"#/components/schemas/Cat"},{"$ref":"#/components/schemas/Dog"}]}`
+	if string(b) != expected {
+		t.Errorf("unexpected schema %s", b)
+	}
+
+	schemas := builder.OpenAPI().Components.Schemas.Map()
+	if schemas["Cat"] == nil || schemas["Dog"] == nil {
+		t.Errorf("expected Cat and Dog to be registered, got %v", schemas)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "named struct") {
+			t.Errorf("expected panic for unnamed mapping, got %v", r)
+		}
+	}()
+	getPet.Response(http.StatusAccepted).Description("Pending").
+		OneOfBodies(Cat{}, Dog{}).
+		Discriminator("type", map[string]any{"cat": openapi.StringType})
+}
+
+func TestComposedSchemas(t *testing.T) {
+	builder := openapi.New("title", "version")
+	registry := builder.OpenAPI().Components.Schemas
+
+	if s := openapi.AnyOf(registry, Cat{}, Dog{}); len(s.AnyOf) != 2 || s.AnyOf[0].Ref != "#/components/schemas/Cat" {
+		t.Errorf("unexpected anyOf schema %v", s)
+	}
+
+	createPet := builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/pets",
+	})
+	createPet.Request().OneOfBodies(Cat{}, Dog{})
+	createPet.Response(http.StatusCreated).Description("Created").
+		AllOfBodies(Cat{}, Dog{})
+	createPet.Response(http.StatusOK).Description("OK").Body(Cat{}).AnyOf(Cat{}, Dog{})
+
+	op := builder.OpenAPI().Paths["/pets"].Post
+	if s := op.RequestBody.Content["application/json"].Schema; len(s.OneOf) != 2 {
+		t.Errorf("unexpected request schema %v", s)
+	}
+	if s := op.Responses["201"].Content["application/json"].Schema; len(s.AllOf) != 2 {
+		t.Errorf("unexpected created schema %v", s)
+	}
+	if s := op.Responses["200"].Content["application/json"].Schema; len(s.AnyOf) != 2 || s.Ref != "" {
+		t.Errorf("unexpected ok schema %v", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a single oneOf type")
+		}
+	}()
+	openapi.OneOf(registry, Cat{})
+}
+
+type Pet struct{}
+
+func (Pet) Schema(r openapi.Registry) *openapi.Schema {
+	return openapi.RegisterPolymorphic(r, "Pet", "type", map[string]any{"cat": Cat{}, "dog": Dog{}})
+}
+
+type PetList struct {
+	Pets []Pet `json:"pets"`
+}
+
+func TestRegisterPolymorphic(t *testing.T) {
+	builder := openapi.New("title", "version")
+	listPets := builder.Register(&openapi.Operation{
+		OperationID: "listPets",
+		Method:      http.MethodGet,
+		Path:        "/pets",
+	})
+	listPets.Response(http.StatusOK).Description("OK").Body(PetList{})
+	listPets.Response(http.StatusCreated).Description("Created").Body(Pet{})
+
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Fatalf("expected a valid spec, got %v", errs)
+	}
+
+	schemas := builder.OpenAPI().Components.Schemas.Map()
+	b, err := json.Marshal(schemas["Pet"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"discriminator":{"mapping":{"cat":"#/components/schemas/Cat","dog":"#/components/schemas/Dog"},"propertyName":"type"},"oneOf":[{"$ref":"#/components/schemas/Cat"},{"$ref":"#/components/schemas/Dog"}]}`
+	if string(b) != expected {
+		t.Errorf("unexpected schema %s", b)
+	}
+	if ref := schemas["PetList"].Properties["pets"].Items.Ref; ref != "#/components/schemas/Pet" {
+		t.Errorf("expected a reference to Pet, got %q", ref)
+	}
+	if ref := builder.OpenAPI().Paths["/pets"].Get.Responses["201"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/Pet" {
+		t.Errorf("expected a reference to Pet, got %q", ref)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "duplicate name") {
+			t.Errorf("expected panic for a taken name, got %v", r)
+		}
+	}()
+	openapi.RegisterPolymorphic(builder.OpenAPI().Components.Schemas, "Cat", "type", map[string]any{"cat": Cat{}, "dog": Dog{}})
+}
+
+func TestRegisterPolymorphicOrder(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Layout(openapi.Layout{Schemas: openapi.OrderRegistration})
+	pets := builder.Register(&openapi.Operation{
+		OperationID: "createPet",
+		Method:      http.MethodPost,
+		Path:        "/pets",
+	})
+	pets.Response(http.StatusCreated).Description("Created").Body(Pet{})
+	pets.Response(http.StatusOK).Description("OK").Body(LayoutZebra{})
+
+	data, err := builder.OpenAPI().JSONBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemas := keyOrder(t, data, "components", "schemas"); !reflect.DeepEqual(schemas, []string{"Cat", "Dog", "Pet", "LayoutZebra"}) {
+		t.Errorf("expected the polymorphic schema in registration order, got %v", schemas)
+	}
+}
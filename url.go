@@ -0,0 +1,98 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// URL builds a concrete URL for the operation with the given operation ID by
+// filling in its path template, e.g. `/users/{userId}` becomes `/users/123`,
+// and appending query as the query string. Values are validated against the
+// schemas of the declared parameters and every path parameter must be given.
+// The URL is relative to the server.
+func (o *OpenAPI) URL(operationID string, params map[string]any, query map[string]any) (string, error) {
+	path, op := o.findOperation(operationID)
+	if op == nil {
+		return "", fmt.Errorf("unknown operation %q", operationID)
+	}
+
+	declared := map[[2]string]*Param{}
+	for _, p := range o.operationParams(path, op) {
+		declared[[2]string{p.In, p.Name}] = p
+	}
+
+	var registry Registry
+	if o.Components != nil {
+		registry = o.Components.Schemas
+	}
+
+	pb := NewPathBuffer([]byte{}, 0)
+	res := &ValidateResult{}
+	validate := func(in, name string, v any) {
+		p := declared[[2]string{in, name}]
+		if p == nil || p.Schema == nil || registry == nil {
+			return
+		}
+		pb.Reset()
+		pb.Push(in)
+		pb.Push(name)
+		Validate(registry, p.Schema, pb, ModeWriteToServer, v, res)
+	}
+
+	var missing []string
+	u := pathTemplateRe.ReplaceAllStringFunc(path, func(match string) string {
+		name := match[1 : len(match)-1]
+		v, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		validate("path", name, v)
+		return url.PathEscape(fmt.Sprint(v))
+	})
+
+	for _, name := range missing {
+		res.Errors = append(res.Errors, &ErrorDetail{
+			Message:  "missing path parameter",
+			Location: "path." + name,
+		})
+	}
+
+	if len(query) > 0 {
+		names := make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		values := url.Values{}
+		for _, name := range names {
+			v := query[name]
+			validate("query", name, v)
+
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+				for i := 0; i < rv.Len(); i++ {
+					values.Add(name, fmt.Sprint(rv.Index(i).Interface()))
+				}
+				continue
+			}
+			values.Add(name, fmt.Sprint(v))
+		}
+
+		u += "?" + values.Encode()
+	}
+
+	if len(res.Errors) > 0 {
+		return "", errorList(res.Errors)
+	}
+
+	return u, nil
+}
@@ -37,6 +37,11 @@ const (
 	// reject read-only fields that are non-zero, as these are owned by the
 	// server and the client should not try to modify them.
 	ModeWriteToServer
+
+	// ModeWriteToServerStrict is like ModeWriteToServer but rejects read-only
+	// fields that are non-zero, for APIs which don't want clients to send
+	// them back.
+	ModeWriteToServerStrict
 )
 
 var rxHostname = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]{0,61}[a-zA-Z0-9]))*$`)
@@ -348,6 +353,11 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 	}
 
 	switch s.Type {
+	case TypeNull:
+		if v != nil {
+			res.Add(path, v, "expected null")
+			return
+		}
 	case TypeBoolean:
 		if _, ok := v.(bool); !ok {
 			res.Add(path, v, "expected boolean")
@@ -560,8 +570,12 @@ func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode,
 		}
 
 		// We should be permissive by default to enable easy round-trips for the
-		// client without needing to remove read-only values.
-		// TODO: should we make this configurable?
+		// client without needing to remove read-only values, unless the strict
+		// mode is used.
+		if mode == ModeWriteToServerStrict && readOnly && m[k] != nil && !reflect.ValueOf(m[k]).IsZero() {
+			res.Add(path, m[k], "read only property is non-zero")
+			continue
+		}
 
 		// Be stricter for responses, enabling validation of the server if desired.
 		if mode == ModeReadFromServer && writeOnly && m[k] != nil && !reflect.ValueOf(m[k]).IsZero() {
@@ -573,7 +587,7 @@ func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode,
 			if !s.requiredMap[k] {
 				continue
 			}
-			if (mode == ModeWriteToServer && readOnly) ||
+			if (mode != ModeReadFromServer && readOnly) ||
 				(mode == ModeReadFromServer && writeOnly) {
 				// These are not required for the current mode.
 				continue
@@ -654,8 +668,12 @@ func handleMapAny(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m
 		}
 
 		// We should be permissive by default to enable easy round-trips for the
-		// client without needing to remove read-only values.
-		// TODO: should we make this configurable?
+		// client without needing to remove read-only values, unless the strict
+		// mode is used.
+		if mode == ModeWriteToServerStrict && readOnly && m[k] != nil && !reflect.ValueOf(m[k]).IsZero() {
+			res.Add(path, m[k], "read only property is non-zero")
+			continue
+		}
 
 		// Be stricter for responses, enabling validation of the server if desired.
 		if mode == ModeReadFromServer && writeOnly && m[k] != nil && !reflect.ValueOf(m[k]).IsZero() {
@@ -667,7 +685,7 @@ func handleMapAny(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m
 			if !s.requiredMap[k] {
 				continue
 			}
-			if (mode == ModeWriteToServer && readOnly) ||
+			if (mode != ModeReadFromServer && readOnly) ||
 				(mode == ModeReadFromServer && writeOnly) {
 				// These are not required for the current mode.
 				continue
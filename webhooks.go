@@ -0,0 +1,121 @@
+// Copyright 2024 Arianit Uka
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package openapi
+
+import (
+	"reflect"
+	"sort"
+)
+
+// CallbacksToWebhooks promotes the callbacks of every operation into the
+// top-level `webhooks` map (OpenAPI 3.1), keyed by the callback event name.
+// If an event has several callback expressions, their operations are merged
+// into a single webhook and the first expression (sorted) wins when two of
+// them define the same method. Webhooks which already exist are left alone,
+// e.g. when two operations define the same event, and the webhooks are copies
+// of the callbacks.
+//
+// If remove is true the callbacks are deleted from their operations once they
+// have been promoted, so the callbacks which were left alone are kept.
+func (o *OpenAPI) CallbacksToWebhooks(remove bool) {
+	o.WalkOperations(func(path, method string, op *Operation) {
+		events := make([]string, 0, len(op.Callbacks))
+		for event := range op.Callbacks {
+			events = append(events, event)
+		}
+		sort.Strings(events)
+
+		for _, event := range events {
+			if _, ok := o.Webhooks[event]; ok {
+				continue
+			}
+			if o.Webhooks == nil {
+				o.Webhooks = map[string]*PathItem{}
+			}
+			o.Webhooks[event] = mergeCallback(op.Callbacks[event])
+
+			if remove {
+				delete(op.Callbacks, event)
+			}
+		}
+
+		if remove && len(op.Callbacks) == 0 {
+			op.Callbacks = nil
+		}
+	})
+	o.Invalidate()
+}
+
+// WebhooksToCallbacks is the reverse of CallbacksToWebhooks and is meant for
+// documents that must be downgraded to OpenAPI 3.0, which has no webhooks.
+// Every webhook is attached to op as a callback event of the same name, using
+// expression as the callback URL, e.g. `{$request.body#/callbackUrl}`. Events
+// which op already defines are left alone, and the callbacks are copies of
+// the webhooks.
+//
+// If remove is true the webhooks are deleted from the document once they
+// have been attached, so the webhooks which were left alone are kept.
+func (o *OpenAPI) WebhooksToCallbacks(op *Operation, expression string, remove bool) {
+	if expression == "" {
+		panic("expression must be specified")
+	}
+
+	for name, item := range o.Webhooks {
+		if _, ok := op.Callbacks[name]; ok {
+			continue
+		}
+		if op.Callbacks == nil {
+			op.Callbacks = map[string]map[string]*PathItem{}
+		}
+		op.Callbacks[name] = map[string]*PathItem{
+			expression: clonePathItem(item),
+		}
+
+		if remove {
+			delete(o.Webhooks, name)
+		}
+	}
+
+	if remove && len(o.Webhooks) == 0 {
+		o.Webhooks = nil
+	}
+	o.Invalidate()
+}
+
+// mergeCallback returns a single path item containing copies of every
+// operation of the callback's expressions.
+func mergeCallback(callback map[string]*PathItem) *PathItem {
+	expressions := make([]string, 0, len(callback))
+	for expression := range callback {
+		expressions = append(expressions, expression)
+	}
+	sort.Strings(expressions)
+
+	if len(expressions) == 1 {
+		return clonePathItem(callback[expressions[0]])
+	}
+
+	merged := &PathItem{}
+	for _, expression := range expressions {
+		clonePathItem(callback[expression]).WalkOperations(func(method string, op *Operation) {
+			if merged.Operation(method) != nil {
+				return
+			}
+			op.Method = method
+			merged.SetOperation(op)
+		})
+	}
+
+	return merged
+}
+
+// clonePathItem returns a deep copy of item, so webhooks and callbacks don't
+// share their operations.
+func clonePathItem(item *PathItem) *PathItem {
+	c := &cloner{seen: map[cloneKey]reflect.Value{}}
+	return c.clone(reflect.ValueOf(item)).Interface().(*PathItem)
+}
@@ -0,0 +1,204 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/restk/openapi"
+)
+
+func TestCallbacksToWebhooks(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	subscribe := builder.Register(&openapi.Operation{
+		OperationID: "subscribe",
+		Method:      http.MethodPost,
+		Path:        "/subscribe",
+	})
+	subscribe.Callback("userCreated", &openapi.Operation{
+		Method: http.MethodPost,
+		Path:   "{$request.body#/callbackUrl}",
+	})
+
+	o := builder.OpenAPI()
+	o.CallbacksToWebhooks(false)
+
+	webhook := o.Webhooks["userCreated"]
+	if webhook == nil || webhook.Post == nil {
+		t.Fatalf("expected userCreated webhook with a POST operation, got %v", o.Webhooks)
+	}
+	callback := o.Paths["/subscribe"].Post.Callbacks["userCreated"]
+	if len(callback) != 1 {
+		t.Fatalf("expected callbacks to be kept, got %v", o.Paths["/subscribe"].Post.Callbacks)
+	}
+	if callback["{$request.body#/callbackUrl}"].Post == webhook.Post {
+		t.Errorf("expected the webhook to be a copy of the callback")
+	}
+
+	o.CallbacksToWebhooks(true)
+	if subscribe := o.Paths["/subscribe"].Post; len(subscribe.Callbacks) != 1 {
+		t.Errorf("expected callbacks left alone to be kept, got %v", subscribe.Callbacks)
+	}
+
+	o.Webhooks = nil
+	o.CallbacksToWebhooks(true)
+	if subscribe := o.Paths["/subscribe"].Post; subscribe.Callbacks != nil {
+		t.Errorf("expected callbacks to be removed, got %v", subscribe.Callbacks)
+	}
+
+	op := o.Paths["/subscribe"].Post
+	o.WebhooksToCallbacks(op, "{$request.body#/callbackUrl}", true)
+	if o.Webhooks != nil {
+		t.Errorf("expected webhooks to be removed, got %v", o.Webhooks)
+	}
+	if item := op.Callbacks["userCreated"]["{$request.body#/callbackUrl}"]; item == nil || item.Post == nil {
+		t.Errorf("expected userCreated callback, got %v", op.Callbacks)
+	}
+
+	o.Webhooks = map[string]*openapi.PathItem{"userCreated": {Post: &openapi.Operation{Summary: "other"}}}
+	o.WebhooksToCallbacks(op, "{$request.body#/callbackUrl}", true)
+	if o.Webhooks["userCreated"] == nil {
+		t.Errorf("expected webhooks left alone to be kept, got %v", o.Webhooks)
+	}
+}
+
+func TestCallbackBuilder(t *testing.T) {
+	builder := openapi.New("title", "version")
+
+	subscribe := builder.Register(&openapi.Operation{
+		OperationID: "subscribe",
+		Method:      http.MethodPost,
+		Path:        "/subscribe",
+	})
+	subscribe.CallbackEvent("userCreated").
+		Operation(http.MethodPost, openapi.Expr.RequestBody("callbackUrl")).
+		Summary("User created")
+	subscribe.CallbackEvent("userDeleted").
+		Operation(http.MethodPost, "https://example.com/hooks?id=", openapi.Expr.RequestBody("user", "id"))
+
+	callbacks := builder.OpenAPI().Paths["/subscribe"].Post.Callbacks
+	if item := callbacks["userCreated"]["{$request.body#/callbackUrl}"]; item == nil || item.Post.Summary != "User created" {
+		t.Errorf("unexpected userCreated callback %v", callbacks["userCreated"])
+	}
+	if item := callbacks["userDeleted"]["https://example.com/hooks?id={$request.body#/user/id}"]; item == nil {
+		t.Errorf("unexpected userDeleted callback %v", callbacks["userDeleted"])
+	}
+
+	if expr := openapi.Expr.RequestHeader("X-Callback-Url").String(); expr != "$request.header.X-Callback-Url" {
+		t.Errorf("unexpected expression %q", expr)
+	}
+	if expr := openapi.Expr.ResponseBody("a/b", "c~d").String(); expr != "$response.body#/a~1b/c~0d" {
+		t.Errorf("unexpected expression %q", expr)
+	}
+}
+
+func TestAddPathItem(t *testing.T) {
+	builder := openapi.New("title", "version")
+	builder.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}",
+	})
+
+	o := builder.OpenAPI()
+	var added []string
+	o.OnAddOperation = append(o.OnAddOperation, func(oapi *openapi.OpenAPI, op *openapi.Operation) {
+		added = append(added, op.OperationID)
+	})
+
+	o.AddPathItem("/users/{id}", &openapi.PathItem{
+		Summary:     "User resource",
+		Description: "A single user",
+		Servers:     []*openapi.Server{{URL: "https://users.example.com"}},
+		Delete:      &openapi.Operation{OperationID: "deleteUser"},
+	})
+
+	item := o.PathItem("/users/{id}")
+	if item.Summary != "User resource" || item.Description != "A single user" || len(item.Servers) != 1 {
+		t.Errorf("unexpected path item %v", item)
+	}
+	if item.Get == nil || item.Delete == nil || item.Delete.Method != http.MethodDelete || item.Delete.Path != "/users/{id}" {
+		t.Errorf("unexpected operations %v %v", item.Get, item.Delete)
+	}
+	if len(added) != 1 || added[0] != "deleteUser" {
+		t.Errorf("expected OnAddOperation for deleteUser, got %v", added)
+	}
+	if o.PathItem("/missing") != nil {
+		t.Errorf("expected nil for missing path")
+	}
+}
+
+func TestPathItemBuilder(t *testing.T) {
+	builder := openapi.New("title", "version")
+	users := builder.Path("/users/{userId}").
+		Summary("User resource").
+		Description("A single user")
+	users.PathParam("userId", openapi.IntType)
+	users.Server().URL("https://users.example.com").AddVariable("region").Default("eu")
+	users.Register(&openapi.Operation{
+		OperationID: "getUser",
+		Method:      http.MethodGet,
+	})
+
+	item := builder.OpenAPI().Paths["/users/{userId}"]
+	if item.Summary != "User resource" || item.Description != "A single user" || item.Get == nil {
+		t.Errorf("unexpected path item %v", item)
+	}
+	if item.Servers[0].Variables["region"].Default != "eu" {
+		t.Errorf("unexpected servers %v", item.Servers)
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("expected path item params to satisfy validation, got %v", errs)
+	}
+}
+
+func TestWebhookBuilder(t *testing.T) {
+	type UserCreatedEvent struct {
+		ID string `json:"id"`
+	}
+
+	builder := openapi.New("title", "version")
+	hook := builder.Webhook("userCreated", &openapi.Operation{OperationID: "userCreated"}).
+		Summary("User created")
+	hook.Request().Body(UserCreatedEvent{})
+	hook.Response(http.StatusNoContent).Description("Event received")
+
+	item := builder.OpenAPI().Webhooks["userCreated"]
+	if item == nil || item.Post == nil || item.Post.Summary != "User created" {
+		t.Fatalf("expected userCreated webhook with a POST operation, got %v", item)
+	}
+	if item.Post.RequestBody == nil || item.Post.Responses["204"] == nil {
+		t.Errorf("unexpected webhook operation %v", item.Post)
+	}
+	if _, ok := builder.OpenAPI().Components.Schemas.Map()["UserCreatedEvent"]; !ok {
+		t.Errorf("expected the event schema to be registered")
+	}
+	if len(builder.OpenAPI().Paths) != 0 {
+		t.Errorf("expected no paths, got %v", builder.OpenAPI().Paths)
+	}
+	if errs := builder.Validate(); len(errs) != 0 {
+		t.Errorf("unexpected validation errors %v", errs)
+	}
+}
+
+func TestWebhookHooks(t *testing.T) {
+	var registered, added []string
+
+	builder := openapi.New("title", "version")
+	builder.Hooks(&openapi.Hooks{
+		OperationRegistered: func(op *openapi.Operation) {
+			registered = append(registered, op.OperationID)
+		},
+	})
+	builder.OpenAPI().OnAddOperation = append(builder.OpenAPI().OnAddOperation, func(o *openapi.OpenAPI, op *openapi.Operation) {
+		added = append(added, op.OperationID)
+	})
+	builder.Webhook("userCreated", &openapi.Operation{OperationID: "userCreated"})
+
+	if len(registered) != 1 || registered[0] != "userCreated" {
+		t.Errorf("expected the OperationRegistered hook for the webhook, got %v", registered)
+	}
+	if len(added) != 1 || added[0] != "userCreated" {
+		t.Errorf("expected OnAddOperation for the webhook, got %v", added)
+	}
+}
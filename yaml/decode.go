@@ -0,0 +1,634 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Decode reads a YAML document from r and returns its value using the same
+// types as encoding/json decodes into an `any`: map[string]any, []any,
+// string, float64, bool and nil.
+//
+// It supports the subset of YAML used by OpenAPI documents: block mappings
+// and sequences, flow collections, plain, quoted and block scalars and
+// comments. Anchors, aliases, tags and complex keys are not supported.
+func Decode(r io.Reader) (any, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	src := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	d := &decoder{}
+	for i, raw := range strings.Split(src, "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		d.lines = append(d.lines, &line{
+			num:    i + 1,
+			indent: len(raw) - len(trimmed),
+			text:   strings.TrimRight(stripComment(trimmed), " \t"),
+			raw:    raw,
+		})
+	}
+
+	d.skip()
+	if d.pos < len(d.lines) && (d.lines[d.pos].text == "---" || strings.HasPrefix(d.lines[d.pos].text, "--- ")) {
+		if rest := strings.TrimSpace(d.lines[d.pos].text[3:]); rest != "" {
+			return nil, d.errorf("content after document marker is not supported")
+		}
+		d.pos++
+	}
+
+	v, err := d.node(0)
+	if err != nil {
+		return nil, err
+	}
+
+	d.skip()
+	if d.pos < len(d.lines) && d.lines[d.pos].text != "..." && d.lines[d.pos].text != "---" {
+		return nil, d.errorf("unexpected content")
+	}
+
+	return v, nil
+}
+
+type line struct {
+	num    int
+	indent int
+	text   string
+	raw    string
+}
+
+type decoder struct {
+	lines []*line
+	pos   int
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	num := len(d.lines)
+	if d.pos < len(d.lines) {
+		num = d.lines[d.pos].num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// skip moves past blank and comment-only lines.
+func (d *decoder) skip() {
+	for d.pos < len(d.lines) && d.lines[d.pos].text == "" {
+		d.pos++
+	}
+}
+
+// node decodes the value starting at the current line, which must be
+// indented by at least indent.
+func (d *decoder) node(indent int) (any, error) {
+	d.skip()
+	if d.pos >= len(d.lines) {
+		return nil, nil
+	}
+
+	l := d.lines[d.pos]
+	if l.indent < indent {
+		return nil, nil
+	}
+
+	switch {
+	case isSequenceEntry(l.text):
+		return d.sequence(l.indent)
+	case mappingKeyEnd(l.text) >= 0:
+		return d.mapping(l.indent)
+	}
+
+	d.pos++
+	return d.scalar(l.text, l.indent-1)
+}
+
+// mapping decodes a block mapping whose keys are indented by indent.
+func (d *decoder) mapping(indent int) (any, error) {
+	m := map[string]any{}
+
+	for {
+		d.skip()
+		if d.pos >= len(d.lines) {
+			return m, nil
+		}
+
+		l := d.lines[d.pos]
+		if l.indent < indent {
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, d.errorf("unexpected indentation")
+		}
+
+		end := mappingKeyEnd(l.text)
+		if end < 0 {
+			if isSequenceEntry(l.text) {
+				return m, nil
+			}
+			return nil, d.errorf("expected a mapping key")
+		}
+
+		key, err := scalarKey(l.text[:end])
+		if err != nil {
+			return nil, d.errorf("%v", err)
+		}
+		if _, ok := m[key]; ok {
+			return nil, d.errorf("duplicate key %q", key)
+		}
+		rest := strings.TrimSpace(l.text[end+1:])
+		d.pos++
+
+		var v any
+		switch {
+		case rest == "":
+			d.skip()
+			if d.pos < len(d.lines) && d.lines[d.pos].indent == indent && isSequenceEntry(d.lines[d.pos].text) {
+				// Sequences may be indented like their key.
+				v, err = d.sequence(indent)
+			} else {
+				v, err = d.node(indent + 1)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			v, err = d.blockScalar(rest, indent)
+		default:
+			v, err = d.scalar(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = v
+	}
+}
+
+// sequence decodes a block sequence whose dashes are indented by indent.
+func (d *decoder) sequence(indent int) (any, error) {
+	s := []any{}
+
+	for {
+		d.skip()
+		if d.pos >= len(d.lines) {
+			return s, nil
+		}
+
+		l := d.lines[d.pos]
+		if l.indent < indent || !isSequenceEntry(l.text) {
+			return s, nil
+		}
+		if l.indent > indent {
+			return nil, d.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+
+		var v any
+		var err error
+		switch {
+		case rest == "":
+			d.pos++
+			v, err = d.node(indent + 1)
+		case rest[0] == '|' || rest[0] == '>':
+			d.pos++
+			v, err = d.blockScalar(rest, indent)
+		case isSequenceEntry(rest) || mappingKeyEnd(rest) >= 0:
+			// Compact nested collection, e.g. `- name: foo`. The rest of the
+			// line becomes the first line of the collection.
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			v, err = d.node(l.indent)
+		default:
+			d.pos++
+			v, err = d.scalar(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, v)
+	}
+}
+
+// scalar decodes a flow collection, a quoted scalar or a plain scalar which
+// may continue on the following lines indented by more than indent.
+func (d *decoder) scalar(text string, indent int) (any, error) {
+	if text[0] == '&' || text[0] == '*' || text[0] == '!' {
+		return nil, d.errorf("anchors, aliases and tags are not supported")
+	}
+
+	if text[0] == '[' || text[0] == '{' || text[0] == '"' || text[0] == '\'' {
+		// Flow collections and quoted scalars may span several lines.
+		for !balanced(text) {
+			d.skip()
+			if d.pos >= len(d.lines) {
+				return nil, d.errorf("unterminated flow collection or quoted scalar")
+			}
+			text += " " + strings.TrimSpace(d.lines[d.pos].text)
+			d.pos++
+		}
+
+		p := &flowParser{s: text}
+		v, err := p.value()
+		if err != nil {
+			return nil, d.errorf("%v", err)
+		}
+		p.space()
+		if p.i < len(p.s) {
+			return nil, d.errorf("unexpected content after value")
+		}
+		return v, nil
+	}
+	if mappingKeyEnd(text) >= 0 {
+		return nil, d.errorf("mapping values are not allowed in this context")
+	}
+
+	for {
+		if d.pos >= len(d.lines) {
+			break
+		}
+		l := d.lines[d.pos]
+		if l.text == "" || l.indent <= indent || isSequenceEntry(l.text) || mappingKeyEnd(l.text) >= 0 {
+			break
+		}
+		text += " " + l.text
+		d.pos++
+	}
+
+	return plainValue(text), nil
+}
+
+// blockScalar decodes a literal (`|`) or folded (`>`) scalar with the given
+// header whose content is indented by more than indent.
+func (d *decoder) blockScalar(header string, indent int) (any, error) {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	contentIndent := 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			contentIndent = indent + int(c-'0')
+		default:
+			return nil, d.errorf("invalid block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	for d.pos < len(d.lines) {
+		raw := d.lines[d.pos].raw
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			d.pos++
+			continue
+		}
+
+		lineIndent := len(raw) - len(trimmed)
+		if contentIndent == 0 {
+			if lineIndent <= indent {
+				break
+			}
+			contentIndent = lineIndent
+		}
+		if lineIndent < contentIndent {
+			break
+		}
+
+		lines = append(lines, raw[contentIndent:])
+		d.pos++
+	}
+
+	// Trailing blank lines are only kept with the `+` indicator.
+	trailing := 0
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var b strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case !folded || l == "":
+				b.WriteByte('\n')
+			case prev == "":
+				// The blank line already broke the line.
+			case l[0] == ' ' || prev[0] == ' ':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(l)
+	}
+
+	s := b.String()
+	if len(lines) == 0 {
+		s = ""
+	}
+	switch chomp {
+	case '-':
+	case '+':
+		if len(lines) > 0 {
+			s += "\n"
+		}
+		s += strings.Repeat("\n", trailing)
+	default:
+		if len(lines) > 0 {
+			s += "\n"
+		}
+	}
+
+	return s, nil
+}
+
+// isSequenceEntry reports whether the text starts a block sequence entry.
+func isSequenceEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// mappingKeyEnd returns the index of the colon ending the mapping key at the
+// start of text, or -1 if text isn't a mapping entry.
+func mappingKeyEnd(text string) int {
+	if text == "" || text[0] == '[' || text[0] == '{' || text[0] == '-' && isSequenceEntry(text) {
+		return -1
+	}
+
+	start := 0
+	if text[0] == '"' || text[0] == '\'' {
+		end := quoteEnd(text)
+		if end < 0 {
+			return -1
+		}
+		start = end + 1
+		rest := strings.TrimLeft(text[start:], " ")
+		if rest == "" || rest[0] != ':' {
+			return -1
+		}
+		start = len(text) - len(rest)
+	}
+
+	for i := start; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// quoteEnd returns the index of the quote closing the quoted scalar at the
+// start of s, or -1.
+func quoteEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// balanced reports whether the quotes and brackets of text are closed.
+func balanced(text string) bool {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			end := quoteEnd(text[i:])
+			if end < 0 {
+				return false
+			}
+			i += end
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// stripComment removes a trailing comment, ignoring `#` in quoted scalars
+// and in the middle of plain scalars.
+func stripComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '[' || s[i-1] == '{' || s[i-1] == ',' {
+				if end := quoteEnd(s[i:]); end >= 0 {
+					i += end
+				}
+			}
+		case '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// scalarKey decodes a plain or quoted mapping key.
+func scalarKey(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		p := &flowParser{s: s}
+		v, err := p.quoted()
+		if err != nil {
+			return "", err
+		}
+		return v, nil
+	}
+	if strings.HasPrefix(s, "? ") || s[0] == '&' || s[0] == '*' || s[0] == '!' {
+		return "", fmt.Errorf("complex keys, anchors, aliases and tags are not supported")
+	}
+	return s, nil
+}
+
+// plainValue resolves a plain scalar using the YAML core schema.
+func plainValue(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	if c := s[0]; c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9') {
+		if strings.HasPrefix(s, "0o") {
+			if i, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+				return float64(i)
+			}
+		} else if strings.HasPrefix(s, "0x") {
+			if i, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+				return float64(i)
+			}
+		} else if !strings.ContainsAny(s, "_xXoObB") {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		}
+	}
+
+	return s
+}
+
+// flowParser parses flow collections and quoted scalars.
+type flowParser struct {
+	s string
+	i int
+}
+
+func (p *flowParser) space() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+func (p *flowParser) value() (any, error) {
+	p.space()
+	if p.i >= len(p.s) {
+		return nil, fmt.Errorf("unexpected end of flow value")
+	}
+
+	switch p.s[p.i] {
+	case '[':
+		p.i++
+		s := []any{}
+		for {
+			p.space()
+			if p.i < len(p.s) && p.s[p.i] == ']' {
+				p.i++
+				return s, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			if err := p.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		p.i++
+		m := map[string]any{}
+		for {
+			p.space()
+			if p.i < len(p.s) && p.s[p.i] == '}' {
+				p.i++
+				return m, nil
+			}
+			k, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			p.space()
+			var v any
+			if p.i < len(p.s) && p.s[p.i] == ':' {
+				p.i++
+				p.space()
+				if p.i < len(p.s) && p.s[p.i] != ',' && p.s[p.i] != '}' {
+					if v, err = p.value(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			m[key] = v
+			if err := p.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		return p.quoted()
+	}
+
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if c == ',' || c == ']' || c == '}' || (c == ':' && (p.i+1 == len(p.s) || p.s[p.i+1] == ' ' || p.s[p.i+1] == ',')) {
+			break
+		}
+		p.i++
+	}
+	return plainValue(strings.TrimSpace(p.s[start:p.i])), nil
+}
+
+// separator consumes the comma between flow entries. It leaves a closing
+// bracket for the caller.
+func (p *flowParser) separator(end byte) error {
+	p.space()
+	if p.i >= len(p.s) {
+		return fmt.Errorf("unterminated flow collection")
+	}
+	switch p.s[p.i] {
+	case ',':
+		p.i++
+		return nil
+	case end:
+		return nil
+	}
+	return fmt.Errorf("expected , or %c in flow collection", end)
+}
+
+func (p *flowParser) quoted() (string, error) {
+	end := quoteEnd(p.s[p.i:])
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quoted scalar")
+	}
+	raw := p.s[p.i : p.i+end+1]
+	p.i += end + 1
+
+	if raw[0] == '\'' {
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+
+	// Double quoted YAML scalars use JSON's escapes, plus a few more.
+	raw = yamlEscapes.Replace(raw)
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return "", fmt.Errorf("invalid double quoted scalar %s", raw)
+	}
+	return s, nil
+}
+
+// yamlEscapes converts the escapes of double quoted YAML scalars which JSON
+// doesn't have. Escaped backslashes are replaced by themselves so they aren't
+// mistaken for the start of another escape.
+var yamlEscapes = strings.NewReplacer(
+	`\\`, `\\`,
+	`\0`, `\u0000`,
+	`\a`, `\u0007`,
+	`\e`, `\u001b`,
+	`\ `, ` `,
+	`\N`, `\u0085`,
+	`\_`, `\u00a0`,
+	`\L`, `\u2028`,
+	`\P`, `\u2029`,
+	"\t", `\t`,
+)
@@ -0,0 +1,154 @@
+package yaml_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	json2yaml "github.com/restk/openapi/yaml"
+)
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+		err  string
+	}{
+		{
+			name: "mapping",
+			src: `
+openapi: 3.1.0 # version
+info:
+  title: "Pet \"Store\""
+  version: '1.0'
+  x-count: 3
+`,
+			want: `{"info":{"title":"Pet \"Store\"","version":"1.0","x-count":3},"openapi":"3.1.0"}`,
+		},
+		{
+			name: "sequences",
+			src: `
+tags:
+- name: pets
+  description: Everything about pets
+-   name: store
+required:
+  - id
+  - name
+empty: []
+nested:
+- - 1
+  - 2
+`,
+			want: `{"empty":[],"nested":[[1,2]],"required":["id","name"],"tags":[{"description":"Everything about pets","name":"pets"},{"name":"store"}]}`,
+		},
+		{
+			name: "flow",
+			src:  `schema: {type: [string, "null"], enum: [a, 'b', 1, true, null], example: {}}`,
+			want: `{"schema":{"enum":["a","b",1,true,null],"example":{},"type":["string","null"]}}`,
+		},
+		{
+			name: "block scalars",
+			src: `
+literal: |
+  line one
+    indented
+
+  line three
+strip: |-
+  no newline
+folded: >
+  folded
+  text
+
+  paragraph
+keep: |+
+  kept
+
+after: x
+`,
+			want: `{"after":"x","folded":"folded text\nparagraph\n","keep":"kept\n\n","literal":"line one\n  indented\n\nline three\n","strip":"no newline"}`,
+		},
+		{
+			name: "plain scalars",
+			src: `
+url: https://example.com/a#b
+comment: value # comment
+number: -1.5e3
+hex: 0x1f
+nulls: ~
+long: a plain scalar
+  continued
+"200":
+  description: OK
+`,
+			want: `{"200":{"description":"OK"},"comment":"value","hex":31,"long":"a plain scalar continued","nulls":null,"number":-1500,"url":"https://example.com/a#b"}`,
+		},
+		{
+			name: "alias",
+			src:  "a: *anchor",
+			err:  "yaml: line 1: anchors, aliases and tags are not supported",
+		},
+		{
+			name: "duplicate",
+			src:  "a: 1\na: 2",
+			err:  `yaml: line 2: duplicate key "a"`,
+		},
+		{
+			name: "nested mapping value",
+			src:  "a: b: c",
+			err:  "yaml: line 1: mapping values are not allowed in this context",
+		},
+		{
+			name: "nested mapping value in sequence",
+			src:  "- a: b: c",
+			err:  "yaml: line 1: mapping values are not allowed in this context",
+		},
+		{
+			name: "colon without space",
+			src:  "a: b:c",
+			want: `{"a":"b:c"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := json2yaml.Decode(strings.NewReader(tc.src))
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(v)
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeConverted(t *testing.T) {
+	src := `{"a":{"b":[1,"two",{"c":null,"d":[]}],"e":"multi\nline\n","f":"with: colon","g":"#hash","h":"-dash","i":{}},"j":"true","k":"  padded","l":"tail\n\n"}`
+
+	var out strings.Builder
+	if err := json2yaml.Convert(&out, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json2yaml.Decode(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+
+	var want any
+	json.Unmarshal([]byte(src), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v\n%s", got, want, out.String())
+	}
+}
@@ -1,7 +1,8 @@
 // Source from https://github.com/itchyny/json2yaml
 // Copyright (c) 2022 itchyny, MIT licensed.
 
-// Package yaml implements a converter from JSON to YAML.
+// Package yaml implements a converter from JSON to YAML and a decoder for the
+// YAML used by OpenAPI documents.
 //
 //nolint:all
 package yaml